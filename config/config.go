@@ -1,9 +1,111 @@
 package config
 
+import (
+	"log/slog"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
 type Config struct {
-	PgHost     string `env:"DB_HOST" default:"localhost"`
-	PgUser     string `env:"DB_USER" default:"salam"`
-	PgPassword string `env:"DB_PASSWORD" default:"salam"`
-	PgPort     int    `env:"DB_PORT" default:"5432"`
-	Db         string `env:"DB_NAME" default:"salam"`
+	PgHost     string `env:"DB_HOST" envDefault:"localhost"`
+	PgUser     string `env:"DB_USER" envDefault:"salam"`
+	PgPassword string `env:"DB_PASSWORD" envDefault:"salam"`
+	PgPort     int    `env:"DB_PORT" envDefault:"5432"`
+	Db         string `env:"DB_NAME" envDefault:"salam"`
+
+	HTTPAddr              string        `env:"HTTP_ADDR" envDefault:":8080"`
+	HTTPReadTimeout       time.Duration `env:"HTTP_READ_TIMEOUT" envDefault:"10s"`
+	HTTPReadHeaderTimeout time.Duration `env:"HTTP_READ_HEADER_TIMEOUT" envDefault:"5s"`
+	HTTPWriteTimeout      time.Duration `env:"HTTP_WRITE_TIMEOUT" envDefault:"15s"`
+	HTTPIdleTimeout       time.Duration `env:"HTTP_IDLE_TIMEOUT" envDefault:"60s"`
+	HTTPMaxHeaderBytes    int           `env:"HTTP_MAX_HEADER_BYTES" envDefault:"1048576"`
+	ShutdownTimeout       time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"20s"`
+	HTTPTrustedProxies    []string      `env:"HTTP_TRUSTED_PROXIES"`
+
+	LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
+
+	TracingEnabled     bool    `env:"TRACING_ENABLED" envDefault:"false"`
+	TracingServiceName string  `env:"TRACING_SERVICE_NAME" envDefault:"shop"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	OTLPEndpoint       string  `env:"OTLP_ENDPOINT" envDefault:"localhost:4318"`
+	OTLPInsecure       bool    `env:"OTLP_INSECURE" envDefault:"true"`
+
+	IdempotencyTTL           time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+	IdempotencyPurgeInterval time.Duration `env:"IDEMPOTENCY_PURGE_INTERVAL" envDefault:"1h"`
+
+	EventSink             string        `env:"EVENT_SINK" envDefault:"log"`
+	EventWebhookURL       string        `env:"EVENT_WEBHOOK_URL"`
+	EventDispatchInterval time.Duration `env:"EVENT_DISPATCH_INTERVAL" envDefault:"1s"`
+	EventMaxAttempts      int           `env:"EVENT_MAX_ATTEMPTS" envDefault:"10"`
+	EventRetryBackoff     time.Duration `env:"EVENT_RETRY_BACKOFF" envDefault:"1s"`
+
+	WebhookDeliveryInterval time.Duration `env:"WEBHOOK_DELIVERY_INTERVAL" envDefault:"1s"`
+	WebhookMaxAttempts      int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookRetryBackoff     time.Duration `env:"WEBHOOK_RETRY_BACKOFF" envDefault:"10s"`
+	WebhookTimeout          time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+
+	MailDriver   string `env:"MAIL_DRIVER" envDefault:"file"`
+	MailFrom     string `env:"MAIL_FROM" envDefault:"Shop <no-reply@shop.local>"`
+	MailDir      string `env:"MAIL_DIR" envDefault:"mail"`
+	SMTPAddr     string `env:"SMTP_ADDR" envDefault:"localhost:25"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+
+	EmailVerificationTTL   time.Duration `env:"EMAIL_VERIFICATION_TTL" envDefault:"48h"`
+	PasswordResetTTL       time.Duration `env:"PASSWORD_RESET_TTL" envDefault:"1h"`
+	RequireVerifiedToOrder bool          `env:"REQUIRE_VERIFIED_EMAIL_TO_ORDER" envDefault:"false"`
+
+	// PendingOrderTTL is how long an order may stay unpaid; zero disables
+	// canceling stale orders.
+	PendingOrderTTL          time.Duration `env:"PENDING_ORDER_TTL" envDefault:"24h"`
+	StaleOrderCancelInterval time.Duration `env:"STALE_ORDER_CANCEL_INTERVAL" envDefault:"1m"`
+
+	// DefaultCurrency is the ISO 4217 code orders are placed in when they do
+	// not name a currency and new users keep their balance in.
+	DefaultCurrency string `env:"DEFAULT_CURRENCY" envDefault:"KZT"`
+
+	// FulfillmentStrategy picks the warehouses orders are reserved in:
+	// "nearest" or "most_stock".
+	FulfillmentStrategy string `env:"FULFILLMENT_STRATEGY" envDefault:"nearest"`
+
+	LowStockCheckInterval time.Duration `env:"LOW_STOCK_CHECK_INTERVAL" envDefault:"1m"`
+	// StockAlertEmail receives low-stock alerts when a mail driver is set;
+	// empty leaves them to the event sink.
+	StockAlertEmail string `env:"STOCK_ALERT_EMAIL"`
+
+	// ImageDir is the directory product images and their thumbnails are
+	// kept in.
+	ImageDir      string `env:"IMAGE_DIR" envDefault:"images"`
+	ImageMaxBytes int64  `env:"IMAGE_MAX_BYTES" envDefault:"10485760"`
+
+	// AdminTokens are "name:token" pairs; each lets the named staff member call
+	// the admin routes with "Authorization: Bearer <token>". Empty disables
+	// them.
+	AdminTokens []string `env:"ADMIN_TOKENS"`
+
+	// Rate limits are counted per client IP address; see HTTPTrustedProxies for
+	// clients behind a load balancer.
+	RateLimitEnabled      bool          `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	RateLimitAPIBurst     int           `env:"RATE_LIMIT_API_BURST" envDefault:"100"`
+	RateLimitAPIPeriod    time.Duration `env:"RATE_LIMIT_API_PERIOD" envDefault:"1m"`
+	RateLimitSignupBurst  int           `env:"RATE_LIMIT_SIGNUP_BURST" envDefault:"5"`
+	RateLimitSignupPeriod time.Duration `env:"RATE_LIMIT_SIGNUP_PERIOD" envDefault:"1h"`
+}
+
+// LogValue implements slog.LogValuer so that logging the config never leaks secrets.
+func (c Config) LogValue() slog.Value {
+	// plain drops the LogValue method to avoid recursing into it.
+	type plain Config
+	p := plain(c)
+	if p.PgPassword != "" {
+		p.PgPassword = redacted
+	}
+	if p.SMTPPassword != "" {
+		p.SMTPPassword = redacted
+	}
+	if len(p.AdminTokens) > 0 {
+		p.AdminTokens = []string{redacted}
+	}
+	return slog.AnyValue(p)
 }
@@ -0,0 +1,23 @@
+package config_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	logger.Info("config loaded", "config", config.Config{PgUser: "salam", PgPassword: "secret", SMTPPassword: "smtp-secret", AdminTokens: []string{"ops:admin-secret"}})
+
+	assert.NotContains(t, buf.String(), "secret")
+	assert.Contains(t, buf.String(), `"SMTPPassword":"[REDACTED]"`)
+	assert.Contains(t, buf.String(), `"PgPassword":"[REDACTED]"`)
+	assert.Contains(t, buf.String(), `"AdminTokens":["[REDACTED]"]`)
+	assert.Contains(t, buf.String(), `"PgUser":"salam"`)
+}
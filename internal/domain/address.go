@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Address is an entry of a user's address book. Orders keep a copy of the
+// addresses they were placed with, so editing or deleting an entry does not
+// change past orders.
+type Address struct {
+	ID         string
+	UserID     string
+	Recipient  string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	// Country is an ISO 3166-1 alpha-2 code such as "KZ".
+	Country string
+	Phone   string
+	// IsDefault marks the address used for orders that do not select one. A
+	// user has at most one default address.
+	IsDefault bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -9,4 +9,50 @@ var (
 	ErrorOrderNotFound     = errors.New("order not found")
 	ErrorCategoryNotFound  = errors.New("category not found")
 	ErrorSupplierNotFound  = errors.New("supplier not found")
+	ErrorWebhookNotFound   = errors.New("webhook not found")
+	ErrorDeliveryNotFound  = errors.New("webhook delivery not found")
+	ErrorAddressNotFound   = errors.New("address not found")
+	ErrorShipmentNotFound  = errors.New("shipment not found")
+	ErrorReturnNotFound    = errors.New("return not found")
+	ErrorWarehouseNotFound = errors.New("warehouse not found")
+	ErrorVariantNotFound   = errors.New("variant not found")
+	ErrorImageNotFound     = errors.New("image not found")
+	ErrorPriceNotFound     = errors.New("price not found")
+
+	ErrorOrderHasNoItems     = errors.New("order has no items")
+	ErrorInvalidQuantity     = errors.New("quantity must be positive")
+	ErrorInsufficientStock   = errors.New("insufficient stock")
+	ErrorOrderNotPending     = errors.New("order is not pending")
+	ErrorInsufficientBalance = errors.New("insufficient balance")
+	ErrorEmailNotVerified    = errors.New("email address is not verified")
+	ErrorOrderNotShippable   = errors.New("order is not paid or already completed")
+	ErrorOrderNotReturnable  = errors.New("only completed orders can be returned")
+	ErrorOrderTransition     = errors.New("order cannot move to this status")
+	ErrorInvalidStatus       = errors.New("unknown order status")
+	ErrorReturnTransition    = errors.New("return cannot move to this status")
+
+	ErrorInvalidToken = errors.New("token is invalid or expired")
+
+	ErrorVersionConflict        = errors.New("resource was modified by another request")
+	ErrorInvalidPatch           = errors.New("invalid patch")
+	ErrorInvalidWebhook         = errors.New("invalid webhook")
+	ErrorInvalidAddress         = errors.New("invalid address")
+	ErrorInvalidShipment        = errors.New("invalid shipment")
+	ErrorInvalidReturn          = errors.New("invalid return")
+	ErrorInvalidStockAdjustment = errors.New("invalid stock adjustment")
+	ErrorInvalidWarehouse       = errors.New("invalid warehouse")
+	ErrorInvalidVariant         = errors.New("invalid variant")
+	ErrorVariantExists          = errors.New("a variant with this SKU or these options already exists")
+	ErrorInvalidImage           = errors.New("invalid image")
+	ErrorImageTooLarge          = errors.New("image is too large")
+	ErrorInvalidCurrency        = errors.New("unsupported currency")
+	ErrorInvalidPrice           = errors.New("invalid price")
+	ErrorCurrencyMismatch       = errors.New("amounts are in different currencies")
+	ErrorMoneyOverflow          = errors.New("amount is out of range")
+	ErrorPriceUnavailable       = errors.New("product has no price in the order currency")
+
+	ErrorIdempotencyKeyExists     = errors.New("idempotency key already exists")
+	ErrorIdempotencyKeyNotFound   = errors.New("idempotency key not found")
+	ErrorIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+	ErrorIdempotencyKeyMismatch   = errors.New("idempotency key was used with a different request")
 )
@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventUserRegistered      EventType = "user.registered"
+	EventOrderCreated        EventType = "order.created"
+	EventOrderStatusChanged  EventType = "order.status_changed"
+	EventStockLow            EventType = "product.stock_low"
+	EventShipmentShipped     EventType = "shipment.shipped"
+	EventShipmentDelivered   EventType = "shipment.delivered"
+	EventReturnStatusChanged EventType = "return.status_changed"
+
+	// EventAccountTokenRequested asks for an account token to be issued and
+	// mailed. The service handles it itself, so it is not handed to sinks and
+	// cannot be subscribed to.
+	EventAccountTokenRequested EventType = "user.token_requested"
+)
+
+// EventTypes lists every event type that can be subscribed to.
+var EventTypes = []EventType{
+	EventUserRegistered,
+	EventOrderCreated,
+	EventOrderStatusChanged,
+	EventStockLow,
+	EventShipmentShipped,
+	EventShipmentDelivered,
+	EventReturnStatusChanged,
+}
+
+// Event is a domain event stored in the outbox in the same transaction as the
+// change it describes, and delivered to sinks at least once.
+type Event struct {
+	ID          string
+	Type        EventType
+	AggregateID string
+	// Payload is marshalled to JSON when the event is written to the outbox;
+	// events read back from it carry a json.RawMessage.
+	Payload   any
+	CreatedAt time.Time
+	// Attempts counts the deliveries tried so far.
+	Attempts int
+}
+
+func newEvent(typ EventType, aggregateID string, payload any) Event {
+	return Event{
+		ID:          uuid.New().String(),
+		Type:        typ,
+		AggregateID: aggregateID,
+		Payload:     payload,
+		CreatedAt:   time.Now(),
+	}
+}
+
+type UserRegistered struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+}
+
+func NewUserRegistered(user *User) Event {
+	return newEvent(EventUserRegistered, user.ID, UserRegistered{
+		UserID: user.ID,
+		Name:   user.Name,
+		Email:  user.Email,
+	})
+}
+
+// AccountTokenRequested names the user to mail a token to. The token is only
+// issued when the event is dispatched, so it never enters the outbox.
+type AccountTokenRequested struct {
+	UserID  string       `json:"user_id"`
+	Purpose TokenPurpose `json:"purpose"`
+}
+
+func NewAccountTokenRequested(user *User, purpose TokenPurpose) Event {
+	return newEvent(EventAccountTokenRequested, user.ID, AccountTokenRequested{
+		UserID:  user.ID,
+		Purpose: purpose,
+	})
+}
+
+// OrderCreated describes a new order. It references the order rather than
+// copying it, so item prices fixed while the order is stored are included.
+type OrderCreated struct {
+	Order *Order
+}
+
+type orderCreatedItem struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
+	Price     Money  `json:"price"`
+}
+
+func (e OrderCreated) MarshalJSON() ([]byte, error) {
+	items := make([]orderCreatedItem, 0, len(e.Order.Items))
+	for _, item := range e.Order.Items {
+		items = append(items, orderCreatedItem{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity, Price: item.Price})
+	}
+	total, err := e.Order.Total()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		OrderID string             `json:"order_id"`
+		UserID  string             `json:"user_id"`
+		Status  Status             `json:"status"`
+		Items   []orderCreatedItem `json:"items"`
+		Total   Money              `json:"total"`
+	}{e.Order.ID, e.Order.UserID, e.Order.Status, items, total})
+}
+
+func NewOrderCreated(order *Order) Event {
+	return newEvent(EventOrderCreated, order.ID, OrderCreated{Order: order})
+}
+
+type OrderStatusChanged struct {
+	OrderID string `json:"order_id"`
+	UserID  string `json:"user_id"`
+	From    Status `json:"from"`
+	To      Status `json:"to"`
+}
+
+func NewOrderStatusChanged(order *Order, from, to Status) Event {
+	return newEvent(EventOrderStatusChanged, order.ID, OrderStatusChanged{
+		OrderID: order.ID,
+		UserID:  order.UserID,
+		From:    from,
+		To:      to,
+	})
+}
+
+// StockLow reports a product whose amount fell to its reorder point, which is
+// sent as the threshold.
+type StockLow struct {
+	ProductID       string  `json:"product_id"`
+	Name            string  `json:"name"`
+	SKU             string  `json:"sku"`
+	Amount          int     `json:"amount"`
+	Threshold       int     `json:"threshold"`
+	ReorderQuantity int     `json:"reorder_quantity"`
+	SupplierID      *string `json:"supplier_id"`
+	SupplierName    string  `json:"supplier_name,omitempty"`
+}
+
+func NewStockLow(line ReorderLine) Event {
+	p := line.Product
+	payload := StockLow{
+		ProductID:       p.ID,
+		Name:            p.Name,
+		SKU:             p.SKU,
+		Amount:          p.Amount,
+		Threshold:       p.ReorderPoint,
+		ReorderQuantity: p.ReorderQuantity,
+		SupplierID:      p.SupplierID,
+	}
+	if line.Supplier != nil {
+		payload.SupplierName = line.Supplier.Name
+	}
+	return newEvent(EventStockLow, p.ID, payload)
+}
+
+type ShipmentChanged struct {
+	ShipmentID     string             `json:"shipment_id"`
+	OrderID        string             `json:"order_id"`
+	Carrier        string             `json:"carrier"`
+	TrackingNumber string             `json:"tracking_number"`
+	Items          []shipmentItemJSON `json:"items"`
+	ShippedAt      time.Time          `json:"shipped_at"`
+	DeliveredAt    *time.Time         `json:"delivered_at,omitempty"`
+}
+
+type shipmentItemJSON struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+func newShipmentChanged(s *Shipment) ShipmentChanged {
+	items := make([]shipmentItemJSON, 0, len(s.Items))
+	for _, item := range s.Items {
+		items = append(items, shipmentItemJSON(item))
+	}
+	return ShipmentChanged{
+		ShipmentID:     s.ID,
+		OrderID:        s.OrderID,
+		Carrier:        s.Carrier,
+		TrackingNumber: s.TrackingNumber,
+		Items:          items,
+		ShippedAt:      s.ShippedAt,
+		DeliveredAt:    s.DeliveredAt,
+	}
+}
+
+func NewShipmentShipped(s *Shipment) Event {
+	return newEvent(EventShipmentShipped, s.OrderID, newShipmentChanged(s))
+}
+
+func NewShipmentDelivered(s *Shipment) Event {
+	return newEvent(EventShipmentDelivered, s.OrderID, newShipmentChanged(s))
+}
+
+// ReturnStatusChanged describes a step of a return; From is empty when the
+// return is requested.
+type ReturnStatusChanged struct {
+	ReturnID     string       `json:"return_id"`
+	OrderID      string       `json:"order_id"`
+	UserID       string       `json:"user_id"`
+	From         ReturnStatus `json:"from,omitempty"`
+	To           ReturnStatus `json:"to"`
+	RefundAmount Money        `json:"refund_amount"`
+}
+
+func NewReturnStatusChanged(r *Return, from, to ReturnStatus) Event {
+	return newEvent(EventReturnStatusChanged, r.ID, ReturnStatusChanged{
+		ReturnID:     r.ID,
+		OrderID:      r.OrderID,
+		UserID:       r.UserID,
+		From:         from,
+		To:           to,
+		RefundAmount: r.RefundAmount,
+	})
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// IdempotencyKey records the outcome of a mutating request sent with an
+// Idempotency-Key header so that retries get the stored response replayed.
+type IdempotencyKey struct {
+	Key         string
+	Scope       string
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
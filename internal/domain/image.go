@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImageSize names a rendition of a product image: the uploaded original or
+// one of the thumbnails made from it.
+type ImageSize string
+
+const (
+	ImageOriginal ImageSize = "original"
+	ImageSmall    ImageSize = "small"
+	ImageMedium   ImageSize = "medium"
+	ImageLarge    ImageSize = "large"
+)
+
+// ThumbnailSizes maps every thumbnail to the edge, in pixels, of the square it
+// is scaled to fit in.
+var ThumbnailSizes = map[ImageSize]int{
+	ImageSmall:  160,
+	ImageMedium: 480,
+	ImageLarge:  1200,
+}
+
+// Valid reports whether s is the original or one of the thumbnails.
+func (s ImageSize) Valid() bool {
+	_, ok := ThumbnailSizes[s]
+	return ok || s == ImageOriginal
+}
+
+// ProductImage is an image in the gallery of a product. Galleries are shown in
+// Position order, starting at 0.
+type ProductImage struct {
+	ID        string
+	ProductID string
+	Position  int
+	// ContentType is the type of the original; thumbnails of PNG and GIF
+	// images are PNG, those of JPEG images are JPEG.
+	ContentType string
+	Width       int
+	Height      int
+	// Size is the length of the original in bytes.
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Key returns where the given rendition of the image is kept.
+func (img *ProductImage) Key(size ImageSize) string {
+	return fmt.Sprintf("products/%s/images/%s/%s", img.ProductID, img.ID, size)
+}
+
+// SizeContentType returns the content type of the given rendition.
+func (img *ProductImage) SizeContentType(size ImageSize) string {
+	if size == ImageOriginal || img.ContentType == "image/jpeg" {
+		return img.ContentType
+	}
+	return "image/png"
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+type MovementType string
+
+const (
+	// MovementSale takes stock out for an order.
+	MovementSale MovementType = "sale"
+	// MovementRestock puts stock back, e.g. when an unpaid order is canceled.
+	MovementRestock MovementType = "restock"
+	// MovementReturn puts the items of a received return back into stock.
+	MovementReturn MovementType = "return"
+	// MovementAdjustment is a manual correction, e.g. after a stock count.
+	MovementAdjustment MovementType = "adjustment"
+)
+
+// ActorSystem is the actor of movements made by background jobs.
+const ActorSystem = "system"
+
+// InventoryMovement is an entry of the inventory ledger. Quantity is the
+// signed change of the product amount; the sum of all movements of a product
+// equals its amount. VariantID is set when the movement concerns one variant
+// of the product. Reference is the order or return that caused the movement,
+// if any.
+type InventoryMovement struct {
+	ID          int64
+	ProductID   string
+	VariantID   string
+	WarehouseID string
+	Type        MovementType
+	Quantity    int
+	Reason      string
+	Actor       string
+	Reference   string
+	CreatedAt   time.Time
+}
+
+// StockDiscrepancy is a product whose amount differs from the sum of its
+// inventory movements or of its warehouse stock.
+type StockDiscrepancy struct {
+	ProductID    string
+	Amount       int
+	LedgerSum    int
+	WarehouseSum int
+}
+
+// Difference returns how much the amount exceeds the ledger.
+func (d StockDiscrepancy) Difference() int {
+	return d.Amount - d.LedgerSum
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// JobCancelStaleOrders is the background job canceling pending orders that
+// were not paid in time.
+const JobCancelStaleOrders = "cancel_stale_orders"
+
+// JobCheckLowStock is the background job raising StockLow events for
+// products at or below their reorder point.
+const JobCheckLowStock = "check_low_stock"
+
+// JobRun is the outcome of the last run of a background job. Processed is the
+// number of items the run handled; Error is empty if it succeeded.
+type JobRun struct {
+	Name       string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Processed  int
+	Error      string
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code such as "KZT".
+type Currency string
+
+// DefaultCurrency is the currency prices and balances were kept in before they
+// carried one.
+const DefaultCurrency Currency = "KZT"
+
+// currencyExponents maps the supported currencies to the number of digits
+// after the decimal point of their minor unit.
+var currencyExponents = map[Currency]int{
+	"AED": 2, "AUD": 2, "BYN": 2, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2,
+	"EUR": 2, "GBP": 2, "GEL": 2, "HKD": 2, "INR": 2, "JPY": 0, "KGS": 2,
+	"KRW": 0, "KZT": 2, "PLN": 2, "RUB": 2, "SEK": 2, "TRY": 2, "UAH": 2,
+	"USD": 2, "UZS": 2,
+}
+
+// ParseCurrency returns the currency with the given code, ignoring case and
+// surrounding space.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.Valid() {
+		return "", fmt.Errorf("%w: %q", ErrorInvalidCurrency, code)
+	}
+	return c, nil
+}
+
+// Valid reports whether c is a supported currency.
+func (c Currency) Valid() bool {
+	_, ok := currencyExponents[c]
+	return ok
+}
+
+// Exponent returns the number of digits after the decimal point of the minor
+// unit of c: 2 for KZT (tiyn), 0 for JPY.
+func (c Currency) Exponent() int {
+	return currencyExponents[c]
+}
+
+// Money is an amount in the minor unit of its currency, so 1999 USD is $19.99.
+// The zero value has no currency and stands for an amount that is not set.
+//
+// Arithmetic only combines amounts in the same currency and fails rather than
+// overflow.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+// NewMoney returns amount minor units of currency.
+func NewMoney(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Add returns m + o.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrorCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrorMoneyOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o.
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrorMoneyOverflow
+	}
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Mul returns m times n, e.g. the price of n units.
+func (m Money) Mul(n int) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Amount: 0, Currency: m.Currency}, nil
+	}
+	a, b := m.Amount, int64(n)
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (a == math.MinInt64 && b == -1) {
+		return Money{}, ErrorMoneyOverflow
+	}
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// IsZero reports whether m is not set.
+func (m Money) IsZero() bool {
+	return m == Money{}
+}
+
+// IsPositive reports whether m is more than nothing.
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Float64 returns m in major units, e.g. 19.99 for 1999 USD. It is meant for
+// metrics and display only; amounts are never computed with it.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / math.Pow10(m.Currency.Exponent())
+}
+
+// String formats m in major units followed by its currency, e.g. "19.99 USD".
+func (m Money) String() string {
+	exp := m.Currency.Exponent()
+	if exp == 0 {
+		return strconv.FormatInt(m.Amount, 10) + " " + string(m.Currency)
+	}
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absInt64(amount), 10)
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	point := len(digits) - exp
+	return sign + digits[:point] + "." + digits[point:] + " " + string(m.Currency)
+}
+
+func absInt64(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+type moneyJSON struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount": 1999, "currency": "USD"}, the amount in
+// minor units.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Amount, Currency: string(m.Currency)})
+}
+
+// UnmarshalJSON decodes the encoding of MarshalJSON. The currency is required
+// and must be supported.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	currency, err := ParseCurrency(v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = Money{Amount: v.Amount, Currency: currency}
+	return nil
+}
@@ -0,0 +1,80 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_Arithmetic(t *testing.T) {
+	usd := func(amount int64) domain.Money { return domain.NewMoney(amount, "USD") }
+
+	sum, err := usd(1999).Add(usd(1))
+	assert.NoError(t, err)
+	assert.Equal(t, usd(2000), sum)
+
+	diff, err := usd(500).Sub(usd(750))
+	assert.NoError(t, err)
+	assert.Equal(t, usd(-250), diff)
+
+	product, err := usd(1999).Mul(3)
+	assert.NoError(t, err)
+	assert.Equal(t, usd(5997), product)
+
+	_, err = usd(1).Add(domain.NewMoney(1, "EUR"))
+	assert.ErrorIs(t, err, domain.ErrorCurrencyMismatch)
+
+	_, err = usd(math.MaxInt64).Add(usd(1))
+	assert.ErrorIs(t, err, domain.ErrorMoneyOverflow)
+
+	_, err = usd(math.MinInt64).Sub(usd(1))
+	assert.ErrorIs(t, err, domain.ErrorMoneyOverflow)
+
+	_, err = usd(math.MaxInt64 / 2).Mul(3)
+	assert.ErrorIs(t, err, domain.ErrorMoneyOverflow)
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		money    domain.Money
+		expected string
+	}{
+		{domain.NewMoney(1999, "USD"), "19.99 USD"},
+		{domain.NewMoney(5, "KZT"), "0.05 KZT"},
+		{domain.NewMoney(-120, "EUR"), "-1.20 EUR"},
+		{domain.NewMoney(500, "JPY"), "500 JPY"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.money.String())
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(domain.NewMoney(1999, "USD"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":1999,"currency":"USD"}`, string(data))
+
+	var m domain.Money
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":500,"currency":"kzt"}`), &m))
+	assert.Equal(t, domain.NewMoney(500, "KZT"), m)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":500}`), &m), domain.ErrorInvalidCurrency)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":500,"currency":"XYZ"}`), &m), domain.ErrorInvalidCurrency)
+}
+
+func TestOrder_Total(t *testing.T) {
+	order := &domain.Order{Currency: "KZT", Items: []domain.OrderItem{
+		{ProductID: "p1", Quantity: 2, Price: domain.NewMoney(1000, "KZT")},
+		{ProductID: "p2", Quantity: 1, Price: domain.NewMoney(250, "KZT")},
+	}}
+	total, err := order.Total()
+	assert.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(2250, "KZT"), total)
+
+	order.Items[1].Price = domain.NewMoney(250, "USD")
+	_, err = order.Total()
+	assert.ErrorIs(t, err, domain.ErrorCurrencyMismatch)
+}
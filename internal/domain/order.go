@@ -12,10 +12,62 @@ const (
 	StatusCanceled  Status = "canceled"
 )
 
+// Valid reports whether s is one of the known order statuses.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusPaid, StatusDelivery, StatusCompleted, StatusCanceled:
+		return true
+	}
+	return false
+}
+
 type Order struct {
 	ID        string
 	UserID    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Status    Status
+	// Currency is what every price of the order is in. It is chosen when the
+	// order is placed and never changes.
+	Currency Currency
+	Items    []OrderItem
+	// ShippingAddress and BillingAddress are copies of the address book
+	// entries the order was placed with. When placing an order only their IDs
+	// are read.
+	ShippingAddress *Address
+	BillingAddress  *Address
+	// Version is incremented on every change and guards against lost updates.
+	Version int
+}
+
+// OrderItem is a product line of an order. VariantID names the variant that
+// was ordered, if the product has variants. Price is the unit price in the order
+// currency at the time the order was placed; Allocations are the warehouses it
+// was reserved in.
+type OrderItem struct {
+	ProductID   string
+	VariantID   string
+	Quantity    int
+	Price       Money
+	Allocations []StockAllocation
+}
+
+// Line returns the order line the item belongs to.
+func (i OrderItem) Line() OrderLine {
+	return OrderLine{ProductID: i.ProductID, VariantID: i.VariantID}
+}
+
+// Total returns the sum of all order lines in the order currency.
+func (o *Order) Total() (Money, error) {
+	total := NewMoney(0, o.Currency)
+	for _, item := range o.Items {
+		line, err := item.Price.Mul(item.Quantity)
+		if err != nil {
+			return Money{}, err
+		}
+		if total, err = total.Add(line); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
 }
@@ -0,0 +1,16 @@
+package domain
+
+import "slices"
+
+// AnyVersion stands for whatever version a resource is stored at, for changes
+// that are not conditional on a particular version (If-Match: *).
+const AnyVersion = -1
+
+// FieldMask names the fields changed by a partial update, using the JSON
+// member names of the resource (e.g. "address").
+type FieldMask []string
+
+// Has reports whether field is part of the mask.
+func (m FieldMask) Has(field string) bool {
+	return slices.Contains(m, field)
+}
@@ -1,10 +1,47 @@
 package domain
 
 type Product struct {
-	ID         string
-	Name       string
-	Price      int
-	SKU        string
-	Amount     int
+	ID   string
+	Name string
+	// Price is the base price. Prices lists what the product costs in other
+	// currencies, sorted by currency.
+	Price  Money
+	Prices []Money
+	SKU    string
+	// Amount is the total of Stock.
+	Amount int
+	Stock  []WarehouseStock
+	// Variants are the versions the product is sold in; a product with
+	// variants is ordered through them.
+	Variants []Variant
+	// Images is the gallery of the product in display order.
+	Images     []ProductImage
 	CategoryID *string
+	// ReorderPoint is the amount at or below which the product is reported
+	// low on stock; ReorderQuantity is how much is usually bought then.
+	ReorderPoint    int
+	ReorderQuantity int
+	SupplierID      *string
+	// Version is incremented on every change and guards against lost updates.
+	Version int
+}
+
+// PriceIn returns the price of the product in currency, if it is sold in it.
+func (p *Product) PriceIn(currency Currency) (Money, bool) {
+	if p.Price.Currency == currency {
+		return p.Price, true
+	}
+	for _, price := range p.Prices {
+		if price.Currency == currency {
+			return price, true
+		}
+	}
+	return Money{}, false
+}
+
+// ReorderLine is a product to reorder with the supplier it is bought from, if
+// any.
+type ReorderLine struct {
+	Product  *Product
+	Supplier *Supplier
 }
@@ -0,0 +1,87 @@
+package domain
+
+import "time"
+
+type ReturnStatus string
+
+const (
+	ReturnRequested ReturnStatus = "requested"
+	ReturnApproved  ReturnStatus = "approved"
+	ReturnRejected  ReturnStatus = "rejected"
+	// ReturnReceived means the items are back in the warehouse and restocked.
+	ReturnReceived ReturnStatus = "received"
+	// ReturnRefunded means RefundAmount was credited to the user's balance.
+	ReturnRefunded ReturnStatus = "refunded"
+)
+
+// CanBecome reports whether a return in status s may move to next.
+func (s ReturnStatus) CanBecome(next ReturnStatus) bool {
+	switch s {
+	case ReturnRequested:
+		return next == ReturnApproved || next == ReturnRejected
+	case ReturnApproved:
+		return next == ReturnReceived || next == ReturnRejected
+	case ReturnReceived:
+		return next == ReturnRefunded
+	}
+	return false
+}
+
+type ReturnReason string
+
+const (
+	ReasonDamaged        ReturnReason = "damaged"
+	ReasonWrongItem      ReturnReason = "wrong_item"
+	ReasonNotAsDescribed ReturnReason = "not_as_described"
+	ReasonNoLongerNeeded ReturnReason = "no_longer_needed"
+	ReasonOther          ReturnReason = "other"
+)
+
+// Valid reports whether r is one of the known return reasons.
+func (r ReturnReason) Valid() bool {
+	switch r {
+	case ReasonDamaged, ReasonWrongItem, ReasonNotAsDescribed, ReasonNoLongerNeeded, ReasonOther:
+		return true
+	}
+	return false
+}
+
+// Return is a customer's request to send back items of a completed order.
+// Staff approve or reject it, restock the items once they arrive and refund
+// the user.
+type Return struct {
+	ID      string
+	OrderID string
+	UserID  string
+	Status  ReturnStatus
+	Items   []ReturnItem
+	Comment string
+	// RefundAmount is what the returned items cost when the order was placed.
+	RefundAmount Money
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	// History lists every status change, oldest first, starting with the
+	// request itself.
+	History []ReturnStatusChange
+}
+
+type ReturnItem struct {
+	ProductID string
+	VariantID string
+	Quantity  int
+	Reason    ReturnReason
+}
+
+// Line returns the order line the item belongs to.
+func (i ReturnItem) Line() OrderLine {
+	return OrderLine{ProductID: i.ProductID, VariantID: i.VariantID}
+}
+
+// ReturnStatusChange records one step of a return. From is empty for the
+// request itself.
+type ReturnStatusChange struct {
+	From ReturnStatus
+	To   ReturnStatus
+	Note string
+	At   time.Time
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Shipment is a parcel sent for an order. An order may be shipped in several
+// parcels, each with some of its line items.
+type Shipment struct {
+	ID             string
+	OrderID        string
+	Carrier        string
+	TrackingNumber string
+	Items          []ShipmentItem
+	ShippedAt      time.Time
+	// DeliveredAt is nil until the carrier reports the parcel delivered.
+	DeliveredAt *time.Time
+}
+
+type ShipmentItem struct {
+	ProductID string
+	VariantID string
+	Quantity  int
+}
+
+// Line returns the order line the item belongs to.
+func (i ShipmentItem) Line() OrderLine {
+	return OrderLine{ProductID: i.ProductID, VariantID: i.VariantID}
+}
+
+// Delivered reports whether the shipment has arrived.
+func (s *Shipment) Delivered() bool {
+	return s.DeliveredAt != nil
+}
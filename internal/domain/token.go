@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type TokenPurpose string
+
+const (
+	TokenEmailVerification TokenPurpose = "email_verification"
+	TokenPasswordReset     TokenPurpose = "password_reset"
+)
+
+// UserToken is a single-use secret mailed to a user to prove they own their
+// email address. Only the SHA-256 hash of the token is stored.
+type UserToken struct {
+	Hash    string
+	UserID  string
+	Purpose TokenPurpose
+	// Email is the address the token was sent to; the token is void once the
+	// user's email changes.
+	Email     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
@@ -3,13 +3,18 @@ package domain
 import "time"
 
 type User struct {
-	ID        string
-	Name      string
-	Email     string
-	Password  string
-	Number    string
-	Address   string
-	Balance   int
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       string
+	Name     string
+	Email    string
+	Password string
+	Number   string
+	Address  string
+	Balance  Money
+	// EmailVerified is set once the user proves they own Email and cleared
+	// whenever Email changes.
+	EmailVerified bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	// Version is incremented on every change and guards against lost updates.
+	Version int
 }
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Variant is a version of a product that is sold on its own, such as one size
+// and colour of a shirt. Options tell it apart from the other variants of the
+// product, e.g. {"size": "M", "color": "red"}.
+type Variant struct {
+	ID        string
+	ProductID string
+	SKU       string
+	Options   map[string]string
+	// Price overrides the base price of the product when set and is in the
+	// same currency. Variants with their own price are not sold in the other
+	// currencies of the product.
+	Price *Money
+	// Amount is the part of the product amount that is this variant.
+	Amount    int
+	CreatedAt time.Time
+}
+
+// OrderLine identifies a line of an order: a product, or one of its variants.
+// VariantID is empty for products without variants.
+type OrderLine struct {
+	ProductID string
+	VariantID string
+}
+
+func (l OrderLine) String() string {
+	if l.VariantID == "" {
+		return fmt.Sprintf("product %q", l.ProductID)
+	}
+	return fmt.Sprintf("variant %q of product %q", l.VariantID, l.ProductID)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// DefaultWarehouse holds the stock that was kept before there were several
+// warehouses.
+const DefaultWarehouse = "main"
+
+type Warehouse struct {
+	ID   string
+	Name string
+	// Country is an ISO 3166-1 alpha-2 code such as "KZ"; orders shipping to
+	// it are nearest to the warehouse.
+	Country   string
+	CreatedAt time.Time
+}
+
+// WarehouseStock is the amount of a product, over all its variants, kept in a
+// warehouse.
+type WarehouseStock struct {
+	WarehouseID string
+	Amount      int
+}
+
+// StockAllocation is the part of an order line reserved in a warehouse.
+type StockAllocation struct {
+	WarehouseID string
+	Quantity    int
+}
+
+// FulfillmentStrategy decides which warehouses an order line is reserved in.
+// Lines are taken from the first warehouse in the strategy's order and only
+// spill over to the next one if it runs out.
+type FulfillmentStrategy string
+
+const (
+	// FulfillNearest prefers warehouses in the country the order ships to.
+	FulfillNearest FulfillmentStrategy = "nearest"
+	// FulfillMostStock prefers the warehouses with the most stock of the product.
+	FulfillMostStock FulfillmentStrategy = "most_stock"
+)
+
+// Valid reports whether s is one of the known strategies.
+func (s FulfillmentStrategy) Valid() bool {
+	return s == FulfillNearest || s == FulfillMostStock
+}
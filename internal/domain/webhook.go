@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// WebhookSubscription asks for the events of the listed types to be POSTed to
+// URL, signed with Secret.
+type WebhookSubscription struct {
+	ID         string
+	URL        string
+	EventTypes []EventType
+	Secret     string
+	CreatedAt  time.Time
+}
+
+// Matches reports whether the subscription wants events of type t.
+func (s *WebhookSubscription) Matches(t EventType) bool {
+	return slices.Contains(s.EventTypes, t)
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to one subscription. Payload is the exact
+// request body, so retries carry the same bytes and signature input.
+type WebhookDelivery struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      EventType
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	// ResponseStatus is the HTTP status of the last attempt, 0 if none was received.
+	ResponseStatus int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	// RedeliveryOf is the ID of the delivery this one was manually repeated from.
+	RedeliveryOf *string
+}
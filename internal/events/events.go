@@ -0,0 +1,41 @@
+// Package events delivers domain events taken from the outbox to other
+// systems.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// Sink receives domain events. Delivery is at least once, so sinks and their
+// consumers should deduplicate by event ID.
+type Sink interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// Envelope is the JSON representation of an event handed to external systems.
+type Envelope struct {
+	ID          string           `json:"id"`
+	Type        domain.EventType `json:"type"`
+	AggregateID string           `json:"aggregate_id"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Payload     any              `json:"payload"`
+}
+
+func NewEnvelope(event domain.Event) Envelope {
+	return Envelope{
+		ID:          event.ID,
+		Type:        event.Type,
+		AggregateID: event.AggregateID,
+		CreatedAt:   event.CreatedAt,
+		Payload:     event.Payload,
+	}
+}
+
+// Marshal returns the JSON encoded envelope of event.
+func Marshal(event domain.Event) ([]byte, error) {
+	return json.Marshal(NewEnvelope(event))
+}
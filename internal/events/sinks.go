@@ -0,0 +1,119 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// LogSink writes events to a structured logger.
+type LogSink struct {
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+func (s LogSink) Publish(ctx context.Context, event domain.Event) error {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	body, err := Marshal(event)
+	if err != nil {
+		return err
+	}
+	logger.InfoContext(ctx, "domain event",
+		"event_id", event.ID,
+		"event_type", string(event.Type),
+		"aggregate_id", event.AggregateID,
+		"event", string(body),
+	)
+	return nil
+}
+
+// WebhookSink POSTs every event as a JSON envelope to URL. Any response other
+// than 2xx is a failed delivery.
+type WebhookSink struct {
+	URL string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (s WebhookSink) Publish(ctx context.Context, event domain.Event) error {
+	body, err := Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// MemorySink keeps published events in memory, for tests.
+type MemorySink struct {
+	mu     sync.Mutex
+	events []domain.Event
+	// Err, if set, is returned by Publish instead of storing the event.
+	Err error
+}
+
+func (s *MemorySink) Publish(_ context.Context, event domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Err != nil {
+		return s.Err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+// Events returns the events published so far.
+func (s *MemorySink) Events() []domain.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]domain.Event(nil), s.events...)
+}
+
+// Multi publishes every event to all of its sinks. An event is retried when
+// any sink fails, so the others may see it more than once and must tolerate
+// that; the notifier does so by skipping events it has already mailed.
+type Multi []Sink
+
+func (m Multi) Publish(ctx context.Context, event domain.Event) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package events_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink_Publish(t *testing.T) {
+	order := &domain.Order{
+		ID:       "o1",
+		UserID:   "u1",
+		Status:   domain.StatusPending,
+		Currency: "USD",
+		Items:    []domain.OrderItem{{ProductID: "p1", Quantity: 2, Price: domain.NewMoney(50, "USD")}},
+	}
+	event := domain.NewOrderCreated(order)
+
+	var got map[string]any
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, event.ID, r.Header.Get("X-Event-ID"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sink := events.WebhookSink{URL: receiver.URL, Client: receiver.Client()}
+	require.NoError(t, sink.Publish(t.Context(), event))
+
+	assert.Equal(t, "order.created", got["type"])
+	assert.Equal(t, "o1", got["aggregate_id"])
+	assert.Equal(t, map[string]any{
+		"order_id": "o1",
+		"user_id":  "u1",
+		"status":   "pending",
+		"items": []any{map[string]any{
+			"product_id": "p1",
+			"quantity":   float64(2),
+			"price":      map[string]any{"amount": float64(50), "currency": "USD"},
+		}},
+		"total": map[string]any{"amount": float64(100), "currency": "USD"},
+	}, got["payload"])
+}
+
+func TestWebhookSink_PublishRejected(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	sink := events.WebhookSink{URL: receiver.URL, Client: receiver.Client()}
+	err := sink.Publish(t.Context(), domain.Event{ID: "e1", Type: domain.EventStockLow, CreatedAt: time.Now()})
+	assert.ErrorContains(t, err, "503")
+}
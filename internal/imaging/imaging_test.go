@@ -0,0 +1,42 @@
+package imaging_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		size          int
+		expected      image.Rectangle
+	}{
+		{name: "landscape", width: 400, height: 200, size: 100, expected: image.Rect(0, 0, 100, 50)},
+		{name: "portrait", width: 300, height: 600, size: 150, expected: image.Rect(0, 0, 75, 150)},
+		{name: "thin strip keeps a pixel", width: 1000, height: 2, size: 100, expected: image.Rect(0, 0, 100, 1)},
+		{name: "small images are not enlarged", width: 40, height: 30, size: 100, expected: image.Rect(0, 0, 40, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			assert.Equal(t, tt.expected, imaging.Fit(src, tt.size).Bounds())
+		})
+	}
+}
+
+func TestFit_AveragesPixels(t *testing.T) {
+	// A 2×2 checkerboard of black and white becomes one grey pixel.
+	src := image.NewGray(image.Rect(10, 10, 12, 12))
+	src.SetGray(10, 10, color.Gray{Y: 255})
+	src.SetGray(11, 11, color.Gray{Y: 255})
+
+	dst := imaging.Fit(src, 1)
+
+	assert.Equal(t, color.RGBA{R: 127, G: 127, B: 127, A: 255}, dst.RGBAAt(0, 0))
+}
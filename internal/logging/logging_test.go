@@ -0,0 +1,58 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name              string
+		ctx               context.Context
+		expectedRequestID any
+	}{
+		{
+			name:              "with request id",
+			ctx:               logging.WithRequestID(context.Background(), "req-1"),
+			expectedRequestID: "req-1",
+		},
+		{
+			name:              "without request id",
+			ctx:               context.Background(),
+			expectedRequestID: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := logging.New(&buf, "info")
+			assert.NoError(t, err)
+
+			logger.With("component", "test").InfoContext(tt.ctx, "hello")
+
+			var record map[string]any
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, "hello", record["msg"])
+			assert.Equal(t, "test", record["component"])
+			assert.Equal(t, tt.expectedRequestID, record["request_id"])
+		})
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "warn")
+	assert.NoError(t, err)
+
+	logger.Info("dropped")
+	assert.Empty(t, buf.String())
+
+	_, err = logging.New(&buf, "loud")
+	assert.Error(t, err)
+}
@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/aibekfatkhulla/shop/internal/domain"
 	gomock "go.uber.org/mock/gomock"
@@ -41,6 +42,25 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddEvents mocks base method.
+func (m *MockRepository) AddEvents(ctx context.Context, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddEvents", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEvents indicates an expected call of AddEvents.
+func (mr *MockRepositoryMockRecorder) AddEvents(ctx any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvents", reflect.TypeOf((*MockRepository)(nil).AddEvents), varargs...)
+}
+
 // AddProductToCategory mocks base method.
 func (m *MockRepository) AddProductToCategory(ctx context.Context, categoryID, productID string) error {
 	m.ctrl.T.Helper()
@@ -55,32 +75,375 @@ func (mr *MockRepositoryMockRecorder) AddProductToCategory(ctx, categoryID, prod
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProductToCategory", reflect.TypeOf((*MockRepository)(nil).AddProductToCategory), ctx, categoryID, productID)
 }
 
+// AdjustStock mocks base method.
+func (m_2 *MockRepository) AdjustStock(ctx context.Context, m *domain.InventoryMovement, events ...domain.Event) error {
+	m_2.ctrl.T.Helper()
+	varargs := []any{ctx, m}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m_2.ctrl.Call(m_2, "AdjustStock", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockRepositoryMockRecorder) AdjustStock(ctx, m any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, m}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockRepository)(nil).AdjustStock), varargs...)
+}
+
+// CancelOrder mocks base method.
+func (m *MockRepository) CancelOrder(ctx context.Context, order *domain.Order, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, order}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CancelOrder", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockRepositoryMockRecorder) CancelOrder(ctx, order any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, order}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockRepository)(nil).CancelOrder), varargs...)
+}
+
+// CancelStaleOrders mocks base method.
+func (m *MockRepository) CancelStaleOrders(ctx context.Context, createdBefore time.Time, limit int, at time.Time) ([]*domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelStaleOrders", ctx, createdBefore, limit, at)
+	ret0, _ := ret[0].([]*domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelStaleOrders indicates an expected call of CancelStaleOrders.
+func (mr *MockRepositoryMockRecorder) CancelStaleOrders(ctx, createdBefore, limit, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelStaleOrders", reflect.TypeOf((*MockRepository)(nil).CancelStaleOrders), ctx, createdBefore, limit, at)
+}
+
+// ClaimEvents mocks base method.
+func (m *MockRepository) ClaimEvents(ctx context.Context, limit, maxAttempts int, now, leaseUntil time.Time) ([]domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimEvents", ctx, limit, maxAttempts, now, leaseUntil)
+	ret0, _ := ret[0].([]domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimEvents indicates an expected call of ClaimEvents.
+func (mr *MockRepositoryMockRecorder) ClaimEvents(ctx, limit, maxAttempts, now, leaseUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimEvents", reflect.TypeOf((*MockRepository)(nil).ClaimEvents), ctx, limit, maxAttempts, now, leaseUntil)
+}
+
+// ClaimLowStock mocks base method.
+func (m *MockRepository) ClaimLowStock(ctx context.Context, limit int, at time.Time) ([]domain.ReorderLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimLowStock", ctx, limit, at)
+	ret0, _ := ret[0].([]domain.ReorderLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimLowStock indicates an expected call of ClaimLowStock.
+func (mr *MockRepositoryMockRecorder) ClaimLowStock(ctx, limit, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimLowStock", reflect.TypeOf((*MockRepository)(nil).ClaimLowStock), ctx, limit, at)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, now, leaseUntil time.Time) ([]*domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, limit, now, leaseUntil)
+	ret0, _ := ret[0].([]*domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) ClaimWebhookDeliveries(ctx, limit, now, leaseUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).ClaimWebhookDeliveries), ctx, limit, now, leaseUntil)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockRepository) CompleteIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) CompleteIdempotencyKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).CompleteIdempotencyKey), ctx, key)
+}
+
+// CreateAddress mocks base method.
+func (m *MockRepository) CreateAddress(ctx context.Context, address *domain.Address) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAddress", ctx, address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAddress indicates an expected call of CreateAddress.
+func (mr *MockRepositoryMockRecorder) CreateAddress(ctx, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAddress", reflect.TypeOf((*MockRepository)(nil).CreateAddress), ctx, address)
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockRepository) CreateIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) CreateIdempotencyKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).CreateIdempotencyKey), ctx, key)
+}
+
 // CreateOrder mocks base method.
-func (m *MockRepository) CreateOrder(ctx context.Context, order *domain.Order) error {
+func (m *MockRepository) CreateOrder(ctx context.Context, order *domain.Order, strategy domain.FulfillmentStrategy, events ...domain.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateOrder", ctx, order)
+	varargs := []any{ctx, order, strategy}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateOrder", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateOrder indicates an expected call of CreateOrder.
-func (mr *MockRepositoryMockRecorder) CreateOrder(ctx, order any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CreateOrder(ctx, order, strategy any, events ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockRepository)(nil).CreateOrder), ctx, order)
+	varargs := append([]any{ctx, order, strategy}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockRepository)(nil).CreateOrder), varargs...)
+}
+
+// CreateProductImage mocks base method.
+func (m *MockRepository) CreateProductImage(ctx context.Context, img *domain.ProductImage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductImage", ctx, img)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProductImage indicates an expected call of CreateProductImage.
+func (mr *MockRepositoryMockRecorder) CreateProductImage(ctx, img any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductImage", reflect.TypeOf((*MockRepository)(nil).CreateProductImage), ctx, img)
+}
+
+// CreateReturn mocks base method.
+func (m *MockRepository) CreateReturn(ctx context.Context, ret *domain.Return, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, ret}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret_2 := m.ctrl.Call(m, "CreateReturn", varargs...)
+	ret0, _ := ret_2[0].(error)
+	return ret0
+}
+
+// CreateReturn indicates an expected call of CreateReturn.
+func (mr *MockRepositoryMockRecorder) CreateReturn(ctx, ret any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, ret}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReturn", reflect.TypeOf((*MockRepository)(nil).CreateReturn), varargs...)
+}
+
+// CreateShipment mocks base method.
+func (m *MockRepository) CreateShipment(ctx context.Context, shipment *domain.Shipment, order *domain.Order, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, shipment, order}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateShipment", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateShipment indicates an expected call of CreateShipment.
+func (mr *MockRepositoryMockRecorder) CreateShipment(ctx, shipment, order any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, shipment, order}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShipment", reflect.TypeOf((*MockRepository)(nil).CreateShipment), varargs...)
 }
 
 // CreateUser mocks base method.
-func (m *MockRepository) CreateUser(ctx context.Context, user *domain.User) error {
+func (m *MockRepository) CreateUser(ctx context.Context, user *domain.User, events ...domain.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
+	varargs := []any{ctx, user}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateUser", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockRepositoryMockRecorder) CreateUser(ctx, user any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CreateUser(ctx, user any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, user}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), varargs...)
+}
+
+// CreateUserToken mocks base method.
+func (m *MockRepository) CreateUserToken(ctx context.Context, token *domain.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUserToken indicates an expected call of CreateUserToken.
+func (mr *MockRepositoryMockRecorder) CreateUserToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserToken", reflect.TypeOf((*MockRepository)(nil).CreateUserToken), ctx, token)
+}
+
+// CreateVariant mocks base method.
+func (m *MockRepository) CreateVariant(ctx context.Context, variant *domain.Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVariant", ctx, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVariant indicates an expected call of CreateVariant.
+func (mr *MockRepositoryMockRecorder) CreateVariant(ctx, variant any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVariant", reflect.TypeOf((*MockRepository)(nil).CreateVariant), ctx, variant)
+}
+
+// CreateWarehouse mocks base method.
+func (m *MockRepository) CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWarehouse", ctx, warehouse)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWarehouse indicates an expected call of CreateWarehouse.
+func (mr *MockRepositoryMockRecorder) CreateWarehouse(ctx, warehouse any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWarehouse", reflect.TypeOf((*MockRepository)(nil).CreateWarehouse), ctx, warehouse)
+}
+
+// CreateWebhookDelivery mocks base method.
+func (m *MockRepository) CreateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) CreateWebhookDelivery(ctx, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).CreateWebhookDelivery), ctx, d)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockRepository) CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) CreateWebhookSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).CreateWebhookSubscription), ctx, sub)
+}
+
+// DeleteAddress mocks base method.
+func (m *MockRepository) DeleteAddress(ctx context.Context, userID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAddress", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAddress indicates an expected call of DeleteAddress.
+func (mr *MockRepositoryMockRecorder) DeleteAddress(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddress", reflect.TypeOf((*MockRepository)(nil).DeleteAddress), ctx, userID, id)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredIdempotencyKeys(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredIdempotencyKeys), ctx, now)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockRepository) DeleteIdempotencyKey(ctx context.Context, key, scope string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", ctx, key, scope)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) DeleteIdempotencyKey(ctx, key, scope any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).DeleteIdempotencyKey), ctx, key, scope)
+}
+
+// DeleteProductImage mocks base method.
+func (m *MockRepository) DeleteProductImage(ctx context.Context, productID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductImage", ctx, productID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductImage indicates an expected call of DeleteProductImage.
+func (mr *MockRepositoryMockRecorder) DeleteProductImage(ctx, productID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductImage", reflect.TypeOf((*MockRepository)(nil).DeleteProductImage), ctx, productID, id)
+}
+
+// DeleteProductPrice mocks base method.
+func (m *MockRepository) DeleteProductPrice(ctx context.Context, productID string, currency domain.Currency) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductPrice", ctx, productID, currency)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductPrice indicates an expected call of DeleteProductPrice.
+func (mr *MockRepositoryMockRecorder) DeleteProductPrice(ctx, productID, currency any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductPrice", reflect.TypeOf((*MockRepository)(nil).DeleteProductPrice), ctx, productID, currency)
 }
 
 // DeleteSupplierByID mocks base method.
@@ -97,6 +460,83 @@ func (mr *MockRepositoryMockRecorder) DeleteSupplierByID(ctx, id any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSupplierByID", reflect.TypeOf((*MockRepository)(nil).DeleteSupplierByID), ctx, id)
 }
 
+// DeleteWebhookSubscription mocks base method.
+func (m *MockRepository) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) DeleteWebhookSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).DeleteWebhookSubscription), ctx, id)
+}
+
+// DeliverShipment mocks base method.
+func (m *MockRepository) DeliverShipment(ctx context.Context, shipment *domain.Shipment, order *domain.Order, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, shipment, order}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeliverShipment", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeliverShipment indicates an expected call of DeliverShipment.
+func (mr *MockRepositoryMockRecorder) DeliverShipment(ctx, shipment, order any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, shipment, order}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverShipment", reflect.TypeOf((*MockRepository)(nil).DeliverShipment), varargs...)
+}
+
+// EnqueueWebhookDeliveries mocks base method.
+func (m *MockRepository) EnqueueWebhookDeliveries(ctx context.Context, event domain.Event, payload []byte, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueWebhookDeliveries", ctx, event, payload, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueWebhookDeliveries indicates an expected call of EnqueueWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) EnqueueWebhookDeliveries(ctx, event, payload, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).EnqueueWebhookDeliveries), ctx, event, payload, now)
+}
+
+// FailEvent mocks base method.
+func (m *MockRepository) FailEvent(ctx context.Context, id string, cause error, retryAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailEvent", ctx, id, cause, retryAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailEvent indicates an expected call of FailEvent.
+func (mr *MockRepositoryMockRecorder) FailEvent(ctx, id, cause, retryAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailEvent", reflect.TypeOf((*MockRepository)(nil).FailEvent), ctx, id, cause, retryAt)
+}
+
+// GetAddress mocks base method.
+func (m *MockRepository) GetAddress(ctx context.Context, userID, id string) (*domain.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddress", ctx, userID, id)
+	ret0, _ := ret[0].(*domain.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddress indicates an expected call of GetAddress.
+func (mr *MockRepositoryMockRecorder) GetAddress(ctx, userID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddress", reflect.TypeOf((*MockRepository)(nil).GetAddress), ctx, userID, id)
+}
+
 // GetByEmail mocks base method.
 func (m *MockRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -112,6 +552,36 @@ func (mr *MockRepositoryMockRecorder) GetByEmail(ctx, email any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockRepository)(nil).GetByEmail), ctx, email)
 }
 
+// GetDefaultAddress mocks base method.
+func (m *MockRepository) GetDefaultAddress(ctx context.Context, userID string) (*domain.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultAddress", ctx, userID)
+	ret0, _ := ret[0].(*domain.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDefaultAddress indicates an expected call of GetDefaultAddress.
+func (mr *MockRepositoryMockRecorder) GetDefaultAddress(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultAddress", reflect.TypeOf((*MockRepository)(nil).GetDefaultAddress), ctx, userID)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockRepository) GetIdempotencyKey(ctx context.Context, key, scope string) (*domain.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", ctx, key, scope)
+	ret0, _ := ret[0].(*domain.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) GetIdempotencyKey(ctx, key, scope any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).GetIdempotencyKey), ctx, key, scope)
+}
+
 // GetOrderByID mocks base method.
 func (m *MockRepository) GetOrderByID(ctx context.Context, ID string) (*domain.Order, error) {
 	m.ctrl.T.Helper()
@@ -142,6 +612,36 @@ func (mr *MockRepositoryMockRecorder) GetProductByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByID", reflect.TypeOf((*MockRepository)(nil).GetProductByID), ctx, id)
 }
 
+// GetProductImage mocks base method.
+func (m *MockRepository) GetProductImage(ctx context.Context, productID, id string) (*domain.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductImage", ctx, productID, id)
+	ret0, _ := ret[0].(*domain.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductImage indicates an expected call of GetProductImage.
+func (mr *MockRepositoryMockRecorder) GetProductImage(ctx, productID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductImage", reflect.TypeOf((*MockRepository)(nil).GetProductImage), ctx, productID, id)
+}
+
+// GetReturn mocks base method.
+func (m *MockRepository) GetReturn(ctx context.Context, id string) (*domain.Return, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReturn", ctx, id)
+	ret0, _ := ret[0].(*domain.Return)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReturn indicates an expected call of GetReturn.
+func (mr *MockRepositoryMockRecorder) GetReturn(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReturn", reflect.TypeOf((*MockRepository)(nil).GetReturn), ctx, id)
+}
+
 // GetSupplierByID mocks base method.
 func (m *MockRepository) GetSupplierByID(ctx context.Context, id string) (*domain.Supplier, error) {
 	m.ctrl.T.Helper()
@@ -172,6 +672,96 @@ func (mr *MockRepositoryMockRecorder) GetUserByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockRepository)(nil).GetUserByID), ctx, id)
 }
 
+// GetWebhookDelivery mocks base method.
+func (m *MockRepository) GetWebhookDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDelivery", ctx, id)
+	ret0, _ := ret[0].(*domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDelivery indicates an expected call of GetWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) GetWebhookDelivery(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).GetWebhookDelivery), ctx, id)
+}
+
+// GetWebhookSubscription mocks base method.
+func (m *MockRepository) GetWebhookSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscription", ctx, id)
+	ret0, _ := ret[0].(*domain.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscription indicates an expected call of GetWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) GetWebhookSubscription(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).GetWebhookSubscription), ctx, id)
+}
+
+// ListAddresses mocks base method.
+func (m *MockRepository) ListAddresses(ctx context.Context, userID string) ([]*domain.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAddresses", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAddresses indicates an expected call of ListAddresses.
+func (mr *MockRepositoryMockRecorder) ListAddresses(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAddresses", reflect.TypeOf((*MockRepository)(nil).ListAddresses), ctx, userID)
+}
+
+// ListInventoryMovements mocks base method.
+func (m *MockRepository) ListInventoryMovements(ctx context.Context, productID string, limit, offset int) ([]*domain.InventoryMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInventoryMovements", ctx, productID, limit, offset)
+	ret0, _ := ret[0].([]*domain.InventoryMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInventoryMovements indicates an expected call of ListInventoryMovements.
+func (mr *MockRepositoryMockRecorder) ListInventoryMovements(ctx, productID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInventoryMovements", reflect.TypeOf((*MockRepository)(nil).ListInventoryMovements), ctx, productID, limit, offset)
+}
+
+// ListJobRuns mocks base method.
+func (m *MockRepository) ListJobRuns(ctx context.Context) ([]*domain.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRuns", ctx)
+	ret0, _ := ret[0].([]*domain.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRuns indicates an expected call of ListJobRuns.
+func (mr *MockRepositoryMockRecorder) ListJobRuns(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRuns", reflect.TypeOf((*MockRepository)(nil).ListJobRuns), ctx)
+}
+
+// ListProductImages mocks base method.
+func (m *MockRepository) ListProductImages(ctx context.Context, productID string) ([]*domain.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductImages", ctx, productID)
+	ret0, _ := ret[0].([]*domain.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductImages indicates an expected call of ListProductImages.
+func (mr *MockRepositoryMockRecorder) ListProductImages(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductImages", reflect.TypeOf((*MockRepository)(nil).ListProductImages), ctx, productID)
+}
+
 // ListProducts mocks base method.
 func (m *MockRepository) ListProducts(ctx context.Context, limit, offset int) ([]*domain.Product, error) {
 	m.ctrl.T.Helper()
@@ -187,6 +777,66 @@ func (mr *MockRepositoryMockRecorder) ListProducts(ctx, limit, offset any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockRepository)(nil).ListProducts), ctx, limit, offset)
 }
 
+// ListReorderLines mocks base method.
+func (m *MockRepository) ListReorderLines(ctx context.Context) ([]domain.ReorderLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReorderLines", ctx)
+	ret0, _ := ret[0].([]domain.ReorderLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReorderLines indicates an expected call of ListReorderLines.
+func (mr *MockRepositoryMockRecorder) ListReorderLines(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReorderLines", reflect.TypeOf((*MockRepository)(nil).ListReorderLines), ctx)
+}
+
+// ListReturns mocks base method.
+func (m *MockRepository) ListReturns(ctx context.Context, orderID string) ([]*domain.Return, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReturns", ctx, orderID)
+	ret0, _ := ret[0].([]*domain.Return)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReturns indicates an expected call of ListReturns.
+func (mr *MockRepositoryMockRecorder) ListReturns(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReturns", reflect.TypeOf((*MockRepository)(nil).ListReturns), ctx, orderID)
+}
+
+// ListShipments mocks base method.
+func (m *MockRepository) ListShipments(ctx context.Context, orderID string) ([]*domain.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShipments", ctx, orderID)
+	ret0, _ := ret[0].([]*domain.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShipments indicates an expected call of ListShipments.
+func (mr *MockRepositoryMockRecorder) ListShipments(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShipments", reflect.TypeOf((*MockRepository)(nil).ListShipments), ctx, orderID)
+}
+
+// ListStockDiscrepancies mocks base method.
+func (m *MockRepository) ListStockDiscrepancies(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStockDiscrepancies", ctx)
+	ret0, _ := ret[0].([]domain.StockDiscrepancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStockDiscrepancies indicates an expected call of ListStockDiscrepancies.
+func (mr *MockRepositoryMockRecorder) ListStockDiscrepancies(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStockDiscrepancies", reflect.TypeOf((*MockRepository)(nil).ListStockDiscrepancies), ctx)
+}
+
 // ListUsers mocks base method.
 func (m *MockRepository) ListUsers(ctx context.Context) ([]*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -202,6 +852,145 @@ func (mr *MockRepositoryMockRecorder) ListUsers(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockRepository)(nil).ListUsers), ctx)
 }
 
+// ListWarehouses mocks base method.
+func (m *MockRepository) ListWarehouses(ctx context.Context) ([]*domain.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWarehouses", ctx)
+	ret0, _ := ret[0].([]*domain.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWarehouses indicates an expected call of ListWarehouses.
+func (mr *MockRepositoryMockRecorder) ListWarehouses(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWarehouses", reflect.TypeOf((*MockRepository)(nil).ListWarehouses), ctx)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, subscriptionID, limit)
+	ret0, _ := ret[0].([]*domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) ListWebhookDeliveries(ctx, subscriptionID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).ListWebhookDeliveries), ctx, subscriptionID, limit)
+}
+
+// ListWebhookSubscriptions mocks base method.
+func (m *MockRepository) ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptions", ctx)
+	ret0, _ := ret[0].([]*domain.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptions indicates an expected call of ListWebhookSubscriptions.
+func (mr *MockRepositoryMockRecorder) ListWebhookSubscriptions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptions", reflect.TypeOf((*MockRepository)(nil).ListWebhookSubscriptions), ctx)
+}
+
+// MarkEventDispatched mocks base method.
+func (m *MockRepository) MarkEventDispatched(ctx context.Context, id string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventDispatched", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventDispatched indicates an expected call of MarkEventDispatched.
+func (mr *MockRepositoryMockRecorder) MarkEventDispatched(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventDispatched", reflect.TypeOf((*MockRepository)(nil).MarkEventDispatched), ctx, id, at)
+}
+
+// PatchOrder mocks base method.
+func (m *MockRepository) PatchOrder(ctx context.Context, order *domain.Order, mask domain.FieldMask, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, order, mask}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PatchOrder", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchOrder indicates an expected call of PatchOrder.
+func (mr *MockRepositoryMockRecorder) PatchOrder(ctx, order, mask any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, order, mask}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchOrder", reflect.TypeOf((*MockRepository)(nil).PatchOrder), varargs...)
+}
+
+// PatchProduct mocks base method.
+func (m *MockRepository) PatchProduct(ctx context.Context, product *domain.Product, mask domain.FieldMask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchProduct", ctx, product, mask)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchProduct indicates an expected call of PatchProduct.
+func (mr *MockRepositoryMockRecorder) PatchProduct(ctx, product, mask any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchProduct", reflect.TypeOf((*MockRepository)(nil).PatchProduct), ctx, product, mask)
+}
+
+// PatchUser mocks base method.
+func (m *MockRepository) PatchUser(ctx context.Context, user *domain.User, mask domain.FieldMask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchUser", ctx, user, mask)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchUser indicates an expected call of PatchUser.
+func (mr *MockRepositoryMockRecorder) PatchUser(ctx, user, mask any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchUser", reflect.TypeOf((*MockRepository)(nil).PatchUser), ctx, user, mask)
+}
+
+// PayOrder mocks base method.
+func (m *MockRepository) PayOrder(ctx context.Context, order *domain.Order, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, order}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PayOrder", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PayOrder indicates an expected call of PayOrder.
+func (mr *MockRepositoryMockRecorder) PayOrder(ctx, order any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, order}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayOrder", reflect.TypeOf((*MockRepository)(nil).PayOrder), varargs...)
+}
+
+// Ping mocks base method.
+func (m *MockRepository) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockRepositoryMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), ctx)
+}
+
 // RemoveProductFromCategory mocks base method.
 func (m *MockRepository) RemoveProductFromCategory(ctx context.Context, categoryID, productID string) error {
 	m.ctrl.T.Helper()
@@ -216,18 +1005,112 @@ func (mr *MockRepositoryMockRecorder) RemoveProductFromCategory(ctx, categoryID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProductFromCategory", reflect.TypeOf((*MockRepository)(nil).RemoveProductFromCategory), ctx, categoryID, productID)
 }
 
+// ReorderProductImages mocks base method.
+func (m *MockRepository) ReorderProductImages(ctx context.Context, productID string, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderProductImages", ctx, productID, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderProductImages indicates an expected call of ReorderProductImages.
+func (mr *MockRepositoryMockRecorder) ReorderProductImages(ctx, productID, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderProductImages", reflect.TypeOf((*MockRepository)(nil).ReorderProductImages), ctx, productID, ids)
+}
+
+// ResetPassword mocks base method.
+func (m *MockRepository) ResetPassword(ctx context.Context, hash, password string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, hash, password, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockRepositoryMockRecorder) ResetPassword(ctx, hash, password, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockRepository)(nil).ResetPassword), ctx, hash, password, now)
+}
+
+// SaveJobRun mocks base method.
+func (m *MockRepository) SaveJobRun(ctx context.Context, run *domain.JobRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveJobRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveJobRun indicates an expected call of SaveJobRun.
+func (mr *MockRepositoryMockRecorder) SaveJobRun(ctx, run any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveJobRun", reflect.TypeOf((*MockRepository)(nil).SaveJobRun), ctx, run)
+}
+
+// SetProductPrice mocks base method.
+func (m *MockRepository) SetProductPrice(ctx context.Context, productID string, price domain.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductPrice", ctx, productID, price)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProductPrice indicates an expected call of SetProductPrice.
+func (mr *MockRepositoryMockRecorder) SetProductPrice(ctx, productID, price any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductPrice", reflect.TypeOf((*MockRepository)(nil).SetProductPrice), ctx, productID, price)
+}
+
+// UpdateAddress mocks base method.
+func (m *MockRepository) UpdateAddress(ctx context.Context, address *domain.Address) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAddress", ctx, address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAddress indicates an expected call of UpdateAddress.
+func (mr *MockRepositoryMockRecorder) UpdateAddress(ctx, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddress", reflect.TypeOf((*MockRepository)(nil).UpdateAddress), ctx, address)
+}
+
 // UpdateOrder mocks base method.
-func (m *MockRepository) UpdateOrder(ctx context.Context, order *domain.Order) error {
+func (m *MockRepository) UpdateOrder(ctx context.Context, order *domain.Order, events ...domain.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrder", ctx, order)
+	varargs := []any{ctx, order}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateOrder", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateOrder indicates an expected call of UpdateOrder.
-func (mr *MockRepositoryMockRecorder) UpdateOrder(ctx, order any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) UpdateOrder(ctx, order any, events ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockRepository)(nil).UpdateOrder), ctx, order)
+	varargs := append([]any{ctx, order}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockRepository)(nil).UpdateOrder), varargs...)
+}
+
+// UpdateReturnStatus mocks base method.
+func (m *MockRepository) UpdateReturnStatus(ctx context.Context, ret *domain.Return, change domain.ReturnStatusChange, events ...domain.Event) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, ret, change}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret_2 := m.ctrl.Call(m, "UpdateReturnStatus", varargs...)
+	ret0, _ := ret_2[0].(error)
+	return ret0
+}
+
+// UpdateReturnStatus indicates an expected call of UpdateReturnStatus.
+func (mr *MockRepositoryMockRecorder) UpdateReturnStatus(ctx, ret, change any, events ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, ret, change}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReturnStatus", reflect.TypeOf((*MockRepository)(nil).UpdateReturnStatus), varargs...)
 }
 
 // UpdateUser mocks base method.
@@ -243,3 +1126,31 @@ func (mr *MockRepositoryMockRecorder) UpdateUser(ctx, user any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockRepository)(nil).UpdateUser), ctx, user)
 }
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockRepository) UpdateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) UpdateWebhookDelivery(ctx, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).UpdateWebhookDelivery), ctx, d)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockRepository) VerifyEmail(ctx context.Context, hash string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, hash, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockRepositoryMockRecorder) VerifyEmail(ctx, hash, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockRepository)(nil).VerifyEmail), ctx, hash, now)
+}
@@ -11,6 +11,7 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	domain "github.com/aibekfatkhulla/shop/internal/domain"
@@ -55,6 +56,93 @@ func (mr *MockServiceMockRecorder) AddProductToCategory(ctx, categoryID, product
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProductToCategory", reflect.TypeOf((*MockService)(nil).AddProductToCategory), ctx, categoryID, productID)
 }
 
+// AdjustStock mocks base method.
+func (m_2 *MockService) AdjustStock(ctx context.Context, m *domain.InventoryMovement) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "AdjustStock", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockServiceMockRecorder) AdjustStock(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockService)(nil).AdjustStock), ctx, m)
+}
+
+// CancelStaleOrders mocks base method.
+func (m *MockService) CancelStaleOrders(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelStaleOrders", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelStaleOrders indicates an expected call of CancelStaleOrders.
+func (mr *MockServiceMockRecorder) CancelStaleOrders(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelStaleOrders", reflect.TypeOf((*MockService)(nil).CancelStaleOrders), ctx)
+}
+
+// CheckLowStock mocks base method.
+func (m *MockService) CheckLowStock(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLowStock", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckLowStock indicates an expected call of CheckLowStock.
+func (mr *MockServiceMockRecorder) CheckLowStock(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLowStock", reflect.TypeOf((*MockService)(nil).CheckLowStock), ctx)
+}
+
+// ClaimIdempotencyKey mocks base method.
+func (m *MockService) ClaimIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(*domain.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimIdempotencyKey indicates an expected call of ClaimIdempotencyKey.
+func (mr *MockServiceMockRecorder) ClaimIdempotencyKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimIdempotencyKey", reflect.TypeOf((*MockService)(nil).ClaimIdempotencyKey), ctx, key)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockService) CompleteIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockServiceMockRecorder) CompleteIdempotencyKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockService)(nil).CompleteIdempotencyKey), ctx, key)
+}
+
+// CreateAddress mocks base method.
+func (m *MockService) CreateAddress(ctx context.Context, address *domain.Address) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAddress", ctx, address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAddress indicates an expected call of CreateAddress.
+func (mr *MockServiceMockRecorder) CreateAddress(ctx, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAddress", reflect.TypeOf((*MockService)(nil).CreateAddress), ctx, address)
+}
+
 // CreateOrder mocks base method.
 func (m *MockService) CreateOrder(ctx context.Context, order *domain.Order) error {
 	m.ctrl.T.Helper()
@@ -69,6 +157,34 @@ func (mr *MockServiceMockRecorder) CreateOrder(ctx, order any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockService)(nil).CreateOrder), ctx, order)
 }
 
+// CreateReturn mocks base method.
+func (m *MockService) CreateReturn(ctx context.Context, ret *domain.Return) error {
+	m.ctrl.T.Helper()
+	ret_2 := m.ctrl.Call(m, "CreateReturn", ctx, ret)
+	ret0, _ := ret_2[0].(error)
+	return ret0
+}
+
+// CreateReturn indicates an expected call of CreateReturn.
+func (mr *MockServiceMockRecorder) CreateReturn(ctx, ret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReturn", reflect.TypeOf((*MockService)(nil).CreateReturn), ctx, ret)
+}
+
+// CreateShipment mocks base method.
+func (m *MockService) CreateShipment(ctx context.Context, shipment *domain.Shipment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShipment", ctx, shipment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateShipment indicates an expected call of CreateShipment.
+func (mr *MockServiceMockRecorder) CreateShipment(ctx, shipment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShipment", reflect.TypeOf((*MockService)(nil).CreateShipment), ctx, shipment)
+}
+
 // CreateUser mocks base method.
 func (m *MockService) CreateUser(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
@@ -83,6 +199,90 @@ func (mr *MockServiceMockRecorder) CreateUser(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockService)(nil).CreateUser), ctx, user)
 }
 
+// CreateVariant mocks base method.
+func (m *MockService) CreateVariant(ctx context.Context, variant *domain.Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVariant", ctx, variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVariant indicates an expected call of CreateVariant.
+func (mr *MockServiceMockRecorder) CreateVariant(ctx, variant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVariant", reflect.TypeOf((*MockService)(nil).CreateVariant), ctx, variant)
+}
+
+// CreateWarehouse mocks base method.
+func (m *MockService) CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWarehouse", ctx, warehouse)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWarehouse indicates an expected call of CreateWarehouse.
+func (mr *MockServiceMockRecorder) CreateWarehouse(ctx, warehouse any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWarehouse", reflect.TypeOf((*MockService)(nil).CreateWarehouse), ctx, warehouse)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockService) CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockServiceMockRecorder) CreateWebhookSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockService)(nil).CreateWebhookSubscription), ctx, sub)
+}
+
+// DeleteAddress mocks base method.
+func (m *MockService) DeleteAddress(ctx context.Context, userID, ID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAddress", ctx, userID, ID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAddress indicates an expected call of DeleteAddress.
+func (mr *MockServiceMockRecorder) DeleteAddress(ctx, userID, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddress", reflect.TypeOf((*MockService)(nil).DeleteAddress), ctx, userID, ID)
+}
+
+// DeleteProductImage mocks base method.
+func (m *MockService) DeleteProductImage(ctx context.Context, productID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductImage", ctx, productID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductImage indicates an expected call of DeleteProductImage.
+func (mr *MockServiceMockRecorder) DeleteProductImage(ctx, productID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductImage", reflect.TypeOf((*MockService)(nil).DeleteProductImage), ctx, productID, id)
+}
+
+// DeleteProductPrice mocks base method.
+func (m *MockService) DeleteProductPrice(ctx context.Context, productID string, currency domain.Currency) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductPrice", ctx, productID, currency)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductPrice indicates an expected call of DeleteProductPrice.
+func (mr *MockServiceMockRecorder) DeleteProductPrice(ctx, productID, currency any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductPrice", reflect.TypeOf((*MockService)(nil).DeleteProductPrice), ctx, productID, currency)
+}
+
 // DeleteSupplierByID mocks base method.
 func (m *MockService) DeleteSupplierByID(ctx context.Context, ID string) error {
 	m.ctrl.T.Helper()
@@ -97,6 +297,80 @@ func (mr *MockServiceMockRecorder) DeleteSupplierByID(ctx, ID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSupplierByID", reflect.TypeOf((*MockService)(nil).DeleteSupplierByID), ctx, ID)
 }
 
+// DeleteWebhookSubscription mocks base method.
+func (m *MockService) DeleteWebhookSubscription(ctx context.Context, ID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", ctx, ID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockServiceMockRecorder) DeleteWebhookSubscription(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockService)(nil).DeleteWebhookSubscription), ctx, ID)
+}
+
+// DeliverShipment mocks base method.
+func (m *MockService) DeliverShipment(ctx context.Context, orderID, shipmentID string) (*domain.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverShipment", ctx, orderID, shipmentID)
+	ret0, _ := ret[0].(*domain.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverShipment indicates an expected call of DeliverShipment.
+func (mr *MockServiceMockRecorder) DeliverShipment(ctx, orderID, shipmentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverShipment", reflect.TypeOf((*MockService)(nil).DeliverShipment), ctx, orderID, shipmentID)
+}
+
+// DeliverWebhooks mocks base method.
+func (m *MockService) DeliverWebhooks(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverWebhooks", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverWebhooks indicates an expected call of DeliverWebhooks.
+func (mr *MockServiceMockRecorder) DeliverWebhooks(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverWebhooks", reflect.TypeOf((*MockService)(nil).DeliverWebhooks), ctx)
+}
+
+// DispatchEvents mocks base method.
+func (m *MockService) DispatchEvents(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DispatchEvents", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DispatchEvents indicates an expected call of DispatchEvents.
+func (mr *MockServiceMockRecorder) DispatchEvents(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DispatchEvents", reflect.TypeOf((*MockService)(nil).DispatchEvents), ctx)
+}
+
+// GetAddress mocks base method.
+func (m *MockService) GetAddress(ctx context.Context, userID, ID string) (*domain.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddress", ctx, userID, ID)
+	ret0, _ := ret[0].(*domain.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddress indicates an expected call of GetAddress.
+func (mr *MockServiceMockRecorder) GetAddress(ctx, userID, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddress", reflect.TypeOf((*MockService)(nil).GetAddress), ctx, userID, ID)
+}
+
 // GetOrderByID mocks base method.
 func (m *MockService) GetOrderByID(ctx context.Context, ID string) (*domain.Order, error) {
 	m.ctrl.T.Helper()
@@ -127,6 +401,21 @@ func (mr *MockServiceMockRecorder) GetProductByID(ctx, ID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByID", reflect.TypeOf((*MockService)(nil).GetProductByID), ctx, ID)
 }
 
+// GetReturn mocks base method.
+func (m *MockService) GetReturn(ctx context.Context, ID string) (*domain.Return, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReturn", ctx, ID)
+	ret0, _ := ret[0].(*domain.Return)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReturn indicates an expected call of GetReturn.
+func (mr *MockServiceMockRecorder) GetReturn(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReturn", reflect.TypeOf((*MockService)(nil).GetReturn), ctx, ID)
+}
+
 // GetSupplierByID mocks base method.
 func (m *MockService) GetSupplierByID(ctx context.Context, ID string) (*domain.Supplier, error) {
 	m.ctrl.T.Helper()
@@ -142,6 +431,96 @@ func (mr *MockServiceMockRecorder) GetSupplierByID(ctx, ID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSupplierByID", reflect.TypeOf((*MockService)(nil).GetSupplierByID), ctx, ID)
 }
 
+// GetUserByID mocks base method.
+func (m *MockService) GetUserByID(ctx context.Context, ID string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, ID)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockServiceMockRecorder) GetUserByID(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockService)(nil).GetUserByID), ctx, ID)
+}
+
+// GetWebhookSubscription mocks base method.
+func (m *MockService) GetWebhookSubscription(ctx context.Context, ID string) (*domain.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscription", ctx, ID)
+	ret0, _ := ret[0].(*domain.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscription indicates an expected call of GetWebhookSubscription.
+func (mr *MockServiceMockRecorder) GetWebhookSubscription(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscription", reflect.TypeOf((*MockService)(nil).GetWebhookSubscription), ctx, ID)
+}
+
+// ListAddresses mocks base method.
+func (m *MockService) ListAddresses(ctx context.Context, userID string) ([]*domain.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAddresses", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAddresses indicates an expected call of ListAddresses.
+func (mr *MockServiceMockRecorder) ListAddresses(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAddresses", reflect.TypeOf((*MockService)(nil).ListAddresses), ctx, userID)
+}
+
+// ListInventoryMovements mocks base method.
+func (m *MockService) ListInventoryMovements(ctx context.Context, productID string, limit, offset int) ([]*domain.InventoryMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInventoryMovements", ctx, productID, limit, offset)
+	ret0, _ := ret[0].([]*domain.InventoryMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInventoryMovements indicates an expected call of ListInventoryMovements.
+func (mr *MockServiceMockRecorder) ListInventoryMovements(ctx, productID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInventoryMovements", reflect.TypeOf((*MockService)(nil).ListInventoryMovements), ctx, productID, limit, offset)
+}
+
+// ListJobRuns mocks base method.
+func (m *MockService) ListJobRuns(ctx context.Context) ([]*domain.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRuns", ctx)
+	ret0, _ := ret[0].([]*domain.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRuns indicates an expected call of ListJobRuns.
+func (mr *MockServiceMockRecorder) ListJobRuns(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRuns", reflect.TypeOf((*MockService)(nil).ListJobRuns), ctx)
+}
+
+// ListProductImages mocks base method.
+func (m *MockService) ListProductImages(ctx context.Context, productID string) ([]*domain.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductImages", ctx, productID)
+	ret0, _ := ret[0].([]*domain.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductImages indicates an expected call of ListProductImages.
+func (mr *MockServiceMockRecorder) ListProductImages(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductImages", reflect.TypeOf((*MockService)(nil).ListProductImages), ctx, productID)
+}
+
 // ListProducts mocks base method.
 func (m *MockService) ListProducts(ctx context.Context, limit, offset int) ([]*domain.Product, error) {
 	m.ctrl.T.Helper()
@@ -157,6 +536,66 @@ func (mr *MockServiceMockRecorder) ListProducts(ctx, limit, offset any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockService)(nil).ListProducts), ctx, limit, offset)
 }
 
+// ListReorderLines mocks base method.
+func (m *MockService) ListReorderLines(ctx context.Context) ([]domain.ReorderLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReorderLines", ctx)
+	ret0, _ := ret[0].([]domain.ReorderLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReorderLines indicates an expected call of ListReorderLines.
+func (mr *MockServiceMockRecorder) ListReorderLines(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReorderLines", reflect.TypeOf((*MockService)(nil).ListReorderLines), ctx)
+}
+
+// ListReturns mocks base method.
+func (m *MockService) ListReturns(ctx context.Context, orderID string) ([]*domain.Return, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReturns", ctx, orderID)
+	ret0, _ := ret[0].([]*domain.Return)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReturns indicates an expected call of ListReturns.
+func (mr *MockServiceMockRecorder) ListReturns(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReturns", reflect.TypeOf((*MockService)(nil).ListReturns), ctx, orderID)
+}
+
+// ListShipments mocks base method.
+func (m *MockService) ListShipments(ctx context.Context, orderID string) ([]*domain.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShipments", ctx, orderID)
+	ret0, _ := ret[0].([]*domain.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShipments indicates an expected call of ListShipments.
+func (mr *MockServiceMockRecorder) ListShipments(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShipments", reflect.TypeOf((*MockService)(nil).ListShipments), ctx, orderID)
+}
+
+// ListStockDiscrepancies mocks base method.
+func (m *MockService) ListStockDiscrepancies(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStockDiscrepancies", ctx)
+	ret0, _ := ret[0].([]domain.StockDiscrepancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStockDiscrepancies indicates an expected call of ListStockDiscrepancies.
+func (mr *MockServiceMockRecorder) ListStockDiscrepancies(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStockDiscrepancies", reflect.TypeOf((*MockService)(nil).ListStockDiscrepancies), ctx)
+}
+
 // ListUsers mocks base method.
 func (m *MockService) ListUsers(ctx context.Context) ([]*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -172,6 +611,182 @@ func (mr *MockServiceMockRecorder) ListUsers(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockService)(nil).ListUsers), ctx)
 }
 
+// ListWarehouses mocks base method.
+func (m *MockService) ListWarehouses(ctx context.Context) ([]*domain.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWarehouses", ctx)
+	ret0, _ := ret[0].([]*domain.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWarehouses indicates an expected call of ListWarehouses.
+func (mr *MockServiceMockRecorder) ListWarehouses(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWarehouses", reflect.TypeOf((*MockService)(nil).ListWarehouses), ctx)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockService) ListWebhookDeliveries(ctx context.Context, subscriptionID string) ([]*domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, subscriptionID)
+	ret0, _ := ret[0].([]*domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockServiceMockRecorder) ListWebhookDeliveries(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockService)(nil).ListWebhookDeliveries), ctx, subscriptionID)
+}
+
+// ListWebhookSubscriptions mocks base method.
+func (m *MockService) ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptions", ctx)
+	ret0, _ := ret[0].([]*domain.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptions indicates an expected call of ListWebhookSubscriptions.
+func (mr *MockServiceMockRecorder) ListWebhookSubscriptions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptions", reflect.TypeOf((*MockService)(nil).ListWebhookSubscriptions), ctx)
+}
+
+// OpenProductImage mocks base method.
+func (m *MockService) OpenProductImage(ctx context.Context, productID, id string, size domain.ImageSize) (*domain.ProductImage, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenProductImage", ctx, productID, id, size)
+	ret0, _ := ret[0].(*domain.ProductImage)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenProductImage indicates an expected call of OpenProductImage.
+func (mr *MockServiceMockRecorder) OpenProductImage(ctx, productID, id, size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenProductImage", reflect.TypeOf((*MockService)(nil).OpenProductImage), ctx, productID, id, size)
+}
+
+// PatchOrder mocks base method.
+func (m *MockService) PatchOrder(ctx context.Context, order *domain.Order, mask domain.FieldMask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchOrder", ctx, order, mask)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchOrder indicates an expected call of PatchOrder.
+func (mr *MockServiceMockRecorder) PatchOrder(ctx, order, mask any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchOrder", reflect.TypeOf((*MockService)(nil).PatchOrder), ctx, order, mask)
+}
+
+// PatchProduct mocks base method.
+func (m *MockService) PatchProduct(ctx context.Context, product *domain.Product, mask domain.FieldMask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchProduct", ctx, product, mask)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchProduct indicates an expected call of PatchProduct.
+func (mr *MockServiceMockRecorder) PatchProduct(ctx, product, mask any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchProduct", reflect.TypeOf((*MockService)(nil).PatchProduct), ctx, product, mask)
+}
+
+// PatchUser mocks base method.
+func (m *MockService) PatchUser(ctx context.Context, user *domain.User, mask domain.FieldMask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchUser", ctx, user, mask)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchUser indicates an expected call of PatchUser.
+func (mr *MockServiceMockRecorder) PatchUser(ctx, user, mask any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchUser", reflect.TypeOf((*MockService)(nil).PatchUser), ctx, user, mask)
+}
+
+// PayOrder mocks base method.
+func (m *MockService) PayOrder(ctx context.Context, ID string) (*domain.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayOrder", ctx, ID)
+	ret0, _ := ret[0].(*domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PayOrder indicates an expected call of PayOrder.
+func (mr *MockServiceMockRecorder) PayOrder(ctx, ID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayOrder", reflect.TypeOf((*MockService)(nil).PayOrder), ctx, ID)
+}
+
+// Ping mocks base method.
+func (m *MockService) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockServiceMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockService)(nil).Ping), ctx)
+}
+
+// PurgeExpiredIdempotencyKeys mocks base method.
+func (m *MockService) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredIdempotencyKeys", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredIdempotencyKeys indicates an expected call of PurgeExpiredIdempotencyKeys.
+func (mr *MockServiceMockRecorder) PurgeExpiredIdempotencyKeys(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredIdempotencyKeys", reflect.TypeOf((*MockService)(nil).PurgeExpiredIdempotencyKeys), ctx)
+}
+
+// RedeliverWebhook mocks base method.
+func (m *MockService) RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID string) (*domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverWebhook", ctx, subscriptionID, deliveryID)
+	ret0, _ := ret[0].(*domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverWebhook indicates an expected call of RedeliverWebhook.
+func (mr *MockServiceMockRecorder) RedeliverWebhook(ctx, subscriptionID, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhook", reflect.TypeOf((*MockService)(nil).RedeliverWebhook), ctx, subscriptionID, deliveryID)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockService) ReleaseIdempotencyKey(ctx context.Context, key, scope string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, key, scope)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockServiceMockRecorder) ReleaseIdempotencyKey(ctx, key, scope any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockService)(nil).ReleaseIdempotencyKey), ctx, key, scope)
+}
+
 // RemoveProductFromCategory mocks base method.
 func (m *MockService) RemoveProductFromCategory(ctx context.Context, categoryID, productID string) error {
 	m.ctrl.T.Helper()
@@ -186,6 +801,91 @@ func (mr *MockServiceMockRecorder) RemoveProductFromCategory(ctx, categoryID, pr
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProductFromCategory", reflect.TypeOf((*MockService)(nil).RemoveProductFromCategory), ctx, categoryID, productID)
 }
 
+// ReorderProductImages mocks base method.
+func (m *MockService) ReorderProductImages(ctx context.Context, productID string, ids []string) ([]*domain.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderProductImages", ctx, productID, ids)
+	ret0, _ := ret[0].([]*domain.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReorderProductImages indicates an expected call of ReorderProductImages.
+func (mr *MockServiceMockRecorder) ReorderProductImages(ctx, productID, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderProductImages", reflect.TypeOf((*MockService)(nil).ReorderProductImages), ctx, productID, ids)
+}
+
+// RequestEmailVerification mocks base method.
+func (m *MockService) RequestEmailVerification(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailVerification", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmailVerification indicates an expected call of RequestEmailVerification.
+func (mr *MockServiceMockRecorder) RequestEmailVerification(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailVerification", reflect.TypeOf((*MockService)(nil).RequestEmailVerification), ctx, email)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockService) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockServiceMockRecorder) RequestPasswordReset(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockService)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockService) ResetPassword(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockServiceMockRecorder) ResetPassword(ctx, token, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockService)(nil).ResetPassword), ctx, token, password)
+}
+
+// SetProductPrice mocks base method.
+func (m *MockService) SetProductPrice(ctx context.Context, productID string, price domain.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductPrice", ctx, productID, price)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProductPrice indicates an expected call of SetProductPrice.
+func (mr *MockServiceMockRecorder) SetProductPrice(ctx, productID, price any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductPrice", reflect.TypeOf((*MockService)(nil).SetProductPrice), ctx, productID, price)
+}
+
+// UpdateAddress mocks base method.
+func (m *MockService) UpdateAddress(ctx context.Context, address *domain.Address) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAddress", ctx, address)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAddress indicates an expected call of UpdateAddress.
+func (mr *MockServiceMockRecorder) UpdateAddress(ctx, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddress", reflect.TypeOf((*MockService)(nil).UpdateAddress), ctx, address)
+}
+
 // UpdateOrder mocks base method.
 func (m *MockService) UpdateOrder(ctx context.Context, order *domain.Order) error {
 	m.ctrl.T.Helper()
@@ -200,6 +900,21 @@ func (mr *MockServiceMockRecorder) UpdateOrder(ctx, order any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockService)(nil).UpdateOrder), ctx, order)
 }
 
+// UpdateReturnStatus mocks base method.
+func (m *MockService) UpdateReturnStatus(ctx context.Context, ID string, status domain.ReturnStatus, note string) (*domain.Return, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReturnStatus", ctx, ID, status, note)
+	ret0, _ := ret[0].(*domain.Return)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateReturnStatus indicates an expected call of UpdateReturnStatus.
+func (mr *MockServiceMockRecorder) UpdateReturnStatus(ctx, ID, status, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReturnStatus", reflect.TypeOf((*MockService)(nil).UpdateReturnStatus), ctx, ID, status, note)
+}
+
 // UpdateUser mocks base method.
 func (m *MockService) UpdateUser(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
@@ -213,3 +928,32 @@ func (mr *MockServiceMockRecorder) UpdateUser(ctx, user any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockService)(nil).UpdateUser), ctx, user)
 }
+
+// UploadProductImage mocks base method.
+func (m *MockService) UploadProductImage(ctx context.Context, productID string, r io.Reader) (*domain.ProductImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadProductImage", ctx, productID, r)
+	ret0, _ := ret[0].(*domain.ProductImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadProductImage indicates an expected call of UploadProductImage.
+func (mr *MockServiceMockRecorder) UploadProductImage(ctx, productID, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadProductImage", reflect.TypeOf((*MockService)(nil).UploadProductImage), ctx, productID, r)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockService) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockServiceMockRecorder) VerifyEmail(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockService)(nil).VerifyEmail), ctx, token)
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// UserLookup finds the recipient of a notification.
+type UserLookup interface {
+	GetUserByID(ctx context.Context, id string) (*domain.User, error)
+}
+
+// SentLog remembers which outbox events have been mailed.
+type SentLog interface {
+	NotificationSent(ctx context.Context, eventID string) (bool, error)
+	RecordNotification(ctx context.Context, eventID string, at time.Time) error
+}
+
+// Notifier is an events.Sink that emails customers about the events that
+// concern them. It runs in the event dispatcher, so requests never wait for
+// mail to be sent, and failed sends are retried with the event. Events are
+// also retried when another sink fails; with a SentLog set, those that were
+// already mailed are skipped.
+//
+// Account tokens are secret and never enter the outbox; the service issues
+// them when it dispatches a token request and mails them with
+// SendEmailVerification and SendPasswordReset.
+//
+// Low-stock alerts go to the staff address set with SetStockAlertRecipient.
+type Notifier struct {
+	mailer     Mailer
+	users      UserLookup
+	sent       SentLog
+	stockAlert string
+}
+
+func NewNotifier(mailer Mailer, users UserLookup) *Notifier {
+	return &Notifier{mailer: mailer, users: users}
+}
+
+// SetStockAlertRecipient sets the address low-stock alerts are mailed to; empty
+// turns them off.
+func (n *Notifier) SetStockAlertRecipient(to string) {
+	n.stockAlert = to
+}
+
+// SetSentLog sets where mailed events are recorded, so that each event is
+// mailed once.
+func (n *Notifier) SetSentLog(sent SentLog) {
+	n.sent = sent
+}
+
+func (n *Notifier) Publish(ctx context.Context, event domain.Event) error {
+	track := n.sent != nil && event.ID != ""
+	if track {
+		sent, err := n.sent.NotificationSent(ctx, event.ID)
+		if err != nil || sent {
+			return err
+		}
+	}
+
+	mailed, err := n.mail(ctx, event)
+	if err != nil || !mailed || !track {
+		return err
+	}
+	// The mail is out; failing the event now would only send it again.
+	if err := n.sent.RecordNotification(ctx, event.ID, time.Now()); err != nil {
+		slog.ErrorContext(ctx, "record sent notification", "event_id", event.ID, "error", err)
+	}
+	return nil
+}
+
+// mail sends the email event calls for, if any, and reports whether it did.
+func (n *Notifier) mail(ctx context.Context, event domain.Event) (bool, error) {
+	switch event.Type {
+	case domain.EventUserRegistered:
+		var p domain.UserRegistered
+		if err := decodePayload(event, &p); err != nil {
+			return false, err
+		}
+		return true, n.send(ctx, p.Email, "welcome", struct{ Name, Email string }{p.Name, p.Email})
+
+	case domain.EventOrderStatusChanged:
+		var p domain.OrderStatusChanged
+		if err := decodePayload(event, &p); err != nil {
+			return false, err
+		}
+		if p.To != domain.StatusDelivery {
+			return false, nil
+		}
+		user, err := n.users.GetUserByID(ctx, p.UserID)
+		if err != nil {
+			return false, err
+		}
+		return true, n.send(ctx, user.Email, "order_shipped", struct{ Name, OrderID string }{user.Name, p.OrderID})
+
+	case domain.EventStockLow:
+		if n.stockAlert == "" {
+			return false, nil
+		}
+		var p domain.StockLow
+		if err := decodePayload(event, &p); err != nil {
+			return false, err
+		}
+		return true, n.send(ctx, n.stockAlert, "stock_low", p)
+	}
+	return false, nil
+}
+
+// SendEmailVerification mails user the token that verifies their email.
+func (n *Notifier) SendEmailVerification(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	return n.send(ctx, user.Email, "verify_email", newTokenData(user, token, expiresAt))
+}
+
+// SendPasswordReset mails user the token that lets them set a new password.
+func (n *Notifier) SendPasswordReset(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	return n.send(ctx, user.Email, "password_reset", newTokenData(user, token, expiresAt))
+}
+
+type tokenData struct {
+	Name, Email, Token, Expires string
+}
+
+func newTokenData(user *domain.User, token string, expiresAt time.Time) tokenData {
+	return tokenData{
+		Name:    user.Name,
+		Email:   user.Email,
+		Token:   token,
+		Expires: expiresAt.UTC().Format("2 Jan 2006 15:04 MST"),
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, to, name string, data any) error {
+	msg, err := render(name, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{to}
+	return n.mailer.Send(ctx, msg)
+}
+
+// decodePayload reads the payload of an event taken from the outbox into dst.
+// Events raised in process still carry their typed payload.
+func decodePayload(event domain.Event, dst any) error {
+	raw, ok := event.Payload.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(event.Payload); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(raw, dst)
+}
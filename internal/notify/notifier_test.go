@@ -0,0 +1,310 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type users map[string]*domain.User
+
+func (u users) GetUserByID(_ context.Context, id string) (*domain.User, error) {
+	if user, ok := u[id]; ok {
+		return user, nil
+	}
+	return nil, domain.ErrorUserNotFound
+}
+
+// sentLog is an in-memory notify.SentLog.
+type sentLog map[string]time.Time
+
+func (l sentLog) NotificationSent(_ context.Context, eventID string) (bool, error) {
+	_, ok := l[eventID]
+	return ok, nil
+}
+
+func (l sentLog) RecordNotification(_ context.Context, eventID string, at time.Time) error {
+	l[eventID] = at
+	return nil
+}
+
+// fromOutbox returns event as the dispatcher reads it back from the outbox.
+func fromOutbox(t *testing.T, event domain.Event) domain.Event {
+	payload, err := json.Marshal(event.Payload)
+	require.NoError(t, err)
+	event.Payload = json.RawMessage(payload)
+	return event
+}
+
+func TestNotifier_Publish(t *testing.T) {
+	customer := &domain.User{ID: "u1", Name: "Arnur", Email: "arnur@example.com"}
+	order := &domain.Order{ID: "o1", UserID: "u1"}
+
+	tests := []struct {
+		name            string
+		event           domain.Event
+		expectedTo      string
+		expectedSubject string
+		expectedHTML    string
+	}{
+		{
+			name:            "welcome",
+			event:           domain.NewUserRegistered(&domain.User{ID: "u2", Name: "<Bob>", Email: "bob@example.com"}),
+			expectedTo:      "bob@example.com",
+			expectedSubject: "Welcome to the shop, <Bob>",
+			expectedHTML:    "Hi &lt;Bob&gt;,",
+		},
+		{
+			name:            "order handed over for delivery",
+			event:           domain.NewOrderStatusChanged(order, domain.StatusPaid, domain.StatusDelivery),
+			expectedTo:      "arnur@example.com",
+			expectedSubject: "Your order o1 is on its way",
+			expectedHTML:    "<strong>o1</strong>",
+		},
+		{
+			name:  "other status changes are not mailed",
+			event: domain.NewOrderStatusChanged(order, domain.StatusPending, domain.StatusPaid),
+		},
+		{
+			name:  "other events are not mailed",
+			event: domain.NewOrderCreated(order),
+		},
+		{
+			name:  "stock alerts without a recipient are not mailed",
+			event: domain.NewStockLow(domain.ReorderLine{Product: &domain.Product{ID: "p1", Amount: 1, ReorderPoint: 5}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := &notify.MemoryMailer{}
+			n := notify.NewNotifier(mailer, users{"u1": customer})
+
+			require.NoError(t, n.Publish(t.Context(), fromOutbox(t, tt.event)))
+
+			messages := mailer.Messages()
+			if tt.expectedTo == "" {
+				assert.Empty(t, messages)
+				return
+			}
+			require.Len(t, messages, 1)
+			assert.Equal(t, []string{tt.expectedTo}, messages[0].To)
+			assert.Equal(t, tt.expectedSubject, messages[0].Subject)
+			assert.Contains(t, messages[0].HTML, tt.expectedHTML)
+			assert.NotEmpty(t, messages[0].Text)
+		})
+	}
+}
+
+func TestNotifier_StockLow(t *testing.T) {
+	mailer := &notify.MemoryMailer{}
+	n := notify.NewNotifier(mailer, users{})
+	n.SetStockAlertRecipient("stock@example.com")
+	supplierID := "s1"
+	line := domain.ReorderLine{
+		Product:  &domain.Product{ID: "p1", Name: "Tea", SKU: "TEA-1", Amount: 2, ReorderPoint: 5, ReorderQuantity: 40, SupplierID: &supplierID},
+		Supplier: &domain.Supplier{ID: "s1", Name: "Green Leaf"},
+	}
+
+	require.NoError(t, n.Publish(t.Context(), fromOutbox(t, domain.NewStockLow(line))))
+
+	messages := mailer.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"stock@example.com"}, messages[0].To)
+	assert.Equal(t, "Low stock: Tea (TEA-1)", messages[0].Subject)
+	assert.Contains(t, messages[0].Text, "Green Leaf")
+	assert.Contains(t, messages[0].HTML, "<strong>40</strong>")
+}
+
+func TestNotifier_MailsEachEventOnce(t *testing.T) {
+	mailer := &notify.MemoryMailer{}
+	sent := sentLog{}
+	n := notify.NewNotifier(mailer, users{})
+	n.SetSentLog(sent)
+
+	registered := fromOutbox(t, domain.NewUserRegistered(&domain.User{ID: "u1", Name: "Arnur", Email: "arnur@example.com"}))
+	// The dispatcher retries the event when another sink fails.
+	require.NoError(t, n.Publish(t.Context(), registered))
+	require.NoError(t, n.Publish(t.Context(), registered))
+
+	assert.Len(t, mailer.Messages(), 1)
+	assert.Contains(t, sent, registered.ID)
+
+	// Events that call for no mail are not recorded.
+	placed := fromOutbox(t, domain.NewOrderStatusChanged(&domain.Order{ID: "o1", UserID: "u1"}, domain.StatusPending, domain.StatusPaid))
+	require.NoError(t, n.Publish(t.Context(), placed))
+	assert.NotContains(t, sent, placed.ID)
+}
+
+func TestNotifier_AccountTokens(t *testing.T) {
+	mailer := &notify.MemoryMailer{}
+	n := notify.NewNotifier(mailer, users{})
+	user := &domain.User{ID: "u1", Name: "Arnur", Email: "arnur@example.com"}
+	expiresAt := time.Date(2026, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	require.NoError(t, n.SendEmailVerification(t.Context(), user, "verify-token", expiresAt))
+	require.NoError(t, n.SendPasswordReset(t.Context(), user, "reset-token", expiresAt))
+
+	messages := mailer.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "Confirm your email address", messages[0].Subject)
+	assert.Contains(t, messages[0].Text, "verify-token")
+	assert.Contains(t, messages[0].HTML, "verify-token")
+	assert.Contains(t, messages[0].Text, "1 Mar 2026 12:30 UTC")
+	assert.Equal(t, "Reset your password", messages[1].Subject)
+	assert.Contains(t, messages[1].Text, "reset-token")
+	assert.Equal(t, []string{"arnur@example.com"}, messages[1].To)
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := t.TempDir()
+	mailer := notify.FileMailer{Dir: dir, From: "Shop <no-reply@shop.local>"}
+
+	err := mailer.Send(t.Context(), notify.Message{
+		To:      []string{"arnur@example.com"},
+		Subject: "Grüße",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	msg, err := mail.ReadMessage(f)
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Grüße", subject)
+	assert.Equal(t, "arnur@example.com", msg.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	var types []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		types = append(types, strings.Split(part.Header.Get("Content-Type"), ";")[0])
+	}
+	assert.Equal(t, []string{"text/plain", "text/html"}, types)
+}
+
+// fakeSMTP serves one SMTP session on a local port and sends the data of the
+// message it receives on the returned channel.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb, _, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+			case "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+				lines, err := tp.ReadDotLines()
+				if err != nil {
+					return
+				}
+				data <- strings.Join(lines, "\n")
+				_ = tp.PrintfLine("250 queued")
+			case "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				return
+			default:
+				_ = tp.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), data
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	addr, data := fakeSMTP(t)
+	mailer := notify.SMTPMailer{Addr: addr, From: "no-reply@shop.local"}
+
+	err := mailer.Send(t.Context(), notify.Message{To: []string{"arnur@example.com"}, Subject: "Hello", Text: "plain body"})
+	require.NoError(t, err)
+	assert.Contains(t, <-data, "To: arnur@example.com")
+}
+
+func TestSMTPMailer_SendTimesOut(t *testing.T) {
+	// The server accepts the connection but never greets.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			<-t.Context().Done()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	mailer := notify.SMTPMailer{Addr: ln.Addr().String(), From: "no-reply@shop.local"}
+
+	start := time.Now()
+	err = mailer.Send(ctx, notify.Message{To: []string{"arnur@example.com"}, Subject: "Hello", Text: "plain body"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded), "unexpected error: %v", err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestMailer_RejectsHeaderInjection(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  notify.Message
+	}{
+		{name: "recipient", msg: notify.Message{To: []string{"arnur@example.com\r\nBcc: evil@example.com"}, Subject: "Hello"}},
+		{name: "subject", msg: notify.Message{To: []string{"arnur@example.com"}, Subject: "Hello\nBcc: evil@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			mailer := notify.FileMailer{Dir: dir, From: "no-reply@shop.local"}
+
+			assert.Error(t, mailer.Send(t.Context(), tt.msg))
+			files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+			require.NoError(t, err)
+			assert.Empty(t, files)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Burst: 2, Period: 10 * time.Second}
+
+	res, err := store.Take(t.Context(), "a", limit)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 5 * time.Second}, res)
+
+	res, err = store.Take(t.Context(), "a", limit)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 10 * time.Second}, res)
+
+	res, err = store.Take(t.Context(), "a", limit)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: false, Limit: 2, Remaining: 0, Reset: 10 * time.Second, RetryAfter: 5 * time.Second}, res)
+
+	// Other keys have their own bucket.
+	res, err = store.Take(t.Context(), "b", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	// A token is back after one refill interval.
+	now = now.Add(5 * time.Second)
+	res, err = store.Take(t.Context(), "a", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	_, err := store.Take(t.Context(), "idle", Limit{Burst: 1, Period: time.Second})
+	require.NoError(t, err)
+	_, err = store.Take(t.Context(), "busy", Limit{Burst: 1, Period: time.Hour})
+	require.NoError(t, err)
+
+	now = now.Add(2 * sweepInterval)
+	_, err = store.Take(t.Context(), "other", Limit{Burst: 1, Period: time.Second})
+	require.NoError(t, err)
+
+	assert.NotContains(t, store.buckets, "idle")
+	assert.Contains(t, store.buckets, "busy")
+}
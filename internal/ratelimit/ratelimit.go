@@ -0,0 +1,46 @@
+// Package ratelimit implements token-bucket rate limiting with pluggable
+// bucket storage.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit describes a token bucket holding up to Burst tokens that refills at
+// Burst tokens per Period. A zero Limit disables limiting.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Enabled reports whether l limits anything.
+func (l Limit) Enabled() bool {
+	return l.Burst > 0 && l.Period > 0
+}
+
+// interval returns the time it takes to refill a single token.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Burst)
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	// Limit is the bucket capacity.
+	Limit int
+	// Remaining is the number of tokens left after this request.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until a token becomes available; zero if Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets. Implementations must be safe for concurrent
+// use; a shared implementation (e.g. Redis) makes limits hold across replicas.
+type Store interface {
+	// Take removes a token from the bucket identified by key, creating a full
+	// bucket for limit if there is none.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+const addressColumns = `id, user_id, recipient, line1, line2, city, region, postal_code, country, phone, is_default, created_at, updated_at`
+
+// CreateAddress adds an address to the user's address book. The first address
+// of a user becomes the default; a new default replaces the old one.
+func (r *repository) CreateAddress(ctx context.Context, address *domain.Address) (err error) {
+	defer observe(ctx, "CreateAddress")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if !address.IsDefault {
+		err := tx.QueryRow(ctx, `SELECT NOT EXISTS (SELECT 1 FROM addresses WHERE user_id = $1)`, address.UserID).Scan(&address.IsDefault)
+		if err != nil {
+			return err
+		}
+	}
+	if address.IsDefault {
+		if err := clearDefaultAddress(ctx, tx, address.UserID, address.ID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO addresses (`+addressColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, address.ID, address.UserID, address.Recipient, address.Line1, address.Line2, address.City, address.Region,
+		address.PostalCode, address.Country, address.Phone, address.IsDefault, address.CreatedAt, address.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *repository) GetAddress(ctx context.Context, userID, id string) (_ *domain.Address, err error) {
+	defer observe(ctx, "GetAddress")(&err)
+
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+addressColumns+`
+		FROM addresses
+		WHERE user_id = $1 AND id = $2
+	`, userID, id)
+	address, err := scanAddress(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrorAddressNotFound
+	}
+	return address, err
+}
+
+// GetDefaultAddress returns the user's default address, or
+// domain.ErrorAddressNotFound if they have none.
+func (r *repository) GetDefaultAddress(ctx context.Context, userID string) (_ *domain.Address, err error) {
+	defer observe(ctx, "GetDefaultAddress")(&err)
+
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+addressColumns+`
+		FROM addresses
+		WHERE user_id = $1 AND is_default
+	`, userID)
+	address, err := scanAddress(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrorAddressNotFound
+	}
+	return address, err
+}
+
+func (r *repository) ListAddresses(ctx context.Context, userID string) (_ []*domain.Address, err error) {
+	defer observe(ctx, "ListAddresses")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+addressColumns+`
+		FROM addresses
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []*domain.Address{}
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, rows.Err()
+}
+
+// UpdateAddress replaces an address of the user. Making it the default
+// replaces the old default; the default itself cannot be unset this way, only
+// by choosing another one.
+func (r *repository) UpdateAddress(ctx context.Context, address *domain.Address) (err error) {
+	defer observe(ctx, "UpdateAddress")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if address.IsDefault {
+		if err := clearDefaultAddress(ctx, tx, address.UserID, address.ID); err != nil {
+			return err
+		}
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE addresses
+		SET recipient = $3, line1 = $4, line2 = $5, city = $6, region = $7, postal_code = $8,
+		    country = $9, phone = $10, is_default = is_default OR $11, updated_at = $12
+		WHERE user_id = $1 AND id = $2
+		RETURNING is_default, created_at
+	`, address.UserID, address.ID, address.Recipient, address.Line1, address.Line2, address.City, address.Region,
+		address.PostalCode, address.Country, address.Phone, address.IsDefault, address.UpdatedAt,
+	).Scan(&address.IsDefault, &address.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorAddressNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteAddress removes an address of the user. If it was the default, the
+// most recently added remaining address becomes the default.
+func (r *repository) DeleteAddress(ctx context.Context, userID, id string) (err error) {
+	defer observe(ctx, "DeleteAddress")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var wasDefault bool
+	err = tx.QueryRow(ctx, `
+		DELETE FROM addresses
+		WHERE user_id = $1 AND id = $2
+		RETURNING is_default
+	`, userID, id).Scan(&wasDefault)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorAddressNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if wasDefault {
+		_, err := tx.Exec(ctx, `
+			UPDATE addresses
+			SET is_default = true
+			WHERE id = (
+				SELECT id FROM addresses WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT 1
+			)
+		`, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// clearDefaultAddress unsets the default flag of the user's addresses other
+// than keep, so that keep can become the default.
+func clearDefaultAddress(ctx context.Context, tx pgx.Tx, userID, keep string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE addresses
+		SET is_default = false
+		WHERE user_id = $1 AND id <> $2 AND is_default
+	`, userID, keep)
+	return err
+}
+
+func scanAddress(row pgx.Row) (*domain.Address, error) {
+	a := &domain.Address{}
+	err := row.Scan(&a.ID, &a.UserID, &a.Recipient, &a.Line1, &a.Line2, &a.City, &a.Region,
+		&a.PostalCode, &a.Country, &a.Phone, &a.IsDefault, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// addressSnapshot is the JSON form in which orders keep their addresses.
+type addressSnapshot struct {
+	ID         string `json:"id"`
+	Recipient  string `json:"recipient"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone,omitempty"`
+}
+
+func newAddressSnapshot(a *domain.Address) *addressSnapshot {
+	if a == nil {
+		return nil
+	}
+	return &addressSnapshot{
+		ID:         a.ID,
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+	}
+}
+
+func (s *addressSnapshot) toDomain(userID string) *domain.Address {
+	if s == nil {
+		return nil
+	}
+	return &domain.Address{
+		ID:         s.ID,
+		UserID:     userID,
+		Recipient:  s.Recipient,
+		Line1:      s.Line1,
+		Line2:      s.Line2,
+		City:       s.City,
+		Region:     s.Region,
+		PostalCode: s.PostalCode,
+		Country:    s.Country,
+		Phone:      s.Phone,
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateIdempotencyKey claims key for a new request. A key whose previous claim
+// has expired is taken over; a live one yields domain.ErrorIdempotencyKeyExists.
+func (r *repository) CreateIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (err error) {
+	defer observe(ctx, "CreateIdempotencyKey")(&err)
+
+	sqlStatement := `
+		INSERT INTO idempotency_keys (key, scope, fingerprint, completed, created_at, expires_at)
+		VALUES ($1, $2, $3, FALSE, $4, $5)
+		ON CONFLICT (key, scope) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    completed = FALSE,
+		    status_code = NULL,
+		    content_type = NULL,
+		    body = NULL,
+		    created_at = EXCLUDED.created_at,
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		RETURNING key
+	`
+	err = r.pool.QueryRow(
+		ctx,
+		sqlStatement,
+		key.Key,
+		key.Scope,
+		key.Fingerprint,
+		key.CreatedAt,
+		key.ExpiresAt,
+	).Scan(&key.Key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorIdempotencyKeyExists
+	}
+	return err
+}
+
+func (r *repository) GetIdempotencyKey(ctx context.Context, key, scope string) (_ *domain.IdempotencyKey, err error) {
+	defer observe(ctx, "GetIdempotencyKey")(&err)
+
+	sqlStatement := `
+		SELECT key, scope, fingerprint, completed, COALESCE(status_code, 0), COALESCE(content_type, ''), body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1 AND scope = $2
+	`
+	k := &domain.IdempotencyKey{}
+	err = r.pool.QueryRow(ctx, sqlStatement, key, scope).Scan(
+		&k.Key,
+		&k.Scope,
+		&k.Fingerprint,
+		&k.Completed,
+		&k.StatusCode,
+		&k.ContentType,
+		&k.Body,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrorIdempotencyKeyNotFound
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+// CompleteIdempotencyKey stores the response produced for the claimed key.
+func (r *repository) CompleteIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (err error) {
+	defer observe(ctx, "CompleteIdempotencyKey")(&err)
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET completed = TRUE, status_code = $3, content_type = $4, body = $5
+		WHERE key = $1 AND scope = $2
+	`, key.Key, key.Scope, key.StatusCode, key.ContentType, key.Body)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrorIdempotencyKeyNotFound
+	}
+	return nil
+}
+
+func (r *repository) DeleteIdempotencyKey(ctx context.Context, key, scope string) (err error) {
+	defer observe(ctx, "DeleteIdempotencyKey")(&err)
+
+	_, err = r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND scope = $2`, key, scope)
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys removes keys that expired before now and returns how many were removed.
+func (r *repository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (_ int64, err error) {
+	defer observe(ctx, "DeleteExpiredIdempotencyKeys")(&err)
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
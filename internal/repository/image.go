@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+const imageColumns = `id, product_id, position, content_type, width, height, size, created_at`
+
+func scanImage(row pgx.CollectableRow) (*domain.ProductImage, error) {
+	var img domain.ProductImage
+	err := row.Scan(&img.ID, &img.ProductID, &img.Position, &img.ContentType, &img.Width, &img.Height, &img.Size, &img.CreatedAt)
+	return &img, err
+}
+
+// CreateProductImage adds img to the end of the gallery of its product and sets
+// img.Position.
+func (r *repository) CreateProductImage(ctx context.Context, img *domain.ProductImage) (err error) {
+	defer observe(ctx, "CreateProductImage")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockProduct(ctx, tx, img.ProductID); err != nil {
+		return err
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO product_images (id, product_id, position, content_type, width, height, size, created_at)
+		SELECT $1, $2, COALESCE(MAX(position) + 1, 0), $3, $4, $5, $6, $7
+		FROM product_images
+		WHERE product_id = $2
+		RETURNING position
+	`, img.ID, img.ProductID, img.ContentType, img.Width, img.Height, img.Size, img.CreatedAt).Scan(&img.Position)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *repository) GetProductImage(ctx context.Context, productID, id string) (_ *domain.ProductImage, err error) {
+	defer observe(ctx, "GetProductImage")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+imageColumns+`
+		FROM product_images
+		WHERE product_id = $1 AND id = $2
+	`, productID, id)
+	if err != nil {
+		return nil, err
+	}
+	img, err := pgx.CollectExactlyOneRow(rows, scanImage)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrorImageNotFound
+	}
+	return img, err
+}
+
+// ListProductImages returns the gallery of a product in display order.
+func (r *repository) ListProductImages(ctx context.Context, productID string) (_ []*domain.ProductImage, err error) {
+	defer observe(ctx, "ListProductImages")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+imageColumns+`
+		FROM product_images
+		WHERE product_id = $1
+		ORDER BY position, id
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanImage)
+}
+
+// ReorderProductImages moves the images of a product to the positions of
+// their IDs in ids, which must list every image of the gallery once.
+func (r *repository) ReorderProductImages(ctx context.Context, productID string, ids []string) (err error) {
+	defer observe(ctx, "ReorderProductImages")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+	rows, err := tx.Query(ctx, `SELECT id FROM product_images WHERE product_id = $1`, productID)
+	if err != nil {
+		return err
+	}
+	current, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+	slices.Sort(current)
+	sorted := slices.Sorted(slices.Values(ids))
+	if !slices.Equal(current, sorted) {
+		return fmt.Errorf("%w: the new order must list every image of the product once", domain.ErrorInvalidImage)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE product_images i
+		SET position = o.position - 1
+		FROM unnest($2::text[]) WITH ORDINALITY AS o (id, position)
+		WHERE i.product_id = $1 AND i.id = o.id
+	`, productID, ids)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *repository) DeleteProductImage(ctx context.Context, productID, id string) (err error) {
+	defer observe(ctx, "DeleteProductImage")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var position int
+	err = tx.QueryRow(ctx, `
+		DELETE FROM product_images WHERE product_id = $1 AND id = $2
+		RETURNING position
+	`, productID, id).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorImageNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// Close the gap so that positions stay 0, 1, 2, ...
+	if _, err := tx.Exec(ctx, `
+		UPDATE product_images SET position = position - 1
+		WHERE product_id = $1 AND position > $2
+	`, productID, position); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// loadImages sets the galleries of products.
+func (r *repository) loadImages(ctx context.Context, products []*domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(products))
+	byID := make(map[string]*domain.Product, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+		byID[p.ID] = p
+		p.Images = nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+imageColumns+`
+		FROM product_images
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, position, id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	images, err := pgx.CollectRows(rows, scanImage)
+	if err != nil {
+		return err
+	}
+	for _, img := range images {
+		byID[img.ProductID].Images = append(byID[img.ProductID].Images, *img)
+	}
+	return nil
+}
+
+// lockProduct locks the row of a product until tx ends, so that changes to its
+// gallery are serialized.
+func lockProduct(ctx context.Context, tx pgx.Tx, productID string) error {
+	var id string
+	err := tx.QueryRow(ctx, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, productID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorProductNotFound
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/aibekfatkhulla/shop/internal/repository")
+
+// expectedErrors are domain outcomes the repository reports for missing rows,
+// conflicts and business rules. They are answers, not database failures, so
+// observe does not count, log or mark them as errors.
+var expectedErrors = []error{
+	domain.ErrorUserNotFound,
+	domain.ErrorProductNotFound,
+	domain.ErrorOrderNotFound,
+	domain.ErrorCategoryNotFound,
+	domain.ErrorSupplierNotFound,
+	domain.ErrorWebhookNotFound,
+	domain.ErrorDeliveryNotFound,
+	domain.ErrorAddressNotFound,
+	domain.ErrorShipmentNotFound,
+	domain.ErrorReturnNotFound,
+	domain.ErrorWarehouseNotFound,
+	domain.ErrorVariantNotFound,
+	domain.ErrorImageNotFound,
+	domain.ErrorPriceNotFound,
+	domain.ErrorUserAlreadyExists,
+	domain.ErrorVersionConflict,
+	domain.ErrorVariantExists,
+	domain.ErrorIdempotencyKeyExists,
+	domain.ErrorIdempotencyKeyNotFound,
+	domain.ErrorInsufficientStock,
+	domain.ErrorInsufficientBalance,
+	domain.ErrorOrderNotPending,
+	domain.ErrorOrderTransition,
+	domain.ErrorReturnTransition,
+	domain.ErrorCurrencyMismatch,
+	domain.ErrorPriceUnavailable,
+	domain.ErrorInvalidToken,
+	domain.ErrorInvalidPatch,
+	domain.ErrorInvalidImage,
+	domain.ErrorInvalidVariant,
+}
+
+// isExpected reports whether err is one of expectedErrors.
+func isExpected(err error) bool {
+	for _, target := range expectedErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// observe starts timing and tracing the repository call op. The returned function
+// must be deferred with a pointer to the call's error to record its duration and
+// outcome; failures are logged with the operation name. Expected domain errors
+// are not counted as failures.
+func observe(ctx context.Context, op string) func(err *error) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "Repository."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("db.operation.name", op),
+		),
+	)
+	return func(err *error) {
+		defer span.End()
+
+		metrics.DBQueryDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+		if *err != nil && !isExpected(*err) {
+			metrics.DBQueryErrors.WithLabelValues(op).Inc()
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+			slog.ErrorContext(ctx, "repository call failed", "operation", op, "error", *err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// insertMovement records m in the inventory ledger as part of tx and sets its ID.
+func insertMovement(ctx context.Context, tx pgx.Tx, m *domain.InventoryMovement) error {
+	return tx.QueryRow(ctx, `
+		INSERT INTO inventory_movements (product_id, variant_id, warehouse_id, type, quantity, reason, actor, reference, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+		RETURNING id
+	`, m.ProductID, m.VariantID, m.WarehouseID, m.Type, m.Quantity, m.Reason, m.Actor, m.Reference, m.CreatedAt).Scan(&m.ID)
+}
+
+// AdjustStock changes the amount of m.ProductID in m.WarehouseID, and of
+// m.VariantID if set, by m.Quantity and records the movement in a single
+// transaction. It fails with domain.ErrorInsufficientStock if the amount would
+// become negative.
+func (r *repository) AdjustStock(ctx context.Context, m *domain.InventoryMovement, events ...domain.Event) (err error) {
+	defer observe(ctx, "AdjustStock")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE products
+		SET amount = amount + $2, version = version + 1
+		WHERE id = $1 AND amount + $2 >= 0
+	`, m.ProductID, m.Quantity)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)`, m.ProductID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrorProductNotFound
+		}
+		return domain.ErrorInsufficientStock
+	}
+	if err := changeWarehouseStock(ctx, tx, m.WarehouseID, domain.OrderLine{ProductID: m.ProductID, VariantID: m.VariantID}, m.Quantity); err != nil {
+		return err
+	}
+	if m.VariantID != "" {
+		if err := changeVariantStock(ctx, tx, m.ProductID, m.VariantID, m.Quantity); err != nil {
+			return err
+		}
+	}
+
+	if err := insertMovement(ctx, tx, m); err != nil {
+		return err
+	}
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListInventoryMovements returns the ledger of a product, newest first.
+func (r *repository) ListInventoryMovements(ctx context.Context, productID string, limit, offset int) (_ []*domain.InventoryMovement, err error) {
+	defer observe(ctx, "ListInventoryMovements")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, product_id, COALESCE(variant_id, ''), COALESCE(warehouse_id, ''), type, quantity, reason, actor, COALESCE(reference, ''), created_at
+		FROM inventory_movements
+		WHERE product_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3
+	`, productID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.InventoryMovement, error) {
+		var m domain.InventoryMovement
+		err := row.Scan(&m.ID, &m.ProductID, &m.VariantID, &m.WarehouseID, &m.Type, &m.Quantity, &m.Reason, &m.Actor, &m.Reference, &m.CreatedAt)
+		return &m, err
+	})
+}
+
+// ListStockDiscrepancies returns the products whose amount differs from the
+// sum of their inventory movements or of their warehouse stock.
+func (r *repository) ListStockDiscrepancies(ctx context.Context) (_ []domain.StockDiscrepancy, err error) {
+	defer observe(ctx, "ListStockDiscrepancies")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT p.id, p.amount, COALESCE(m.total, 0), COALESCE(s.total, 0)
+		FROM products p
+		LEFT JOIN (
+			SELECT product_id, SUM(quantity) AS total FROM inventory_movements GROUP BY product_id
+		) m ON m.product_id = p.id
+		LEFT JOIN (
+			SELECT product_id, SUM(amount) AS total FROM warehouse_stock GROUP BY product_id
+		) s ON s.product_id = p.id
+		WHERE p.amount <> COALESCE(m.total, 0) OR p.amount <> COALESCE(s.total, 0)
+		ORDER BY p.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.StockDiscrepancy, error) {
+		var d domain.StockDiscrepancy
+		err := row.Scan(&d.ProductID, &d.Amount, &d.LedgerSum, &d.WarehouseSum)
+		return d, err
+	})
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// CancelStaleOrders cancels up to limit pending orders created before
+// createdBefore, oldest first, and puts their items back into stock. Orders
+// locked by another replica are skipped, so concurrent runs never cancel the
+// same order twice.
+func (r *repository) CancelStaleOrders(ctx context.Context, createdBefore time.Time, limit int, at time.Time) (_ []*domain.Order, err error) {
+	defer observe(ctx, "CancelStaleOrders")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE orders
+		SET status = $4, updated_at = $5, version = version + 1
+		WHERE id IN (
+			SELECT id FROM orders
+			WHERE status = $3 AND created_at < $1
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, created_at, updated_at, status, version
+	`, createdBefore, limit, domain.StatusPending, domain.StatusCanceled, at)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Order, error) {
+		var o domain.Order
+		err := row.Scan(&o.ID, &o.UserID, &o.CreatedAt, &o.UpdatedAt, &o.Status, &o.Version)
+		return &o, err
+	})
+	if err != nil || len(orders) == 0 {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(orders))
+	events := make([]domain.Event, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+		events = append(events, domain.NewOrderStatusChanged(order, domain.StatusPending, domain.StatusCanceled))
+	}
+
+	if err := releaseOrders(ctx, tx, ids, "pending order expired", domain.ActorSystem, at); err != nil {
+		return nil, err
+	}
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// CancelOrder cancels the pending order and puts its items back into stock the
+// way CancelStaleOrders does for expired ones. It fails with
+// domain.ErrorVersionConflict if the order changed since order.Version was read
+// and with domain.ErrorOrderNotPending if it was paid or canceled meanwhile.
+func (r *repository) CancelOrder(ctx context.Context, order *domain.Order, events ...domain.Event) (err error) {
+	defer observe(ctx, "CancelOrder")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status domain.Status
+	var version int
+	err = tx.QueryRow(ctx, `
+		SELECT status, version FROM orders WHERE id = $1 FOR UPDATE
+	`, order.ID).Scan(&status, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if version != order.Version {
+		return domain.ErrorVersionConflict
+	}
+	if status != domain.StatusPending {
+		return domain.ErrorOrderNotPending
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE orders
+		SET status = $2, updated_at = $3, version = version + 1
+		WHERE id = $1
+		RETURNING version
+	`, order.ID, domain.StatusCanceled, order.UpdatedAt).Scan(&version)
+	if err != nil {
+		return err
+	}
+	if err := releaseOrders(ctx, tx, []string{order.ID}, "order canceled", order.UserID, order.UpdatedAt); err != nil {
+		return err
+	}
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	order.Status = domain.StatusCanceled
+	order.Version = version
+	return nil
+}
+
+// releaseOrders puts the items of the canceled orders ids back into the
+// product, variant and warehouse stock they were reserved from and records the
+// restock in the inventory ledger.
+func releaseOrders(ctx context.Context, tx pgx.Tx, ids []string, reason, actor string, at time.Time) error {
+	// Several canceled orders may hold the same product, so the quantities
+	// are summed before they are released.
+	_, err := tx.Exec(ctx, `
+		UPDATE products p
+		SET amount = p.amount + released.quantity, version = p.version + 1
+		FROM (
+			SELECT product_id, SUM(quantity) AS quantity
+			FROM order_items
+			WHERE order_id = ANY($1)
+			GROUP BY product_id
+		) released
+		WHERE p.id = released.product_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE product_variants v
+		SET amount = v.amount + released.quantity
+		FROM (
+			SELECT variant_id, SUM(quantity) AS quantity
+			FROM order_items
+			WHERE order_id = ANY($1) AND variant_id <> ''
+			GROUP BY variant_id
+		) released
+		WHERE v.id = released.variant_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE warehouse_stock s
+		SET amount = s.amount + released.quantity
+		FROM (
+			SELECT warehouse_id, product_id, variant_id, SUM(quantity) AS quantity
+			FROM order_item_allocations
+			WHERE order_id = ANY($1)
+			GROUP BY warehouse_id, product_id, variant_id
+		) released
+		WHERE s.warehouse_id = released.warehouse_id AND s.product_id = released.product_id
+			AND s.variant_id = released.variant_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO inventory_movements (product_id, variant_id, warehouse_id, type, quantity, reason, actor, reference, created_at)
+		SELECT product_id, NULLIF(variant_id, ''), warehouse_id, $2, quantity, $3, $4, order_id, $5
+		FROM order_item_allocations
+		WHERE order_id = ANY($1)
+	`, ids, domain.MovementRestock, reason, actor, at)
+	return err
+}
+
+// SaveJobRun stores run as the last run of its job.
+func (r *repository) SaveJobRun(ctx context.Context, run *domain.JobRun) (err error) {
+	defer observe(ctx, "SaveJobRun")(&err)
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO job_runs (name, started_at, finished_at, processed, error)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (name) DO UPDATE
+		SET started_at = EXCLUDED.started_at, finished_at = EXCLUDED.finished_at,
+			processed = EXCLUDED.processed, error = EXCLUDED.error
+	`, run.Name, run.StartedAt, run.FinishedAt, run.Processed, strings.ToValidUTF8(run.Error, "?"))
+	return err
+}
+
+func (r *repository) ListJobRuns(ctx context.Context) (_ []*domain.JobRun, err error) {
+	defer observe(ctx, "ListJobRuns")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT name, started_at, finished_at, processed, COALESCE(error, '')
+		FROM job_runs
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.JobRun, error) {
+		var run domain.JobRun
+		err := row.Scan(&run.Name, &run.StartedAt, &run.FinishedAt, &run.Processed, &run.Error)
+		return &run, err
+	})
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// NotificationSent reports whether the notifier has mailed the event eventID.
+func (r *repository) NotificationSent(ctx context.Context, eventID string) (_ bool, err error) {
+	defer observe(ctx, "NotificationSent")(&err)
+
+	var sent bool
+	err = r.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sent_notifications WHERE event_id = $1)
+	`, eventID).Scan(&sent)
+	return sent, err
+}
+
+// RecordNotification remembers that the event eventID has been mailed.
+func (r *repository) RecordNotification(ctx context.Context, eventID string, at time.Time) (err error) {
+	defer observe(ctx, "RecordNotification")(&err)
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO sent_notifications (event_id, sent_at)
+		VALUES ($1, $2)
+		ON CONFLICT (event_id) DO NOTHING
+	`, eventID, at)
+	return err
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// insertEvents writes events to the outbox as part of tx.
+func insertEvents(ctx context.Context, tx pgx.Tx, events []domain.Event) error {
+	for _, event := range events {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO outbox_events (id, type, aggregate_id, payload, created_at, next_attempt_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+		`, event.ID, event.Type, event.AggregateID, payload, event.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddEvents writes events that record no change of their own to the outbox.
+func (r *repository) AddEvents(ctx context.Context, events ...domain.Event) (err error) {
+	defer observe(ctx, "AddEvents")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ClaimEvents returns up to limit undelivered events that are due at now and
+// have been tried fewer than maxAttempts times, oldest first. Claimed events
+// count one more attempt and are hidden from other dispatchers until leaseUntil.
+func (r *repository) ClaimEvents(ctx context.Context, limit, maxAttempts int, now, leaseUntil time.Time) (_ []domain.Event, err error) {
+	defer observe(ctx, "ClaimEvents")(&err)
+
+	sqlStatement := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, next_attempt_at = $4
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE dispatched_at IS NULL AND next_attempt_at <= $3 AND attempts < $2
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, aggregate_id, payload, created_at, attempts
+	`
+	rows, err := r.pool.Query(ctx, sqlStatement, limit, maxAttempts, now, leaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var (
+			e       domain.Event
+			payload []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.AggregateID, &payload, &e.CreatedAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		e.Payload = json.RawMessage(payload)
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery.
+	slices.SortFunc(events, func(a, b domain.Event) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return events, nil
+}
+
+func (r *repository) MarkEventDispatched(ctx context.Context, id string, at time.Time) (err error) {
+	defer observe(ctx, "MarkEventDispatched")(&err)
+
+	_, err = r.pool.Exec(ctx, `
+		UPDATE outbox_events
+		SET dispatched_at = $2, last_error = NULL
+		WHERE id = $1
+	`, id, at)
+	return err
+}
+
+// FailEvent records a failed delivery and schedules the next attempt.
+func (r *repository) FailEvent(ctx context.Context, id string, cause error, retryAt time.Time) (err error) {
+	defer observe(ctx, "FailEvent")(&err)
+
+	_, err = r.pool.Exec(ctx, `
+		UPDATE outbox_events
+		SET last_error = $2, next_attempt_at = $3
+		WHERE id = $1
+	`, id, strings.ToValidUTF8(cause.Error(), "?"), retryAt)
+	return err
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// querier is implemented by both the pool and transactions.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// assignment is a single "column = value" of an UPDATE statement.
+type assignment struct {
+	column string
+	value  any
+}
+
+func (r *repository) PatchUser(ctx context.Context, user *domain.User, mask domain.FieldMask) (err error) {
+	defer observe(ctx, "PatchUser")(&err)
+
+	set := []assignment{{"updated_at", user.UpdatedAt}}
+	for _, field := range mask {
+		switch field {
+		case "name":
+			set = append(set, assignment{"name", user.Name})
+		case "email":
+			set = append(set, assignment{"email", user.Email}, assignment{"email_verified", user.EmailVerified})
+		case "password":
+			set = append(set, assignment{"password", user.Password})
+		case "number":
+			set = append(set, assignment{"number", user.Number})
+		case "address":
+			set = append(set, assignment{"address", user.Address})
+		default:
+			return fmt.Errorf("%w: users.%s is not patchable", domain.ErrorInvalidPatch, field)
+		}
+	}
+
+	err = updateColumns(ctx, r.pool, "users", user.ID, set, &user.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.versionMismatch(ctx, "users", user.ID, domain.ErrorUserNotFound)
+	}
+	return err
+}
+
+func (r *repository) PatchProduct(ctx context.Context, product *domain.Product, mask domain.FieldMask) (err error) {
+	defer observe(ctx, "PatchProduct")(&err)
+
+	set := make([]assignment, 0, len(mask))
+	for _, field := range mask {
+		switch field {
+		case "name":
+			set = append(set, assignment{"name", product.Name})
+		case "price":
+			set = append(set, assignment{"price", product.Price.Amount})
+		case "sku":
+			set = append(set, assignment{"sku", product.SKU})
+		case "reorder_point":
+			set = append(set, assignment{"reorder_point", product.ReorderPoint})
+		case "reorder_quantity":
+			set = append(set, assignment{"reorder_quantity", product.ReorderQuantity})
+		case "supplier_id":
+			set = append(set, assignment{"supplier_id", product.SupplierID})
+		default:
+			return fmt.Errorf("%w: products.%s is not patchable", domain.ErrorInvalidPatch, field)
+		}
+	}
+
+	err = updateColumns(ctx, r.pool, "products", product.ID, set, &product.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.versionMismatch(ctx, "products", product.ID, domain.ErrorProductNotFound)
+	}
+	return err
+}
+
+func (r *repository) PatchOrder(ctx context.Context, order *domain.Order, mask domain.FieldMask, events ...domain.Event) (err error) {
+	defer observe(ctx, "PatchOrder")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	set := []assignment{{"updated_at", order.UpdatedAt}}
+	for _, field := range mask {
+		switch field {
+		case "status":
+			set = append(set, assignment{"status", order.Status})
+		default:
+			return fmt.Errorf("%w: orders.%s is not patchable", domain.ErrorInvalidPatch, field)
+		}
+	}
+
+	err = updateColumns(ctx, tx, "orders", order.ID, set, &order.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.versionMismatch(ctx, "orders", order.ID, domain.ErrorOrderNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// updateColumns writes only the given columns of the row id in table, provided
+// the row is still at *version, and stores the bumped version in *version. It
+// returns pgx.ErrNoRows when no row matched.
+func updateColumns(ctx context.Context, q querier, table, id string, set []assignment, version *int) error {
+	args := []any{id, *version}
+	clauses := make([]string, 0, len(set)+1)
+	for _, a := range set {
+		args = append(args, a.value)
+		clauses = append(clauses, fmt.Sprintf("%s = $%d", a.column, len(args)))
+	}
+	clauses = append(clauses, "version = version + 1")
+
+	sqlStatement := `UPDATE ` + table + `
+		SET ` + strings.Join(clauses, ", ") + `
+		WHERE id = $1 AND version = $2
+		RETURNING version`
+
+	return q.QueryRow(ctx, sqlStatement, args...).Scan(version)
+}
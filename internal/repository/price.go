@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// SetProductPrice adds price to the price list of a product, replacing what
+// the product cost in that currency before.
+func (r *repository) SetProductPrice(ctx context.Context, productID string, price domain.Money) (err error) {
+	defer observe(ctx, "SetProductPrice")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO product_prices (product_id, currency, amount)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (product_id, currency) DO UPDATE SET amount = EXCLUDED.amount
+	`, productID, price.Currency, price.Amount)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteProductPrice removes the price in currency from the price list of a
+// product.
+func (r *repository) DeleteProductPrice(ctx context.Context, productID string, currency domain.Currency) (err error) {
+	defer observe(ctx, "DeleteProductPrice")(&err)
+
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM product_prices WHERE product_id = $1 AND currency = $2
+	`, productID, currency)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrorPriceNotFound
+	}
+	return nil
+}
+
+// loadPrices sets the price lists of products.
+func (r *repository) loadPrices(ctx context.Context, products []*domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(products))
+	byID := make(map[string]*domain.Product, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+		byID[p.ID] = p
+		p.Prices = nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT product_id, currency, amount
+		FROM product_prices
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, currency
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			productID string
+			price     domain.Money
+		)
+		if err := rows.Scan(&productID, &price.Currency, &price.Amount); err != nil {
+			return err
+		}
+		byID[productID].Prices = append(byID[productID].Prices, price)
+	}
+	return rows.Err()
+}
+
+// moneyAmount returns the amount of an optional price, for nullable columns.
+func moneyAmount(m *domain.Money) *int64 {
+	if m == nil {
+		return nil
+	}
+	return &m.Amount
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// reorderColumns are scanned by scanReorderLine; the products table is p and
+// the suppliers table s.
+const reorderColumns = `p.id, p.name, p.price, p.currency, p.sku, p.amount, p.category_id,
+	p.reorder_point, p.reorder_quantity, p.supplier_id, p.version, s.id, s.name`
+
+func scanReorderLine(row pgx.CollectableRow) (domain.ReorderLine, error) {
+	var (
+		p                        domain.Product
+		supplierID, supplierName *string
+	)
+	err := row.Scan(&p.ID, &p.Name, &p.Price.Amount, &p.Price.Currency, &p.SKU, &p.Amount, &p.CategoryID,
+		&p.ReorderPoint, &p.ReorderQuantity, &p.SupplierID, &p.Version, &supplierID, &supplierName)
+	line := domain.ReorderLine{Product: &p}
+	if supplierID != nil {
+		line.Supplier = &domain.Supplier{ID: *supplierID, Name: *supplierName}
+	}
+	return line, err
+}
+
+// ListReorderLines returns the products at or below their reorder point with
+// their suppliers, grouped by supplier.
+func (r *repository) ListReorderLines(ctx context.Context) (_ []domain.ReorderLine, err error) {
+	defer observe(ctx, "ListReorderLines")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+reorderColumns+`
+		FROM products p
+		LEFT JOIN suppliers s ON s.id = p.supplier_id
+		WHERE p.amount <= p.reorder_point
+		ORDER BY s.name NULLS LAST, p.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scanReorderLine)
+}
+
+// ClaimLowStock raises a StockLow event for up to limit products that reached
+// their reorder point since the last check, and marks them alerted. Products
+// restocked above their reorder point are rearmed, so every shortage is
+// reported once. Rows locked by another replica are skipped.
+func (r *repository) ClaimLowStock(ctx context.Context, limit int, at time.Time) (_ []domain.ReorderLine, err error) {
+	defer observe(ctx, "ClaimLowStock")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE products
+		SET low_stock_alerted_at = NULL
+		WHERE low_stock_alerted_at IS NOT NULL AND amount > reorder_point
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		WITH claimed AS (
+			UPDATE products
+			SET low_stock_alerted_at = $2
+			WHERE id IN (
+				SELECT id FROM products
+				WHERE low_stock_alerted_at IS NULL AND amount <= reorder_point
+				ORDER BY id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+reorderColumns+`
+		FROM claimed p
+		LEFT JOIN suppliers s ON s.id = p.supplier_id
+		ORDER BY p.id
+	`, limit, at)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := pgx.CollectRows(rows, scanReorderLine)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.Event, 0, len(lines))
+	for _, line := range lines {
+		events = append(events, domain.NewStockLow(line))
+	}
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
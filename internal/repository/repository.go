@@ -3,13 +3,15 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/aibekfatkhulla/shop/internal/domain"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type repository struct {
-	conn *pgx.Conn
+	pool *pgxpool.Pool
 }
 
 func (r *repository) NewService(ctx context.Context, user *domain.User) error {
@@ -18,17 +20,31 @@ func (r *repository) NewService(ctx context.Context, user *domain.User) error {
 	panic("implement me")
 }
 
-func NewRepository(conn *pgx.Conn) *repository {
-	return &repository{conn: conn}
+func NewRepository(pool *pgxpool.Pool) *repository {
+	return &repository{pool: pool}
 }
 
-func (r *repository) CreateUser(ctx context.Context, user *domain.User) error {
+func (r *repository) Ping(ctx context.Context) (err error) {
+	defer observe(ctx, "Ping")(&err)
+
+	return r.pool.Ping(ctx)
+}
+
+func (r *repository) CreateUser(ctx context.Context, user *domain.User, events ...domain.Event) (err error) {
+	defer observe(ctx, "CreateUser")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	sqlStatement := `
-		INSERT INTO users (id, name, password, email, number, address, balance, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8,$9)
-		RETURNING id
+		INSERT INTO users (id, name, password, email, number, address, balance, balance_currency, email_verified, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 1)
+		RETURNING id, version
 `
-	err := r.conn.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		sqlStatement,
 		user.ID,
@@ -37,23 +53,33 @@ func (r *repository) CreateUser(ctx context.Context, user *domain.User) error {
 		user.Email,
 		user.Number,
 		user.Address,
-		user.Balance,
+		user.Balance.Amount,
+		user.Balance.Currency,
+		user.EmailVerified,
 		user.CreatedAt,
 		user.UpdatedAt,
-	).Scan(&user.ID)
+	).Scan(&user.ID, &user.Version)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *repository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+func (r *repository) GetByEmail(ctx context.Context, email string) (_ *domain.User, err error) {
+	defer observe(ctx, "GetByEmail")(&err)
+
 	sqlStatement :=
-		`SELECT id, name, password, email, number, address, balance, created_at, updated_at
+		`SELECT id, name, password, email, number, address, balance, balance_currency, email_verified, created_at, updated_at, version
 		FROM users
 		WHERE email = $1;
 	`
 
 	var user domain.User
-	err := r.conn.QueryRow(
+	err = r.pool.QueryRow(
 		ctx,
 		sqlStatement,
 		email,
@@ -64,9 +90,12 @@ func (r *repository) GetByEmail(ctx context.Context, email string) (*domain.User
 		&user.Email,
 		&user.Number,
 		&user.Address,
-		&user.Balance,
+		&user.Balance.Amount,
+		&user.Balance.Currency,
+		&user.EmailVerified,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -76,20 +105,24 @@ func (r *repository) GetByEmail(ctx context.Context, email string) (*domain.User
 	return &user, err
 }
 
-func (r *repository) UpdateUser(ctx context.Context, user *domain.User) error {
+func (r *repository) UpdateUser(ctx context.Context, user *domain.User) (err error) {
+	defer observe(ctx, "UpdateUser")(&err)
+
 	sqlStatement :=
 		`UPDATE users
 		SET name = $2,
 		    password = $3,
 		    email = $4,
-		    number =$5,
+		    number = $5,
 		    address = $6,
 		    updated_at = $7,
-		WHERE id = $1
-		RETURNING id
+		    email_verified = email_verified AND email = $4,
+		    version = version + 1
+		WHERE id = $1 AND version = $8
+		RETURNING version, email_verified
 		`
 
-	err := r.conn.QueryRow(
+	err = r.pool.QueryRow(
 		ctx,
 		sqlStatement,
 		user.ID,
@@ -99,28 +132,37 @@ func (r *repository) UpdateUser(ctx context.Context, user *domain.User) error {
 		user.Number,
 		user.Address,
 		user.UpdatedAt,
-	).Scan(&user.ID)
+		user.Version,
+	).Scan(&user.Version, &user.EmailVerified)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.versionMismatch(ctx, "users", user.ID, domain.ErrorUserNotFound)
+	}
 	return err
 }
 
-func (r *repository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+func (r *repository) GetUserByID(ctx context.Context, id string) (_ *domain.User, err error) {
+	defer observe(ctx, "GetUserByID")(&err)
+
 	query := `
-		SELECT id, name, password, email, number, address, balance, created_at, updated_at
+		SELECT id, name, password, email, number, address, balance, balance_currency, email_verified, created_at, updated_at, version
 		FROM users
 		WHERE id = $1
 		`
 
 	user := &domain.User{}
-	err := r.conn.QueryRow(ctx, query, id).Scan(
+	err = r.pool.QueryRow(ctx, query, id).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Password,
 		&user.Email,
 		&user.Number,
 		&user.Address,
-		&user.Balance,
+		&user.Balance.Amount,
+		&user.Balance.Currency,
+		&user.EmailVerified,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -131,9 +173,11 @@ func (r *repository) GetUserByID(ctx context.Context, id string) (*domain.User,
 	return user, nil
 }
 
-func (r *repository) ListUsers(ctx context.Context) ([]*domain.User, error) {
-	rows, err := r.conn.Query(ctx, `
-		SELECT id, name, email, password, number, address, balance, created_at, updated_at 
+func (r *repository) ListUsers(ctx context.Context) (_ []*domain.User, err error) {
+	defer observe(ctx, "ListUsers")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, email, password, number, address, balance, balance_currency, email_verified, created_at, updated_at, version
 		FROM users
 		`)
 	if err != nil {
@@ -144,7 +188,7 @@ func (r *repository) ListUsers(ctx context.Context) ([]*domain.User, error) {
 	var users []*domain.User
 	for rows.Next() {
 		u := &domain.User{}
-		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Number, &u.Address, &u.Balance, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Number, &u.Address, &u.Balance.Amount, &u.Balance.Currency, &u.EmailVerified, &u.CreatedAt, &u.UpdatedAt, &u.Version); err != nil {
 			return nil, err
 		}
 		users = append(users, u)
@@ -153,20 +197,28 @@ func (r *repository) ListUsers(ctx context.Context) ([]*domain.User, error) {
 	return users, rows.Err()
 }
 
-func (r *repository) GetProductByID(ctx context.Context, id string) (*domain.Product, error) {
+func (r *repository) GetProductByID(ctx context.Context, id string) (_ *domain.Product, err error) {
+	defer observe(ctx, "GetProductByID")(&err)
+
 	sqlStatement :=
-		`SELECT id, name, price, sku, amount
+		`SELECT id, name, price, currency, sku, amount, category_id, reorder_point, reorder_quantity, supplier_id, version
 		FROM products
 		WHERE id = $1
 `
 	product := &domain.Product{}
 
-	err := r.conn.QueryRow(ctx, sqlStatement, id).Scan(
+	err = r.pool.QueryRow(ctx, sqlStatement, id).Scan(
 		&product.ID,
 		&product.Name,
-		&product.Price,
+		&product.Price.Amount,
+		&product.Price.Currency,
 		&product.SKU,
 		&product.Amount,
+		&product.CategoryID,
+		&product.ReorderPoint,
+		&product.ReorderQuantity,
+		&product.SupplierID,
+		&product.Version,
 	)
 
 	if err != nil {
@@ -175,18 +227,32 @@ func (r *repository) GetProductByID(ctx context.Context, id string) (*domain.Pro
 		}
 		return nil, err
 	}
+	if err := r.loadWarehouseStock(ctx, []*domain.Product{product}); err != nil {
+		return nil, err
+	}
+	if err := r.loadPrices(ctx, []*domain.Product{product}); err != nil {
+		return nil, err
+	}
+	if err := r.loadVariants(ctx, []*domain.Product{product}); err != nil {
+		return nil, err
+	}
+	if err := r.loadImages(ctx, []*domain.Product{product}); err != nil {
+		return nil, err
+	}
 	return product, nil
 }
 
-func (r *repository) ListProducts(ctx context.Context, limit, offset int) ([]*domain.Product, error) {
+func (r *repository) ListProducts(ctx context.Context, limit, offset int) (_ []*domain.Product, err error) {
+	defer observe(ctx, "ListProducts")(&err)
+
 	sqlStatement := `
-		SELECT id, name, price, sku, amount
+		SELECT id, name, price, currency, sku, amount, category_id, reorder_point, reorder_quantity, supplier_id, version
 		FROM products
 		ORDER BY id ASC
 		LIMIT $1 OFFSET $2;
 	`
 
-	rows, err := r.conn.Query(ctx, sqlStatement, limit, offset)
+	rows, err := r.pool.Query(ctx, sqlStatement, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -194,7 +260,7 @@ func (r *repository) ListProducts(ctx context.Context, limit, offset int) ([]*do
 	var products []*domain.Product
 	for rows.Next() {
 		p := &domain.Product{}
-		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.SKU, &p.Amount, &p.CategoryID)
+		err := rows.Scan(&p.ID, &p.Name, &p.Price.Amount, &p.Price.Currency, &p.SKU, &p.Amount, &p.CategoryID, &p.ReorderPoint, &p.ReorderQuantity, &p.SupplierID, &p.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -207,16 +273,39 @@ func (r *repository) ListProducts(ctx context.Context, limit, offset int) ([]*do
 	if len(products) == 0 {
 		return []*domain.Product{}, nil
 	}
+	if err := r.loadWarehouseStock(ctx, products); err != nil {
+		return nil, err
+	}
+	if err := r.loadPrices(ctx, products); err != nil {
+		return nil, err
+	}
+	if err := r.loadVariants(ctx, products); err != nil {
+		return nil, err
+	}
+	if err := r.loadImages(ctx, products); err != nil {
+		return nil, err
+	}
 	return products, nil
 }
 
-func (r *repository) CreateOrder(ctx context.Context, order *domain.Order) error {
+// CreateOrder stores the order with its items and events and reserves stock for
+// every item in a single transaction. Item prices are set to the current product
+// or variant prices and their allocations to the warehouses chosen by strategy.
+func (r *repository) CreateOrder(ctx context.Context, order *domain.Order, strategy domain.FulfillmentStrategy, events ...domain.Event) (err error) {
+	defer observe(ctx, "CreateOrder")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	sqlStatement := `
-		INSERT INTO orders (id, user_id, created_at, updated_at, status)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id;
+		INSERT INTO orders (id, user_id, created_at, updated_at, status, currency, shipping_address, billing_address, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1)
+		RETURNING id, version;
 `
-	err := r.conn.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		sqlStatement,
 		order.ID,
@@ -224,61 +313,318 @@ func (r *repository) CreateOrder(ctx context.Context, order *domain.Order) error
 		order.CreatedAt,
 		order.UpdatedAt,
 		order.Status,
-	).Scan(&order.ID)
+		order.Currency,
+		newAddressSnapshot(order.ShippingAddress),
+		newAddressSnapshot(order.BillingAddress),
+	).Scan(&order.ID, &order.Version)
+	if err != nil {
+		return err
+	}
 
-	return err
+	var country string
+	if order.ShippingAddress != nil {
+		country = order.ShippingAddress.Country
+	}
+	for i := range order.Items {
+		item := &order.Items[i]
+		if err := reserveStock(ctx, tx, item, order.Currency); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO order_items (order_id, product_id, variant_id, quantity, price)
+			VALUES ($1, $2, $3, $4, $5)
+		`, order.ID, item.ProductID, item.VariantID, item.Quantity, item.Price.Amount)
+		if err != nil {
+			return err
+		}
+		if err := allocateStock(ctx, tx, order.ID, item, strategy, country); err != nil {
+			return err
+		}
+		for _, a := range item.Allocations {
+			err = insertMovement(ctx, tx, &domain.InventoryMovement{
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				WarehouseID: a.WarehouseID,
+				Type:        domain.MovementSale,
+				Quantity:    -a.Quantity,
+				Reason:      "order placed",
+				Actor:       order.UserID,
+				Reference:   order.ID,
+				CreatedAt:   order.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// reserveStock takes item.Quantity units of the product, and of its variant if
+// item.VariantID is set, out of stock and sets item.Price to the current price
+// in currency. Products with variants can only be ordered through one of them.
+func reserveStock(ctx context.Context, tx pgx.Tx, item *domain.OrderItem, currency domain.Currency) error {
+	var variantPrice *int64
+	if item.VariantID != "" {
+		if err := changeVariantStock(ctx, tx, item.ProductID, item.VariantID, -item.Quantity); err != nil {
+			return err
+		}
+		err := tx.QueryRow(ctx, `SELECT price FROM product_variants WHERE id = $1`, item.VariantID).Scan(&variantPrice)
+		if err != nil {
+			return err
+		}
+	}
+
+	var (
+		base        domain.Money
+		listed      *int64
+		hasVariants bool
+	)
+	err := tx.QueryRow(ctx, `
+		UPDATE products
+		SET amount = amount - $2, version = version + 1
+		WHERE id = $1 AND amount >= $2
+		RETURNING price, currency,
+			(SELECT amount FROM product_prices WHERE product_id = $1 AND currency = $3),
+			EXISTS (SELECT 1 FROM product_variants WHERE product_id = $1)
+	`, item.ProductID, item.Quantity, currency).Scan(&base.Amount, &base.Currency, &listed, &hasVariants)
+	if err == nil {
+		if item.VariantID == "" && hasVariants {
+			return fmt.Errorf("%w: product %q is sold in variants, choose one", domain.ErrorInvalidVariant, item.ProductID)
+		}
+		switch {
+		case base.Currency == currency && variantPrice != nil:
+			item.Price = domain.NewMoney(*variantPrice, currency)
+		case base.Currency == currency:
+			item.Price = base
+		case listed != nil && variantPrice == nil:
+			item.Price = domain.NewMoney(*listed, currency)
+		default:
+			return fmt.Errorf("%w: %s is not sold in %s", domain.ErrorPriceUnavailable, item.Line(), currency)
+		}
+		return nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)`, item.ProductID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrorProductNotFound
+	}
+	return domain.ErrorInsufficientStock
 }
 
-func (r *repository) UpdateOrder(ctx context.Context, order *domain.Order) error {
+func (r *repository) UpdateOrder(ctx context.Context, order *domain.Order, events ...domain.Event) (err error) {
+	defer observe(ctx, "UpdateOrder")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	sqlStatement := `
 		UPDATE orders
-		SET status = $1, updated_at = $2
-		WHERE id = $3
-		RETURNING id;
+		SET status = $1, updated_at = $2, version = version + 1
+		WHERE id = $3 AND version = $4
+		RETURNING version;
 `
-	err := r.conn.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		sqlStatement,
 		order.Status,
+		order.UpdatedAt,
 		order.ID,
-	).Scan(&order.ID)
-	return err
+		order.Version,
+	).Scan(&order.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.versionMismatch(ctx, "orders", order.ID, domain.ErrorOrderNotFound)
+	}
+	if err != nil {
+		return err
+	}
 
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *repository) GetOrderByID(ctx context.Context, ID string) (*domain.Order, error) {
+// PayOrder debits the order total from the user's balance and marks the order
+// paid. It fails with domain.ErrorOrderNotPending if the order was paid or
+// canceled concurrently, with domain.ErrorCurrencyMismatch if the balance is in
+// another currency than the order and with domain.ErrorInsufficientBalance if
+// the user cannot afford it.
+func (r *repository) PayOrder(ctx context.Context, order *domain.Order, events ...domain.Event) (err error) {
+	defer observe(ctx, "PayOrder")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var version int
+	err = tx.QueryRow(ctx, `
+		UPDATE orders
+		SET status = $2, updated_at = $3, version = version + 1
+		WHERE id = $1 AND status = $4
+		RETURNING version
+	`, order.ID, domain.StatusPaid, order.UpdatedAt, domain.StatusPending).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorOrderNotPending
+	}
+	if err != nil {
+		return err
+	}
+
+	total, err := order.Total()
+	if err != nil {
+		return err
+	}
+	var balance domain.Money
+	err = tx.QueryRow(ctx, `
+		SELECT balance, balance_currency FROM users WHERE id = $1 FOR UPDATE
+	`, order.UserID).Scan(&balance.Amount, &balance.Currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	remaining, err := balance.Sub(total)
+	if err != nil {
+		return err
+	}
+	if remaining.Amount < 0 {
+		return domain.ErrorInsufficientBalance
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET balance = $2, updated_at = $3, version = version + 1
+		WHERE id = $1
+	`, order.UserID, remaining.Amount, order.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	order.Status = domain.StatusPaid
+	order.Version = version
+	return nil
+}
+
+func (r *repository) GetOrderByID(ctx context.Context, ID string) (_ *domain.Order, err error) {
+	defer observe(ctx, "GetOrderByID")(&err)
+
 	sqlStatement := `
-SELECT id, userid, created_at, updated_at, status
+SELECT id, user_id, created_at, updated_at, status, currency, shipping_address, billing_address, version
 FROM orders
 WHERE id = $1`
 	order := &domain.Order{}
-	err := r.conn.QueryRow(ctx, sqlStatement, ID).Scan(
+	var shipping, billing *addressSnapshot
+	err = r.pool.QueryRow(ctx, sqlStatement, ID).Scan(
 		&order.ID,
 		&order.UserID,
 		&order.CreatedAt,
 		&order.UpdatedAt,
 		&order.Status,
+		&order.Currency,
+		&shipping,
+		&billing,
+		&order.Version,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, domain.ErrorProductNotFound
+			return nil, domain.ErrorOrderNotFound
+		}
+		return nil, err
+	}
+	order.ShippingAddress = shipping.toDomain(order.UserID)
+	order.BillingAddress = billing.toDomain(order.UserID)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT product_id, variant_id, quantity, price
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY product_id, variant_id
+	`, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := domain.OrderItem{Price: domain.NewMoney(0, order.Currency)}
+		if err := rows.Scan(&item.ProductID, &item.VariantID, &item.Quantity, &item.Price.Amount); err != nil {
+			return nil, err
+		}
+		order.Items = append(order.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT product_id, variant_id, warehouse_id, quantity
+		FROM order_item_allocations
+		WHERE order_id = $1
+		ORDER BY product_id, variant_id, warehouse_id
+	`, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			line       domain.OrderLine
+			allocation domain.StockAllocation
+		)
+		if err := rows.Scan(&line.ProductID, &line.VariantID, &allocation.WarehouseID, &allocation.Quantity); err != nil {
+			return nil, err
+		}
+		for i := range order.Items {
+			if order.Items[i].Line() == line {
+				order.Items[i].Allocations = append(order.Items[i].Allocations, allocation)
+			}
 		}
+	}
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return nil, err
+	return order, nil
 }
 
-func (r *repository) AddProductToCategory(ctx context.Context, categoryID, productID string) error {
+func (r *repository) AddProductToCategory(ctx context.Context, categoryID, productID string) (err error) {
+	defer observe(ctx, "AddProductToCategory")(&err)
+
 	sqlStatemnt := `
 		UPDATE products
-		SET category_id = $1
+		SET category_id = $1, version = version + 1
 		WHERE id = $2
 		RETURNING id;
 	`
 
 	var id string
-	err := r.conn.QueryRow(
+	err = r.pool.QueryRow(
 		ctx,
 		sqlStatemnt,
 		categoryID,
@@ -287,15 +633,17 @@ func (r *repository) AddProductToCategory(ctx context.Context, categoryID, produ
 	return err
 }
 
-func (r *repository) RemoveProductFromCategory(ctx context.Context, categoryID, productID string) error {
+func (r *repository) RemoveProductFromCategory(ctx context.Context, categoryID, productID string) (err error) {
+	defer observe(ctx, "RemoveProductFromCategory")(&err)
+
 	sqlStatement := `
 		UPDATE products
-		SET product_id = NULL
-		WHERE id = $2
+		SET category_id = NULL, version = version + 1
+		WHERE id = $2 AND category_id = $1
 		RETURNING id;
 	`
 	var id string
-	err := r.conn.QueryRow(
+	err = r.pool.QueryRow(
 		ctx,
 		sqlStatement,
 		categoryID,
@@ -304,14 +652,16 @@ func (r *repository) RemoveProductFromCategory(ctx context.Context, categoryID,
 	return err
 }
 
-func (r *repository) GetSupplierByID(ctx context.Context, ID string) (*domain.Supplier, error) {
+func (r *repository) GetSupplierByID(ctx context.Context, ID string) (_ *domain.Supplier, err error) {
+	defer observe(ctx, "GetSupplierByID")(&err)
+
 	sqlStatement := `
 		SELECT id, name
 		FROM suppliers
 		WHERE id = $1
 `
 	supplier := &domain.Supplier{}
-	err := r.conn.QueryRow(ctx, sqlStatement, ID).Scan(&supplier.ID, &supplier.Name)
+	err = r.pool.QueryRow(ctx, sqlStatement, ID).Scan(&supplier.ID, &supplier.Name)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrorSupplierNotFound
@@ -321,12 +671,14 @@ func (r *repository) GetSupplierByID(ctx context.Context, ID string) (*domain.Su
 	return supplier, nil
 }
 
-func (r *repository) DeleteSupplierByID(ctx context.Context, ID string) error {
+func (r *repository) DeleteSupplierByID(ctx context.Context, ID string) (err error) {
+	defer observe(ctx, "DeleteSupplierByID")(&err)
+
 	sqlStatement := `
 	DELETE FROM suppliers
 	WHERE id = $1
 	`
-	tag, err := r.conn.Exec(ctx, sqlStatement, ID)
+	tag, err := r.pool.Exec(ctx, sqlStatement, ID)
 	if err != nil {
 		return err
 	}
@@ -336,3 +688,17 @@ func (r *repository) DeleteSupplierByID(ctx context.Context, ID string) error {
 	}
 	return nil
 }
+
+// versionMismatch tells apart why a versioned update of the row id in table
+// matched nothing: the row is gone (notFound) or its version moved on.
+func (r *repository) versionMismatch(ctx context.Context, table, id string, notFound error) error {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return notFound
+	}
+	return domain.ErrorVersionConflict
+}
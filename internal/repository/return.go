@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateReturn stores a requested return with its items, first history entry
+// and events in a single transaction. The order is locked while the returned
+// quantities are checked again, so concurrent requests cannot return the same
+// items twice; it fails with domain.ErrorOrderNotReturnable if the order is no
+// longer completed and with domain.ErrorInvalidReturn if an item is already
+// being returned.
+func (r *repository) CreateReturn(ctx context.Context, ret *domain.Return, events ...domain.Event) (err error) {
+	defer observe(ctx, "CreateReturn")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkReturnable(ctx, tx, ret); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO returns (id, order_id, user_id, status, comment, refund_amount, refund_currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, ret.ID, ret.OrderID, ret.UserID, ret.Status, ret.Comment, ret.RefundAmount.Amount, ret.RefundAmount.Currency, ret.CreatedAt, ret.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	for _, item := range ret.Items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO return_items (return_id, product_id, variant_id, quantity, reason)
+			VALUES ($1, $2, $3, $4, $5)
+		`, ret.ID, item.ProductID, item.VariantID, item.Quantity, item.Reason)
+		if err != nil {
+			return err
+		}
+	}
+	for _, change := range ret.History {
+		if err := insertReturnStatusChange(ctx, tx, ret.ID, change); err != nil {
+			return err
+		}
+	}
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// checkReturnable locks the order of ret and checks that each of its items
+// is still part of the order and not returned by another return that was not
+// rejected.
+func checkReturnable(ctx context.Context, tx pgx.Tx, ret *domain.Return) error {
+	var status domain.Status
+	err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, ret.OrderID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != domain.StatusCompleted {
+		return domain.ErrorOrderNotReturnable
+	}
+
+	for _, item := range ret.Items {
+		var left int
+		err := tx.QueryRow(ctx, `
+			SELECT oi.quantity - COALESCE((
+				SELECT SUM(ri.quantity)
+				FROM return_items ri
+				JOIN returns r ON r.id = ri.return_id
+				WHERE r.order_id = oi.order_id AND r.status <> $4
+					AND ri.product_id = oi.product_id AND ri.variant_id = oi.variant_id
+			), 0)
+			FROM order_items oi
+			WHERE oi.order_id = $1 AND oi.product_id = $2 AND oi.variant_id = $3
+		`, ret.OrderID, item.ProductID, item.VariantID, domain.ReturnRejected).Scan(&left)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %s is not part of the order", domain.ErrorInvalidReturn, item.Line())
+		}
+		if err != nil {
+			return err
+		}
+		if item.Quantity > left {
+			return fmt.Errorf("%w: only %d of %s can be returned", domain.ErrorInvalidReturn, max(left, 0), item.Line())
+		}
+	}
+	return nil
+}
+
+// UpdateReturnStatus moves the return from change.From to change.To, records
+// the change and stores events in a single transaction. Moving to received
+// puts the returned items back into stock of the warehouse they were shipped
+// from; moving to refunded credits RefundAmount to the user's balance, which
+// fails with domain.ErrorCurrencyMismatch if the balance is in another
+// currency. It fails with domain.ErrorReturnTransition if the return left
+// change.From concurrently.
+func (r *repository) UpdateReturnStatus(ctx context.Context, ret *domain.Return, change domain.ReturnStatusChange, events ...domain.Event) (err error) {
+	defer observe(ctx, "UpdateReturnStatus")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE returns
+		SET status = $3, updated_at = $4
+		WHERE id = $1 AND status = $2
+	`, ret.ID, change.From, change.To, change.At)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrorReturnTransition
+	}
+
+	switch change.To {
+	case domain.ReturnReceived:
+		for _, item := range ret.Items {
+			_, err := tx.Exec(ctx, `
+				UPDATE products
+				SET amount = amount + $2, version = version + 1
+				WHERE id = $1
+			`, item.ProductID, item.Quantity)
+			if err != nil {
+				return err
+			}
+			if item.VariantID != "" {
+				if err := changeVariantStock(ctx, tx, item.ProductID, item.VariantID, item.Quantity); err != nil {
+					return err
+				}
+			}
+			warehouseID, err := returnWarehouse(ctx, tx, ret.OrderID, item.Line())
+			if err != nil {
+				return err
+			}
+			if err := changeWarehouseStock(ctx, tx, warehouseID, item.Line(), item.Quantity); err != nil {
+				return err
+			}
+			err = insertMovement(ctx, tx, &domain.InventoryMovement{
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				WarehouseID: warehouseID,
+				Type:        domain.MovementReturn,
+				Quantity:    item.Quantity,
+				Reason:      string(item.Reason),
+				Actor:       ret.UserID,
+				Reference:   ret.ID,
+				CreatedAt:   change.At,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	case domain.ReturnRefunded:
+		var balance domain.Money
+		err := tx.QueryRow(ctx, `
+			SELECT balance, balance_currency FROM users WHERE id = $1 FOR UPDATE
+		`, ret.UserID).Scan(&balance.Amount, &balance.Currency)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrorUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if balance, err = balance.Add(ret.RefundAmount); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE users
+			SET balance = $2, updated_at = $3, version = version + 1
+			WHERE id = $1
+		`, ret.UserID, balance.Amount, change.At)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := insertReturnStatusChange(ctx, tx, ret.ID, change); err != nil {
+		return err
+	}
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertReturnStatusChange(ctx context.Context, tx pgx.Tx, returnID string, change domain.ReturnStatusChange) error {
+	var from *domain.ReturnStatus
+	if change.From != "" {
+		from = &change.From
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO return_status_changes (return_id, from_status, to_status, note, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, returnID, from, change.To, change.Note, change.At)
+	return err
+}
+
+// GetReturn returns a return with its items and history.
+func (r *repository) GetReturn(ctx context.Context, id string) (_ *domain.Return, err error) {
+	defer observe(ctx, "GetReturn")(&err)
+
+	ret := &domain.Return{}
+	err = r.pool.QueryRow(ctx, `
+		SELECT id, order_id, user_id, status, comment, refund_amount, refund_currency, created_at, updated_at
+		FROM returns
+		WHERE id = $1
+	`, id).Scan(&ret.ID, &ret.OrderID, &ret.UserID, &ret.Status, &ret.Comment, &ret.RefundAmount.Amount, &ret.RefundAmount.Currency, &ret.CreatedAt, &ret.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrorReturnNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadReturnDetails(ctx, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ListReturns returns the returns of an order with their items and history,
+// oldest first.
+func (r *repository) ListReturns(ctx context.Context, orderID string) (_ []*domain.Return, err error) {
+	defer observe(ctx, "ListReturns")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, order_id, user_id, status, comment, refund_amount, refund_currency, created_at, updated_at
+		FROM returns
+		WHERE order_id = $1
+		ORDER BY created_at, id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []*domain.Return{}
+	for rows.Next() {
+		ret := &domain.Return{}
+		if err := rows.Scan(&ret.ID, &ret.OrderID, &ret.UserID, &ret.Status, &ret.Comment, &ret.RefundAmount.Amount, &ret.RefundAmount.Currency, &ret.CreatedAt, &ret.UpdatedAt); err != nil {
+			return nil, err
+		}
+		returns = append(returns, ret)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, ret := range returns {
+		if err := r.loadReturnDetails(ctx, ret); err != nil {
+			return nil, err
+		}
+	}
+	return returns, nil
+}
+
+func (r *repository) loadReturnDetails(ctx context.Context, ret *domain.Return) error {
+	rows, err := r.pool.Query(ctx, `
+		SELECT product_id, variant_id, quantity, reason
+		FROM return_items
+		WHERE return_id = $1
+		ORDER BY product_id, variant_id
+	`, ret.ID)
+	if err != nil {
+		return err
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ReturnItem, error) {
+		var item domain.ReturnItem
+		err := row.Scan(&item.ProductID, &item.VariantID, &item.Quantity, &item.Reason)
+		return item, err
+	})
+	if err != nil {
+		return err
+	}
+	ret.Items = items
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT COALESCE(from_status, ''), to_status, note, created_at
+		FROM return_status_changes
+		WHERE return_id = $1
+		ORDER BY id
+	`, ret.ID)
+	if err != nil {
+		return err
+	}
+	history, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ReturnStatusChange, error) {
+		var change domain.ReturnStatusChange
+		err := row.Scan(&change.From, &change.To, &change.Note, &change.At)
+		return change, err
+	})
+	if err != nil {
+		return err
+	}
+	ret.History = history
+	return nil
+}
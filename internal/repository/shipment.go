@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateShipment stores the shipment and events, and saves order.Status, in a
+// single transaction. order.Version must match the stored version, so that two
+// concurrent shipments cannot both ship the same items.
+func (r *repository) CreateShipment(ctx context.Context, shipment *domain.Shipment, order *domain.Order, events ...domain.Event) (err error) {
+	defer observe(ctx, "CreateShipment")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.updateOrderStatus(ctx, tx, order); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO shipments (id, order_id, carrier, tracking_number, shipped_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, shipment.ID, shipment.OrderID, shipment.Carrier, shipment.TrackingNumber, shipment.ShippedAt)
+	if err != nil {
+		return err
+	}
+	for _, item := range shipment.Items {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO shipment_items (shipment_id, product_id, variant_id, quantity)
+			VALUES ($1, $2, $3, $4)
+		`, shipment.ID, item.ProductID, item.VariantID, item.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeliverShipment stores shipment.DeliveredAt and events, and saves
+// order.Status, in a single transaction. order.Version must match the stored
+// version.
+func (r *repository) DeliverShipment(ctx context.Context, shipment *domain.Shipment, order *domain.Order, events ...domain.Event) (err error) {
+	defer observe(ctx, "DeliverShipment")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.updateOrderStatus(ctx, tx, order); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE shipments
+		SET delivered_at = $3
+		WHERE order_id = $1 AND id = $2 AND delivered_at IS NULL
+	`, shipment.OrderID, shipment.ID, shipment.DeliveredAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrorVersionConflict
+	}
+
+	if err := insertEvents(ctx, tx, events); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// updateOrderStatus saves order.Status and order.UpdatedAt provided the order
+// is still at order.Version, and bumps order.Version.
+func (r *repository) updateOrderStatus(ctx context.Context, tx pgx.Tx, order *domain.Order) error {
+	err := tx.QueryRow(ctx, `
+		UPDATE orders
+		SET status = $3, updated_at = $4, version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING version
+	`, order.ID, order.Version, order.Status, order.UpdatedAt).Scan(&order.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.versionMismatch(ctx, "orders", order.ID, domain.ErrorOrderNotFound)
+	}
+	return err
+}
+
+// ListShipments returns the shipments of an order, oldest first.
+func (r *repository) ListShipments(ctx context.Context, orderID string) (_ []*domain.Shipment, err error) {
+	defer observe(ctx, "ListShipments")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.id, s.order_id, s.carrier, s.tracking_number, s.shipped_at, s.delivered_at, i.product_id, i.variant_id, i.quantity
+		FROM shipments s
+		JOIN shipment_items i ON i.shipment_id = s.id
+		WHERE s.order_id = $1
+		ORDER BY s.shipped_at, s.id, i.product_id, i.variant_id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipments := []*domain.Shipment{}
+	for rows.Next() {
+		var s domain.Shipment
+		var item domain.ShipmentItem
+		if err := rows.Scan(&s.ID, &s.OrderID, &s.Carrier, &s.TrackingNumber, &s.ShippedAt, &s.DeliveredAt, &item.ProductID, &item.VariantID, &item.Quantity); err != nil {
+			return nil, err
+		}
+		if n := len(shipments); n == 0 || shipments[n-1].ID != s.ID {
+			shipments = append(shipments, &s)
+		}
+		last := shipments[len(shipments)-1]
+		last.Items = append(last.Items, item)
+	}
+	return shipments, rows.Err()
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateUserToken stores token and voids the user's earlier unused tokens of
+// the same purpose, so only the most recently mailed one works.
+func (r *repository) CreateUserToken(ctx context.Context, token *domain.UserToken) (err error) {
+	defer observe(ctx, "CreateUserToken")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := voidUserTokens(ctx, tx, token.UserID, token.Purpose, token.CreatedAt); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_tokens (hash, user_id, purpose, email, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, token.Hash, token.UserID, token.Purpose, token.Email, token.CreatedAt, token.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// VerifyEmail uses up the email verification token with the given hash and
+// marks the address it was sent to as verified.
+func (r *repository) VerifyEmail(ctx context.Context, hash string, now time.Time) (err error) {
+	defer observe(ctx, "VerifyEmail")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	token, err := useUserToken(ctx, tx, hash, domain.TokenEmailVerification, now)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET email_verified = true, updated_at = $3, version = version + 1
+		WHERE id = $1 AND email = $2 AND NOT email_verified
+	`, token.UserID, token.Email, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		// The email changed since the token was sent, or it was verified already.
+		var verified bool
+		err := tx.QueryRow(ctx, `SELECT email_verified FROM users WHERE id = $1 AND email = $2`, token.UserID, token.Email).Scan(&verified)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrorInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ResetPassword uses up the password reset token with the given hash, sets the
+// user's password hash and voids the user's other reset tokens. Since the token
+// was mailed to the user, their email counts as verified as well.
+func (r *repository) ResetPassword(ctx context.Context, hash, password string, now time.Time) (err error) {
+	defer observe(ctx, "ResetPassword")(&err)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	token, err := useUserToken(ctx, tx, hash, domain.TokenPasswordReset, now)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET password = $3, email_verified = true, updated_at = $4, version = version + 1
+		WHERE id = $1 AND email = $2
+	`, token.UserID, token.Email, password, now)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrorInvalidToken
+	}
+
+	if err := voidUserTokens(ctx, tx, token.UserID, domain.TokenPasswordReset, now); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// useUserToken marks an unused, unexpired token as used and returns it. It
+// returns domain.ErrorInvalidToken for any other token.
+func useUserToken(ctx context.Context, tx pgx.Tx, hash string, purpose domain.TokenPurpose, now time.Time) (*domain.UserToken, error) {
+	token := &domain.UserToken{Hash: hash, Purpose: purpose}
+	err := tx.QueryRow(ctx, `
+		UPDATE user_tokens
+		SET used_at = $3
+		WHERE hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING user_id, email, created_at, expires_at
+	`, hash, purpose, now).Scan(&token.UserID, &token.Email, &token.CreatedAt, &token.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrorInvalidToken
+	}
+	return token, err
+}
+
+func voidUserTokens(ctx context.Context, tx pgx.Tx, userID string, purpose domain.TokenPurpose, now time.Time) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE user_tokens
+		SET used_at = $3
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, userID, purpose, now)
+	return err
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateVariant stores a variant of an existing product. It fails with
+// domain.ErrorVariantExists if another variant has the same SKU, or the
+// product already has a variant with the same options.
+func (r *repository) CreateVariant(ctx context.Context, variant *domain.Variant) (err error) {
+	defer observe(ctx, "CreateVariant")(&err)
+
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO product_variants (id, product_id, sku, options, price, amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6)
+		ON CONFLICT DO NOTHING
+		RETURNING amount
+	`, variant.ID, variant.ProductID, variant.SKU, variant.Options, moneyAmount(variant.Price), variant.CreatedAt).Scan(&variant.Amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrorVariantExists
+	}
+	return err
+}
+
+// loadVariants sets the variants of products, oldest first.
+func (r *repository) loadVariants(ctx context.Context, products []*domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(products))
+	byID := make(map[string]*domain.Product, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+		byID[p.ID] = p
+		p.Variants = nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, product_id, sku, options, price, amount, created_at
+		FROM product_variants
+		WHERE product_id = ANY($1)
+		ORDER BY created_at, id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			v     domain.Variant
+			price *int64
+		)
+		if err := rows.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Options, &price, &v.Amount, &v.CreatedAt); err != nil {
+			return err
+		}
+		p := byID[v.ProductID]
+		if price != nil {
+			v.Price = &domain.Money{Amount: *price, Currency: p.Price.Currency}
+		}
+		p.Variants = append(p.Variants, v)
+	}
+	return rows.Err()
+}
+
+// changeVariantStock changes the amount of a variant of productID by delta.
+// It fails with domain.ErrorVariantNotFound if the product has no such variant
+// and with domain.ErrorInsufficientStock if the amount would become negative.
+func changeVariantStock(ctx context.Context, tx pgx.Tx, productID, variantID string, delta int) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE product_variants
+		SET amount = amount + $3
+		WHERE id = $2 AND product_id = $1 AND amount + $3 >= 0
+	`, productID, variantID, delta)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM product_variants WHERE id = $2 AND product_id = $1)
+	`, productID, variantID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrorVariantNotFound
+	}
+	return domain.ErrorInsufficientStock
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *repository) CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) (err error) {
+	defer observe(ctx, "CreateWarehouse")(&err)
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO warehouses (id, name, country, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, warehouse.ID, warehouse.Name, warehouse.Country, warehouse.CreatedAt)
+	return err
+}
+
+func (r *repository) ListWarehouses(ctx context.Context) (_ []*domain.Warehouse, err error) {
+	defer observe(ctx, "ListWarehouses")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, country, created_at
+		FROM warehouses
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*domain.Warehouse, error) {
+		var w domain.Warehouse
+		err := row.Scan(&w.ID, &w.Name, &w.Country, &w.CreatedAt)
+		return &w, err
+	})
+}
+
+// loadWarehouseStock sets the per-warehouse stock of products, summed over
+// their variants.
+func (r *repository) loadWarehouseStock(ctx context.Context, products []*domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(products))
+	byID := make(map[string]*domain.Product, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+		byID[p.ID] = p
+		p.Stock = []domain.WarehouseStock{}
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT product_id, warehouse_id, SUM(amount)
+		FROM warehouse_stock
+		WHERE product_id = ANY($1)
+		GROUP BY product_id, warehouse_id
+		ORDER BY warehouse_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			productID string
+			stock     domain.WarehouseStock
+		)
+		if err := rows.Scan(&productID, &stock.WarehouseID, &stock.Amount); err != nil {
+			return err
+		}
+		byID[productID].Stock = append(byID[productID].Stock, stock)
+	}
+	return rows.Err()
+}
+
+// allocateStock reserves item.Quantity of the order line in the warehouses
+// that hold it, ranked first by strategy, spilling over to the next warehouse when one runs
+// out, and records the allocations of the order line. country is where the
+// order ships to.
+func allocateStock(ctx context.Context, tx pgx.Tx, orderID string, item *domain.OrderItem, strategy domain.FulfillmentStrategy, country string) error {
+	order := `(w.country = $2) DESC, w.id`
+	if strategy == domain.FulfillMostStock {
+		order = `s.amount DESC, w.id`
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT s.warehouse_id, s.amount
+		FROM warehouse_stock s
+		JOIN warehouses w ON w.id = s.warehouse_id
+		WHERE s.product_id = $1 AND s.variant_id = $3 AND s.amount > 0
+		ORDER BY `+order+`
+		FOR UPDATE OF s
+	`, item.ProductID, country, item.VariantID)
+	if err != nil {
+		return err
+	}
+	available, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.WarehouseStock])
+	if err != nil {
+		return err
+	}
+
+	item.Allocations = nil
+	left := item.Quantity
+	for _, stock := range available {
+		if left == 0 {
+			break
+		}
+		take := min(left, stock.Amount)
+		item.Allocations = append(item.Allocations, domain.StockAllocation{WarehouseID: stock.WarehouseID, Quantity: take})
+		left -= take
+	}
+	if left > 0 {
+		return domain.ErrorInsufficientStock
+	}
+
+	for _, a := range item.Allocations {
+		if err := changeWarehouseStock(ctx, tx, a.WarehouseID, item.Line(), -a.Quantity); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO order_item_allocations (order_id, product_id, variant_id, warehouse_id, quantity)
+			VALUES ($1, $2, $3, $4, $5)
+		`, orderID, item.ProductID, item.VariantID, a.WarehouseID, a.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeWarehouseStock changes the amount of an order line, a product or one
+// of its variants, in a warehouse by delta. It fails with
+// domain.ErrorInsufficientStock if the amount would become negative and with
+// domain.ErrorWarehouseNotFound for unknown warehouses.
+func changeWarehouseStock(ctx context.Context, tx pgx.Tx, warehouseID string, line domain.OrderLine, delta int) error {
+	if delta < 0 {
+		tag, err := tx.Exec(ctx, `
+			UPDATE warehouse_stock
+			SET amount = amount + $4
+			WHERE warehouse_id = $1 AND product_id = $2 AND variant_id = $3 AND amount + $4 >= 0
+		`, warehouseID, line.ProductID, line.VariantID, delta)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrorInsufficientStock
+		}
+		return nil
+	}
+
+	var exists bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM warehouses WHERE id = $1)`, warehouseID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrorWarehouseNotFound
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, amount)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (warehouse_id, product_id, variant_id) DO UPDATE
+		SET amount = warehouse_stock.amount + EXCLUDED.amount
+	`, warehouseID, line.ProductID, line.VariantID, delta)
+	return err
+}
+
+// returnWarehouse returns the warehouse a returned order line goes back to:
+// the one most of it was taken from.
+func returnWarehouse(ctx context.Context, tx pgx.Tx, orderID string, line domain.OrderLine) (string, error) {
+	var warehouseID string
+	err := tx.QueryRow(ctx, `
+		SELECT warehouse_id
+		FROM order_item_allocations
+		WHERE order_id = $1 AND product_id = $2 AND variant_id = $3
+		ORDER BY quantity DESC, warehouse_id
+		LIMIT 1
+	`, orderID, line.ProductID, line.VariantID).Scan(&warehouseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.DefaultWarehouse, nil
+	}
+	return warehouseID, err
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *repository) CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) (err error) {
+	defer observe(ctx, "CreateWebhookSubscription")(&err)
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO webhook_subscriptions (id, url, event_types, secret, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, sub.ID, sub.URL, eventTypeStrings(sub.EventTypes), sub.Secret, sub.CreatedAt)
+	return err
+}
+
+func (r *repository) GetWebhookSubscription(ctx context.Context, id string) (_ *domain.WebhookSubscription, err error) {
+	defer observe(ctx, "GetWebhookSubscription")(&err)
+
+	row := r.pool.QueryRow(ctx, `
+		SELECT id, url, event_types, secret, created_at
+		FROM webhook_subscriptions
+		WHERE id = $1
+	`, id)
+	sub, err := scanWebhookSubscription(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrorWebhookNotFound
+	}
+	return sub, err
+}
+
+func (r *repository) ListWebhookSubscriptions(ctx context.Context) (_ []*domain.WebhookSubscription, err error) {
+	defer observe(ctx, "ListWebhookSubscriptions")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, url, event_types, secret, created_at
+		FROM webhook_subscriptions
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*domain.WebhookSubscription
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (r *repository) DeleteWebhookSubscription(ctx context.Context, id string) (err error) {
+	defer observe(ctx, "DeleteWebhookSubscription")(&err)
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrorWebhookNotFound
+	}
+	return nil
+}
+
+// EnqueueWebhookDeliveries creates a pending delivery of payload for every
+// subscription to the event's type. Calling it again for the same event adds
+// nothing, so it is safe to repeat when the event itself is retried.
+func (r *repository) EnqueueWebhookDeliveries(ctx context.Context, event domain.Event, payload []byte, now time.Time) (_ int64, err error) {
+	defer observe(ctx, "EnqueueWebhookDeliveries")(&err)
+
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		SELECT gen_random_uuid()::text, id, $1, $2, $3, $4, $5, $5
+		FROM webhook_subscriptions
+		WHERE $2 = ANY (event_types)
+		ON CONFLICT (subscription_id, event_id) WHERE redelivery_of IS NULL DO NOTHING
+	`, event.ID, event.Type, payload, domain.DeliveryPending, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *repository) CreateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) (err error) {
+	defer observe(ctx, "CreateWebhookDelivery")(&err)
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at, redelivery_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, d.ID, d.SubscriptionID, d.EventID, d.EventType, d.Payload, d.Status, d.NextAttemptAt, d.CreatedAt, d.RedeliveryOf)
+	return err
+}
+
+// ClaimWebhookDeliveries returns up to limit pending deliveries due at now,
+// oldest first. Claimed deliveries count one more attempt and are hidden from
+// other workers until leaseUntil.
+func (r *repository) ClaimWebhookDeliveries(ctx context.Context, limit int, now, leaseUntil time.Time) (_ []*domain.WebhookDelivery, err error) {
+	defer observe(ctx, "ClaimWebhookDeliveries")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, next_attempt_at = $4
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= $3
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns, limit, domain.DeliveryPending, now, leaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := collectWebhookDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery.
+	slices.SortFunc(deliveries, func(a, b *domain.WebhookDelivery) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery stores the outcome of a delivery attempt.
+func (r *repository) UpdateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) (err error) {
+	defer observe(ctx, "UpdateWebhookDelivery")(&err)
+
+	_, err = r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, next_attempt_at = $3, response_status = NULLIF($4, 0), last_error = NULLIF($5, ''), delivered_at = $6
+		WHERE id = $1
+	`, d.ID, d.Status, d.NextAttemptAt, d.ResponseStatus, d.LastError, d.DeliveredAt)
+	return err
+}
+
+func (r *repository) GetWebhookDelivery(ctx context.Context, id string) (_ *domain.WebhookDelivery, err error) {
+	defer observe(ctx, "GetWebhookDelivery")(&err)
+
+	rows, err := r.pool.Query(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := collectWebhookDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, domain.ErrorDeliveryNotFound
+	}
+	return deliveries[0], nil
+}
+
+// ListWebhookDeliveries returns the latest deliveries of a subscription,
+// newest first.
+func (r *repository) ListWebhookDeliveries(ctx context.Context, subscriptionID string, limit int) (_ []*domain.WebhookDelivery, err error) {
+	defer observe(ctx, "ListWebhookDeliveries")(&err)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectWebhookDeliveries(rows)
+}
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	COALESCE(response_status, 0), COALESCE(last_error, ''), created_at, delivered_at, redelivery_of`
+
+func collectWebhookDeliveries(rows pgx.Rows) ([]*domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d := &domain.WebhookDelivery{}
+		err := rows.Scan(
+			&d.ID,
+			&d.SubscriptionID,
+			&d.EventID,
+			&d.EventType,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.ResponseStatus,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+			&d.RedeliveryOf,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhookSubscription(row pgx.Row) (*domain.WebhookSubscription, error) {
+	var (
+		sub   domain.WebhookSubscription
+		types []string
+	)
+	if err := row.Scan(&sub.ID, &sub.URL, &types, &sub.Secret, &sub.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, t := range types {
+		sub.EventTypes = append(sub.EventTypes, domain.EventType(t))
+	}
+	return &sub, nil
+}
+
+func eventTypeStrings(types []domain.EventType) []string {
+	out := make([]string, 0, len(types))
+	for _, t := range types {
+		out = append(out, string(t))
+	}
+	return out
+}
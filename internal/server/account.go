@@ -0,0 +1,77 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestEmailVerificationHandler mails a new verification token. It answers
+// 202 whether or not the email is registered.
+func (s *Server) RequestEmailVerificationHandler(c *gin.Context) {
+	var dto EmailDTO
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing email"})
+		return
+	}
+
+	if err := s.service.RequestEmailVerification(c.Request.Context(), dto.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func (s *Server) VerifyEmailHandler(c *gin.Context) {
+	var dto TokenDTO
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing token"})
+		return
+	}
+
+	if err := s.service.VerifyEmail(c.Request.Context(), dto.Token); err != nil {
+		if errors.Is(err, domain.ErrorInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RequestPasswordResetHandler mails a password reset token. It answers 202
+// whether or not the email is registered.
+func (s *Server) RequestPasswordResetHandler(c *gin.Context) {
+	var dto EmailDTO
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing email"})
+		return
+	}
+
+	if err := s.service.RequestPasswordReset(c.Request.Context(), dto.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func (s *Server) ConfirmPasswordResetHandler(c *gin.Context) {
+	var dto TokenDTO
+	if err := c.ShouldBindJSON(&dto); err != nil || dto.Token == "" || dto.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required fields"})
+		return
+	}
+
+	if err := s.service.ResetPassword(c.Request.Context(), dto.Token, dto.Password); err != nil {
+		if errors.Is(err, domain.ErrorInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
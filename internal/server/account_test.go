@@ -0,0 +1,137 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_AccountTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		svc          server.Service
+		expectedCode int
+	}{
+		{
+			name: "request password reset",
+			path: "/api/v1/auth/password-reset/request",
+			body: `{"email":"qwe@qwe.qwe"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().RequestPasswordReset(gomock.Any(), "qwe@qwe.qwe").Return(nil)
+				return s
+			}(),
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "request password reset without email",
+			path:         "/api/v1/auth/password-reset/request",
+			body:         `{}`,
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "confirm password reset",
+			path: "/api/v1/auth/password-reset/confirm",
+			body: `{"token":"t0k3n","password":"new"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().ResetPassword(gomock.Any(), "t0k3n", "new").Return(nil)
+				return s
+			}(),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "confirm password reset without password",
+			path:         "/api/v1/auth/password-reset/confirm",
+			body:         `{"token":"t0k3n"}`,
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "confirm password reset with used token",
+			path: "/api/v1/auth/password-reset/confirm",
+			body: `{"token":"t0k3n","password":"new"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().ResetPassword(gomock.Any(), "t0k3n", "new").Return(domain.ErrorInvalidToken)
+				return s
+			}(),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "request email verification",
+			path: "/api/v1/auth/verify-email/request",
+			body: `{"email":"qwe@qwe.qwe"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().RequestEmailVerification(gomock.Any(), "qwe@qwe.qwe").Return(nil)
+				return s
+			}(),
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name: "verify email",
+			path: "/api/v1/auth/verify-email",
+			body: `{"token":"t0k3n"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().VerifyEmail(gomock.Any(), "t0k3n").Return(nil)
+				return s
+			}(),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "verify email with expired token",
+			path: "/api/v1/auth/verify-email",
+			body: `{"token":"t0k3n"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().VerifyEmail(gomock.Any(), "t0k3n").Return(domain.ErrorInvalidToken)
+				return s
+			}(),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := server.NewServer(tt.svc).SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_CreateOrderUnverifiedEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(domain.ErrorEmailNotVerified)
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/api/v1/orders", bytes.NewBufferString(`{"user_id":"u1","items":[{"product_id":"p1","quantity":1}]}`))
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) CreateAddressHandler(c *gin.Context) {
+	var dto AddressDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	address := dto.toDomain()
+	address.UserID = c.Param("id")
+	if err := s.service.CreateAddress(c.Request.Context(), address); err != nil {
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newAddressDTO(address))
+}
+
+func (s *Server) ListAddressesHandler(c *gin.Context) {
+	addresses, err := s.service.ListAddresses(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeAddressError(c, err)
+		return
+	}
+
+	dtos := make([]AddressDTO, 0, len(addresses))
+	for _, address := range addresses {
+		dtos = append(dtos, newAddressDTO(address))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+func (s *Server) GetAddressHandler(c *gin.Context) {
+	address, err := s.service.GetAddress(c.Request.Context(), c.Param("id"), c.Param("addressID"))
+	if err != nil {
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newAddressDTO(address))
+}
+
+func (s *Server) UpdateAddressHandler(c *gin.Context) {
+	var dto AddressDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	address := dto.toDomain()
+	address.ID = c.Param("addressID")
+	address.UserID = c.Param("id")
+	if err := s.service.UpdateAddress(c.Request.Context(), address); err != nil {
+		writeAddressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newAddressDTO(address))
+}
+
+func (s *Server) DeleteAddressHandler(c *gin.Context) {
+	if err := s.service.DeleteAddress(c.Request.Context(), c.Param("id"), c.Param("addressID")); err != nil {
+		writeAddressError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeAddressError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrorUserNotFound), errors.Is(err, domain.ErrorAddressNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorInvalidAddress):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,133 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_CreateAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		body         string
+		svc          server.Service
+		expectedCode int
+	}{
+		{
+			name: "success",
+			body: `{"recipient":"Arnur","line1":"Abay 1","city":"Almaty","postal_code":"050000","country":"kz"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().CreateAddress(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, address *domain.Address) error {
+					assert.Equal(t, "u1", address.UserID)
+					assert.Equal(t, "Abay 1", address.Line1)
+					address.ID = "a1"
+					address.IsDefault = true
+					return nil
+				})
+				return s
+			}(),
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "invalid address",
+			body: `{"recipient":"Arnur"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().CreateAddress(gomock.Any(), gomock.Any()).Return(domain.ErrorInvalidAddress)
+				return s
+			}(),
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "unknown user",
+			body: `{"recipient":"Arnur","line1":"Abay 1","city":"Almaty","postal_code":"050000","country":"KZ"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().CreateAddress(gomock.Any(), gomock.Any()).Return(domain.ErrorUserNotFound)
+				return s
+			}(),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := server.NewServer(tt.svc).SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/users/u1/addresses", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusCreated {
+				var resp server.AddressDTO
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "a1", resp.ID)
+				assert.True(t, resp.IsDefault)
+			}
+		})
+	}
+}
+
+func TestServer_DeleteAddressNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().DeleteAddress(gomock.Any(), "u1", "a9").Return(domain.ErrorAddressNotFound)
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("DELETE", "/api/v1/users/u1/addresses/a9", nil)
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServer_CreateOrderWithAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order) error {
+		assert.Equal(t, "a1", order.ShippingAddress.ID)
+		assert.Nil(t, order.BillingAddress)
+		order.ShippingAddress = &domain.Address{ID: "a1", Recipient: "Arnur", City: "Almaty", Country: "KZ", IsDefault: true}
+		order.BillingAddress = order.ShippingAddress
+		return nil
+	})
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/api/v1/orders", bytes.NewBufferString(`{"user_id":"u1","shipping_address_id":"a1","items":[{"product_id":"p1","quantity":1}]}`))
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]any{
+		"id":          "a1",
+		"recipient":   "Arnur",
+		"line1":       "",
+		"city":        "Almaty",
+		"postal_code": "",
+		"country":     "KZ",
+		"is_default":  false,
+	}, resp["shipping_address"])
+	assert.Equal(t, resp["shipping_address"], resp["billing_address"])
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPISpec is the OpenAPI 3 document describing every route registered in SetupRouter.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Shop API</title>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+  <redoc spec-url="/openapi.json"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+func (s *Server) OpenAPIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", OpenAPISpec)
+}
+
+func (s *Server) DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(docsPage))
+}
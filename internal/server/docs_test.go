@@ -0,0 +1,64 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var ginParam = regexp.MustCompile(`[:*](\w+)`)
+
+// TestOpenAPISpec_CoversRoutes fails when a route registered in SetupRouter is
+// missing from openapi.json.
+func TestOpenAPISpec_CoversRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(server.OpenAPISpec, &spec))
+	assert.True(t, strings.HasPrefix(spec.OpenAPI, "3."))
+
+	s := server.NewServer(internalMock.NewMockService(ctrl))
+	for _, route := range s.SetupRouter().Routes() {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+
+		operations, ok := spec.Paths[path]
+		if !assert.Truef(t, ok, "path %s is not documented", path) {
+			continue
+		}
+		_, ok = operations[strings.ToLower(route.Method)]
+		assert.Truef(t, ok, "operation %s %s is not documented", route.Method, path)
+	}
+}
+
+func TestServer_OpenAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := server.NewServer(internalMock.NewMockService(ctrl))
+	r := s.SetupRouter()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/openapi.json", nil)
+	assert.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(server.OpenAPISpec), w.Body.String())
+}
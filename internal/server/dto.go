@@ -1,18 +1,39 @@
 package server
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
 
 type Status string
 type UserDTO struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password"`
-	Number    string    `json:"number"`
-	Address   string    `json:"address"`
-	Balance   int       `json:"balance"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+	Number   string `json:"number"`
+	Address  string `json:"address"`
+	// Balance is read-only; it is ignored in requests and starts at zero in
+	// the default currency.
+	Balance domain.Money `json:"balance"`
+	// EmailVerified is read-only; it is ignored in requests.
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// EmailDTO asks for a token to be mailed to Email.
+type EmailDTO struct {
+	Email string `json:"email"`
+}
+
+// TokenDTO carries a mailed token back; Password is the new password when
+// confirming a password reset.
+type TokenDTO struct {
+	Token    string `json:"token"`
+	Password string `json:"password,omitempty"`
 }
 
 type OrderDTO struct {
@@ -21,14 +42,207 @@ type OrderDTO struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Status    Status    `json:"status"`
+	// Currency is what the order is placed and paid in; the default currency
+	// is used when it is not given.
+	Currency string         `json:"currency"`
+	Items    []OrderItemDTO `json:"items"`
+	Total    domain.Money   `json:"total"`
+	// ShippingAddressID and BillingAddressID select address book entries when
+	// placing an order; responses carry the copies in ShippingAddress and
+	// BillingAddress instead.
+	ShippingAddressID string      `json:"shipping_address_id,omitempty"`
+	BillingAddressID  string      `json:"billing_address_id,omitempty"`
+	ShippingAddress   *AddressDTO `json:"shipping_address,omitempty"`
+	BillingAddress    *AddressDTO `json:"billing_address,omitempty"`
+}
+
+type OrderItemDTO struct {
+	ProductID string `json:"product_id"`
+	// VariantID is required for products that have variants.
+	VariantID string       `json:"variant_id,omitempty"`
+	Quantity  int          `json:"quantity"`
+	Price     domain.Money `json:"price"`
+	// Allocations are the warehouses the line is shipped from.
+	Allocations []StockAllocationDTO `json:"allocations,omitempty"`
+}
+
+type StockAllocationDTO struct {
+	WarehouseID string `json:"warehouse_id"`
+	Quantity    int    `json:"quantity"`
+}
+
+type AddressDTO struct {
+	ID         string     `json:"id"`
+	Recipient  string     `json:"recipient"`
+	Line1      string     `json:"line1"`
+	Line2      string     `json:"line2,omitempty"`
+	City       string     `json:"city"`
+	Region     string     `json:"region,omitempty"`
+	PostalCode string     `json:"postal_code"`
+	Country    string     `json:"country"`
+	Phone      string     `json:"phone,omitempty"`
+	IsDefault  bool       `json:"is_default"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+type ShipmentDTO struct {
+	ID             string            `json:"id"`
+	OrderID        string            `json:"order_id"`
+	Carrier        string            `json:"carrier"`
+	TrackingNumber string            `json:"tracking_number"`
+	Items          []ShipmentItemDTO `json:"items"`
+	ShippedAt      time.Time         `json:"shipped_at"`
+	DeliveredAt    *time.Time        `json:"delivered_at,omitempty"`
+}
+
+type ShipmentItemDTO struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+type ReturnDTO struct {
+	ID           string                  `json:"id"`
+	OrderID      string                  `json:"order_id"`
+	UserID       string                  `json:"user_id"`
+	Status       string                  `json:"status"`
+	Items        []ReturnItemDTO         `json:"items"`
+	Comment      string                  `json:"comment,omitempty"`
+	RefundAmount domain.Money            `json:"refund_amount"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+	History      []ReturnStatusChangeDTO `json:"history"`
+}
+
+type ReturnItemDTO struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
+	Reason    string `json:"reason"`
+}
+
+type ReturnStatusChangeDTO struct {
+	From string    `json:"from,omitempty"`
+	To   string    `json:"to"`
+	Note string    `json:"note,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// NoteDTO is the optional staff note sent with a return status change.
+type NoteDTO struct {
+	Note string `json:"note"`
+}
+
+// StockAdjustmentDTO is a manual correction of a product amount. Its actor is
+// the admin making the request.
+type StockAdjustmentDTO struct {
+	WarehouseID string `json:"warehouse_id"`
+	VariantID   string `json:"variant_id"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason"`
+}
+
+type InventoryMovementDTO struct {
+	ID          int64     `json:"id"`
+	ProductID   string    `json:"product_id"`
+	VariantID   string    `json:"variant_id,omitempty"`
+	WarehouseID string    `json:"warehouse_id,omitempty"`
+	Type        string    `json:"type"`
+	Quantity    int       `json:"quantity"`
+	Reason      string    `json:"reason"`
+	Actor       string    `json:"actor"`
+	Reference   string    `json:"reference,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type StockDiscrepancyDTO struct {
+	ProductID    string `json:"product_id"`
+	Amount       int    `json:"amount"`
+	LedgerSum    int    `json:"ledger_sum"`
+	WarehouseSum int    `json:"warehouse_sum"`
+	Difference   int    `json:"difference"`
+}
+
+type JobRunDTO struct {
+	Name       string    `json:"name"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Processed  int       `json:"processed"`
+	Error      string    `json:"error,omitempty"`
 }
 
 type ProductDTO struct {
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	Price  int    `json:"price"`
-	SKU    string `json:"sku"`
-	Amount int    `json:"amount"`
+	ID    string       `json:"id"`
+	Name  string       `json:"name"`
+	Price domain.Money `json:"price"`
+	// Prices are what the product costs in other currencies.
+	Prices []domain.Money `json:"prices,omitempty"`
+	SKU    string         `json:"sku"`
+	// Amount is the total available in all warehouses.
+	Amount int                 `json:"amount"`
+	Stock  []WarehouseStockDTO `json:"stock,omitempty"`
+	// ReorderPoint is the amount at or below which the product is reported
+	// low on stock.
+	ReorderPoint    int     `json:"reorder_point"`
+	ReorderQuantity int     `json:"reorder_quantity"`
+	SupplierID      *string `json:"supplier_id"`
+	// Variants are the versions the product is sold in.
+	Variants []VariantDTO `json:"variants,omitempty"`
+	// Images is the gallery of the product, in display order.
+	Images []ProductImageDTO `json:"images,omitempty"`
+}
+
+type VariantDTO struct {
+	ID      string            `json:"id"`
+	SKU     string            `json:"sku"`
+	Options map[string]string `json:"options"`
+	// Price overrides the base price of the product and is in its currency;
+	// null sells at the product prices.
+	Price     *domain.Money `json:"price"`
+	Amount    int           `json:"amount"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// PriceDTO is what a product costs in the currency named by the path.
+type PriceDTO struct {
+	Amount int64 `json:"amount" binding:"required"`
+}
+
+// ProductImageDTO is an image in the gallery of a product. URLs maps every
+// rendition, the original and its thumbnails, to where it is served.
+type ProductImageDTO struct {
+	ID          string            `json:"id"`
+	Position    int               `json:"position"`
+	ContentType string            `json:"content_type"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Size        int64             `json:"size"`
+	URLs        map[string]string `json:"urls"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ImageOrderDTO is the new order of the gallery of a product.
+type ImageOrderDTO struct {
+	ImageIDs []string `json:"image_ids" binding:"required"`
+}
+
+// ReorderLineDTO is a product to reorder and where to buy it.
+type ReorderLineDTO struct {
+	Product  ProductDTO   `json:"product"`
+	Supplier *SupplierDTO `json:"supplier"`
+}
+
+type WarehouseStockDTO struct {
+	WarehouseID string `json:"warehouse_id"`
+	Amount      int    `json:"amount"`
+}
+
+type WarehouseDTO struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Country   string    `json:"country"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type CategoryDTO struct {
@@ -41,3 +255,437 @@ type SupplierDTO struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
+
+type WebhookDTO struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryDTO struct {
+	ID             string     `json:"id"`
+	WebhookID      string     `json:"webhook_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	RedeliveryOf   *string    `json:"redelivery_of,omitempty"`
+}
+
+type CheckDTO struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ReadinessDTO struct {
+	Status string              `json:"status"`
+	Checks map[string]CheckDTO `json:"checks"`
+}
+
+type VersionDTO struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// newUserDTO maps a user to its API representation. The password hash is never exposed.
+func newUserDTO(user *domain.User) UserDTO {
+	return UserDTO{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Number:        user.Number,
+		Address:       user.Address,
+		Balance:       user.Balance,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+}
+
+func (dto UserDTO) toDomain() *domain.User {
+	return &domain.User{
+		ID:        dto.ID,
+		Name:      dto.Name,
+		Email:     dto.Email,
+		Password:  dto.Password,
+		Number:    dto.Number,
+		Address:   dto.Address,
+		CreatedAt: dto.CreatedAt,
+		UpdatedAt: dto.UpdatedAt,
+	}
+}
+
+// newOrderDTO maps an order for a response. It fails if the total of the
+// order cannot be computed, which is only possible for a broken order.
+func newOrderDTO(order *domain.Order) (OrderDTO, error) {
+	total, err := order.Total()
+	if err != nil {
+		return OrderDTO{}, err
+	}
+
+	items := make([]OrderItemDTO, 0, len(order.Items))
+	for _, item := range order.Items {
+		var allocations []StockAllocationDTO
+		for _, a := range item.Allocations {
+			allocations = append(allocations, StockAllocationDTO{WarehouseID: a.WarehouseID, Quantity: a.Quantity})
+		}
+		items = append(items, OrderItemDTO{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+			Allocations: allocations,
+		})
+	}
+
+	dto := OrderDTO{
+		ID:        order.ID,
+		UserID:    order.UserID,
+		CreatedAt: order.CreatedAt,
+		UpdatedAt: order.UpdatedAt,
+		Status:    Status(order.Status),
+		Currency:  string(order.Currency),
+		Items:     items,
+		Total:     total,
+	}
+	if order.ShippingAddress != nil {
+		shipping := newOrderAddressDTO(order.ShippingAddress)
+		dto.ShippingAddress = &shipping
+	}
+	if order.BillingAddress != nil {
+		billing := newOrderAddressDTO(order.BillingAddress)
+		dto.BillingAddress = &billing
+	}
+	return dto, nil
+}
+
+// toDomain maps an order request. Item prices and the total are always computed
+// by the service and ignored here.
+func (dto OrderDTO) toDomain() *domain.Order {
+	items := make([]domain.OrderItem, 0, len(dto.Items))
+	for _, item := range dto.Items {
+		items = append(items, domain.OrderItem{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	order := &domain.Order{
+		ID:        dto.ID,
+		UserID:    dto.UserID,
+		CreatedAt: dto.CreatedAt,
+		UpdatedAt: dto.UpdatedAt,
+		Status:    domain.Status(dto.Status),
+		Currency:  domain.Currency(strings.ToUpper(strings.TrimSpace(dto.Currency))),
+		Items:     items,
+	}
+	if dto.ShippingAddressID != "" {
+		order.ShippingAddress = &domain.Address{ID: dto.ShippingAddressID}
+	}
+	if dto.BillingAddressID != "" {
+		order.BillingAddress = &domain.Address{ID: dto.BillingAddressID}
+	}
+	return order
+}
+
+func newAddressDTO(address *domain.Address) AddressDTO {
+	dto := newOrderAddressDTO(address)
+	dto.IsDefault = address.IsDefault
+	dto.CreatedAt = &address.CreatedAt
+	dto.UpdatedAt = &address.UpdatedAt
+	return dto
+}
+
+// newOrderAddressDTO maps the copy of an address kept by an order, which has
+// no default flag or timestamps of its own.
+func newOrderAddressDTO(address *domain.Address) AddressDTO {
+	return AddressDTO{
+		ID:         address.ID,
+		Recipient:  address.Recipient,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		Phone:      address.Phone,
+	}
+}
+
+func (dto AddressDTO) toDomain() *domain.Address {
+	return &domain.Address{
+		Recipient:  dto.Recipient,
+		Line1:      dto.Line1,
+		Line2:      dto.Line2,
+		City:       dto.City,
+		Region:     dto.Region,
+		PostalCode: dto.PostalCode,
+		Country:    dto.Country,
+		Phone:      dto.Phone,
+		IsDefault:  dto.IsDefault,
+	}
+}
+
+func newShipmentDTO(shipment *domain.Shipment) ShipmentDTO {
+	items := make([]ShipmentItemDTO, 0, len(shipment.Items))
+	for _, item := range shipment.Items {
+		items = append(items, ShipmentItemDTO(item))
+	}
+	return ShipmentDTO{
+		ID:             shipment.ID,
+		OrderID:        shipment.OrderID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		Items:          items,
+		ShippedAt:      shipment.ShippedAt,
+		DeliveredAt:    shipment.DeliveredAt,
+	}
+}
+
+// toDomain maps a shipment request; the IDs and timestamps are set by the service.
+func (dto ShipmentDTO) toDomain() *domain.Shipment {
+	items := make([]domain.ShipmentItem, 0, len(dto.Items))
+	for _, item := range dto.Items {
+		items = append(items, domain.ShipmentItem(item))
+	}
+	return &domain.Shipment{
+		Carrier:        dto.Carrier,
+		TrackingNumber: dto.TrackingNumber,
+		Items:          items,
+	}
+}
+
+func newReturnDTO(ret *domain.Return) ReturnDTO {
+	items := make([]ReturnItemDTO, 0, len(ret.Items))
+	for _, item := range ret.Items {
+		items = append(items, ReturnItemDTO{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Reason:    string(item.Reason),
+		})
+	}
+	history := make([]ReturnStatusChangeDTO, 0, len(ret.History))
+	for _, change := range ret.History {
+		history = append(history, ReturnStatusChangeDTO{
+			From: string(change.From),
+			To:   string(change.To),
+			Note: change.Note,
+			At:   change.At,
+		})
+	}
+	return ReturnDTO{
+		ID:           ret.ID,
+		OrderID:      ret.OrderID,
+		UserID:       ret.UserID,
+		Status:       string(ret.Status),
+		Items:        items,
+		Comment:      ret.Comment,
+		RefundAmount: ret.RefundAmount,
+		CreatedAt:    ret.CreatedAt,
+		UpdatedAt:    ret.UpdatedAt,
+		History:      history,
+	}
+}
+
+// toDomain maps a return request; the status, refund and history are set by the service.
+func (dto ReturnDTO) toDomain() *domain.Return {
+	items := make([]domain.ReturnItem, 0, len(dto.Items))
+	for _, item := range dto.Items {
+		items = append(items, domain.ReturnItem{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Reason:    domain.ReturnReason(item.Reason),
+		})
+	}
+	return &domain.Return{
+		Items:   items,
+		Comment: dto.Comment,
+	}
+}
+
+func newInventoryMovementDTO(m *domain.InventoryMovement) InventoryMovementDTO {
+	return InventoryMovementDTO{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		VariantID:   m.VariantID,
+		WarehouseID: m.WarehouseID,
+		Type:        string(m.Type),
+		Quantity:    m.Quantity,
+		Reason:      m.Reason,
+		Actor:       m.Actor,
+		Reference:   m.Reference,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func newStockDiscrepancyDTO(d domain.StockDiscrepancy) StockDiscrepancyDTO {
+	return StockDiscrepancyDTO{
+		ProductID:    d.ProductID,
+		Amount:       d.Amount,
+		LedgerSum:    d.LedgerSum,
+		WarehouseSum: d.WarehouseSum,
+		Difference:   d.Difference(),
+	}
+}
+
+func newJobRunDTO(run *domain.JobRun) JobRunDTO {
+	return JobRunDTO{
+		Name:       run.Name,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Processed:  run.Processed,
+		Error:      run.Error,
+	}
+}
+
+func newProductDTO(product *domain.Product) ProductDTO {
+	var stock []WarehouseStockDTO
+	for _, s := range product.Stock {
+		stock = append(stock, WarehouseStockDTO{WarehouseID: s.WarehouseID, Amount: s.Amount})
+	}
+	var variants []VariantDTO
+	for i := range product.Variants {
+		variants = append(variants, newVariantDTO(&product.Variants[i]))
+	}
+	var images []ProductImageDTO
+	for i := range product.Images {
+		images = append(images, newProductImageDTO(&product.Images[i]))
+	}
+	return ProductDTO{
+		ID:     product.ID,
+		Name:   product.Name,
+		Price:  product.Price,
+		Prices: product.Prices,
+		SKU:    product.SKU,
+		Amount: product.Amount,
+		Stock:  stock,
+
+		ReorderPoint:    product.ReorderPoint,
+		ReorderQuantity: product.ReorderQuantity,
+		SupplierID:      product.SupplierID,
+		Variants:        variants,
+		Images:          images,
+	}
+}
+
+func newVariantDTO(variant *domain.Variant) VariantDTO {
+	return VariantDTO{
+		ID:        variant.ID,
+		SKU:       variant.SKU,
+		Options:   variant.Options,
+		Price:     variant.Price,
+		Amount:    variant.Amount,
+		CreatedAt: variant.CreatedAt,
+	}
+}
+
+func newProductImageDTO(img *domain.ProductImage) ProductImageDTO {
+	urls := map[string]string{string(domain.ImageOriginal): productImageURL(img, domain.ImageOriginal)}
+	for size := range domain.ThumbnailSizes {
+		urls[string(size)] = productImageURL(img, size)
+	}
+	return ProductImageDTO{
+		ID:          img.ID,
+		Position:    img.Position,
+		ContentType: img.ContentType,
+		Width:       img.Width,
+		Height:      img.Height,
+		Size:        img.Size,
+		URLs:        urls,
+		CreatedAt:   img.CreatedAt,
+	}
+}
+
+func productImageURL(img *domain.ProductImage, size domain.ImageSize) string {
+	return "/api/v1/products/" + img.ProductID + "/images/" + img.ID + "/" + string(size)
+}
+
+func newReorderLineDTO(line domain.ReorderLine) ReorderLineDTO {
+	dto := ReorderLineDTO{Product: newProductDTO(line.Product)}
+	if line.Supplier != nil {
+		dto.Supplier = &SupplierDTO{ID: line.Supplier.ID, Name: line.Supplier.Name}
+	}
+	return dto
+}
+
+func newWarehouseDTO(warehouse *domain.Warehouse) WarehouseDTO {
+	return WarehouseDTO{
+		ID:        warehouse.ID,
+		Name:      warehouse.Name,
+		Country:   warehouse.Country,
+		CreatedAt: warehouse.CreatedAt,
+	}
+}
+
+func (dto ProductDTO) toDomain() *domain.Product {
+	return &domain.Product{
+		ID:     dto.ID,
+		Name:   dto.Name,
+		Price:  dto.Price,
+		SKU:    dto.SKU,
+		Amount: dto.Amount,
+
+		ReorderPoint:    dto.ReorderPoint,
+		ReorderQuantity: dto.ReorderQuantity,
+		SupplierID:      dto.SupplierID,
+	}
+}
+
+// newWebhookDTO maps a subscription to its API representation. The secret is
+// only exposed when the subscription is created.
+func newWebhookDTO(sub *domain.WebhookSubscription) WebhookDTO {
+	types := make([]string, 0, len(sub.EventTypes))
+	for _, t := range sub.EventTypes {
+		types = append(types, string(t))
+	}
+	return WebhookDTO{
+		ID:         sub.ID,
+		URL:        sub.URL,
+		EventTypes: types,
+		CreatedAt:  sub.CreatedAt,
+	}
+}
+
+func (dto WebhookDTO) toDomain() *domain.WebhookSubscription {
+	types := make([]domain.EventType, 0, len(dto.EventTypes))
+	for _, t := range dto.EventTypes {
+		types = append(types, domain.EventType(t))
+	}
+	return &domain.WebhookSubscription{
+		URL:        dto.URL,
+		EventTypes: types,
+		Secret:     dto.Secret,
+	}
+}
+
+func newWebhookDeliveryDTO(d *domain.WebhookDelivery) WebhookDeliveryDTO {
+	dto := WebhookDeliveryDTO{
+		ID:             d.ID,
+		WebhookID:      d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+		RedeliveryOf:   d.RedeliveryOf,
+	}
+	if d.Status == domain.DeliveryPending {
+		next := d.NextAttemptAt
+		dto.NextAttemptAt = &next
+	}
+	return dto
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errMissingIfMatch = errors.New("If-Match header is required")
+	errInvalidIfMatch = errors.New("If-Match header must be an ETag returned by the API")
+	errIfMatchFailed  = errors.New("If-Match header matches no current version")
+)
+
+// versionLookup returns the stored version of the resource being modified.
+type versionLookup func(ctx context.Context) (int, error)
+
+// setETag exposes the version of the returned resource as a strong ETag.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ifMatchVersion returns the resource version the client expects to modify,
+// taken from the If-Match header. "*" matches whatever version is stored and
+// yields domain.AnyVersion. Tags are compared strongly (RFC 9110 §13.1.1), so
+// weak tags never match. When the header lists several versions, current is
+// asked for the stored one, which is returned if it is listed.
+func ifMatchVersion(c *gin.Context, current versionLookup) (int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, errMissingIfMatch
+	}
+	if header == "*" {
+		return domain.AnyVersion, nil
+	}
+
+	var versions []int
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		weak := strings.HasPrefix(tag, "W/")
+		version, err := parseETag(strings.TrimPrefix(tag, "W/"))
+		if err != nil {
+			return 0, err
+		}
+		if !weak && !slices.Contains(versions, version) {
+			versions = append(versions, version)
+		}
+	}
+
+	switch len(versions) {
+	case 0:
+		return 0, errIfMatchFailed
+	case 1:
+		return versions[0], nil
+	}
+	version, err := current(c.Request.Context())
+	if err != nil {
+		return 0, err
+	}
+	if !slices.Contains(versions, version) {
+		return 0, errIfMatchFailed
+	}
+	return version, nil
+}
+
+// parseETag returns the version an opaque tag set by setETag stands for.
+func parseETag(tag string) (int, error) {
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil || !strings.HasPrefix(tag, `"`) {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 0 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
+// ifMatchStatus maps an ifMatchVersion error to a response status. A resource
+// that does not exist matches no version.
+func ifMatchStatus(err error) int {
+	switch {
+	case errors.Is(err, errMissingIfMatch):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, errInvalidIfMatch):
+		return http.StatusBadRequest
+	case errors.Is(err, errIfMatchFailed),
+		errors.Is(err, domain.ErrorUserNotFound),
+		errors.Is(err, domain.ErrorProductNotFound),
+		errors.Is(err, domain.ErrorOrderNotFound):
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
+func (s *Server) userVersion(id string) versionLookup {
+	return func(ctx context.Context) (int, error) {
+		user, err := s.service.GetUserByID(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		return user.Version, nil
+	}
+}
+
+func (s *Server) productVersion(id string) versionLookup {
+	return func(ctx context.Context) (int, error) {
+		product, err := s.service.GetProductByID(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		return product.Version, nil
+	}
+}
+
+func (s *Server) orderVersion(id string) versionLookup {
+	return func(ctx context.Context) (int, error) {
+		order, err := s.service.GetOrderByID(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		return order.Version, nil
+	}
+}
@@ -0,0 +1,197 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_UpdateOrderIfMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		ifMatch      string
+		svc          server.Service
+		expectedCode int
+		expectedETag string
+	}{
+		{
+			name:    "success",
+			ifMatch: `"3"`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order) error {
+					assert.Equal(t, 3, order.Version)
+					order.Version = 4
+					return nil
+				})
+				return s
+			}(),
+			expectedCode: http.StatusOK,
+			expectedETag: `"4"`,
+		},
+		{
+			name:         "weak etag never matches",
+			ifMatch:      `W/"3"`,
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "list with a single strong etag",
+			ifMatch: `W/"3", "3"`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order) error {
+					assert.Equal(t, 3, order.Version)
+					order.Version = 4
+					return nil
+				})
+				return s
+			}(),
+			expectedCode: http.StatusOK,
+			expectedETag: `"4"`,
+		},
+		{
+			name:    "list matching the stored version",
+			ifMatch: `"2", "3"`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().GetOrderByID(gomock.Any(), "123").Return(&domain.Order{ID: "123", Version: 3}, nil)
+				s.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order) error {
+					assert.Equal(t, 3, order.Version)
+					order.Version = 4
+					return nil
+				})
+				return s
+			}(),
+			expectedCode: http.StatusOK,
+			expectedETag: `"4"`,
+		},
+		{
+			name:    "list missing the stored version",
+			ifMatch: `"1","2"`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().GetOrderByID(gomock.Any(), "123").Return(&domain.Order{ID: "123", Version: 3}, nil)
+				return s
+			}(),
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "list for a missing order",
+			ifMatch: `"1", "2"`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().GetOrderByID(gomock.Any(), "123").Return(nil, domain.ErrorOrderNotFound)
+				return s
+			}(),
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "list with a malformed etag",
+			ifMatch:      `"1", 2`,
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing If-Match",
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusPreconditionRequired,
+		},
+		{
+			name:    "any version",
+			ifMatch: "*",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order) error {
+					assert.Equal(t, domain.AnyVersion, order.Version)
+					order.Version = 8
+					return nil
+				})
+				return s
+			}(),
+			expectedCode: http.StatusOK,
+			expectedETag: `"8"`,
+		},
+		{
+			name:         "malformed If-Match",
+			ifMatch:      "3",
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative version",
+			ifMatch:      `"-1"`,
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:    "stale version",
+			ifMatch: `"2"`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(domain.ErrorVersionConflict)
+				return s
+			}(),
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "status change not allowed",
+			ifMatch: `"3"`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(domain.ErrorOrderTransition)
+				return s
+			}(),
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := server.NewServer(tt.svc)
+			r := s.SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("PUT", "/api/v1/orders/123", bytes.NewBufferString(`{"status":"delivery"}`))
+			assert.NoError(t, err)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestServer_GetUserByIDETag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", Version: 7}, nil)
+
+	s := server.NewServer(svc)
+	r := s.SetupRouter()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/users/u1", nil)
+	assert.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+}
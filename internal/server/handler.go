@@ -4,16 +4,14 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/aibekfatkhulla/shop/internal/domain"
 	"github.com/gin-gonic/gin"
 )
 
 func (s *Server) CreateUserHandler(c *gin.Context) {
-	var user UserDTO
-
-	if err := c.ShouldBind(&user); err != nil {
+	user, err := bindUserDTO(c, c.ShouldBind)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -23,19 +21,9 @@ func (s *Server) CreateUserHandler(c *gin.Context) {
 		return
 	}
 
-	err := s.service.CreateUser(c, &domain.User{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Password:  user.Password,
-		Number:    user.Number,
-		Address:   user.Address,
-		Balance:   user.Balance,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	})
+	err = s.service.CreateUser(c.Request.Context(), user.toDomain())
 	if err != nil {
-		if err == domain.ErrorUserAlreadyExists {
+		if errors.Is(err, domain.ErrorUserAlreadyExists) {
 			c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -50,37 +38,157 @@ func (s *Server) UpdateUserHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing id"})
 		return
 	}
-	var user UserDTO
-	if err := c.ShouldBind(&user); err != nil {
+	version, err := ifMatchVersion(c, s.userVersion(id))
+	if err != nil {
+		c.JSON(ifMatchStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := bindUserDTO(c, c.ShouldBindJSON)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	user.ID = id
+	updated := user.toDomain()
+	updated.Version = version
+
+	if err := s.service.UpdateUser(c.Request.Context(), updated); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrorUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	setETag(c, updated.Version)
+	c.JSON(http.StatusOK, userView(c, updated))
+}
+
+// PatchUserHandler applies a JSON Merge Patch to a user; fields missing from
+// the patch keep their values.
+func (s *Server) PatchUserHandler(c *gin.Context) {
+	version, err := ifMatchVersion(c, s.userVersion(c.Param("id")))
+	if err != nil {
+		c.JSON(ifMatchStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var dto UserDTO
+	mask, err := bindMergePatch(c, &dto)
+	if err != nil {
+		c.JSON(patchBindStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	user := dto.toDomain()
+	user.ID = c.Param("id")
+	user.Version = version
+
+	if err := s.service.PatchUser(c.Request.Context(), user, mask); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrorUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorInvalidPatch):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
 
-	if err := s.service.UpdateUser(c.Request.Context(), (*domain.User)(&user)); err != nil {
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, newUserDTO(user))
+}
+
+func (s *Server) GetUserByIDHandler(c *gin.Context) {
+	id := c.Param("id")
+	user, err := s.service.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrorUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, newUserDTO(user))
 }
 
 func (s *Server) ListUsersHandler(c *gin.Context) {
 	users, err := s.service.ListUsers(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(200, users)
+
+	dtos := make([]any, 0, len(users))
+	for _, user := range users {
+		dtos = append(dtos, userView(c, user))
+	}
+	c.JSON(http.StatusOK, dtos)
 }
 
 func (s *Server) GetProductByIDHandler(c *gin.Context) {
 	id := c.Param("id")
 	product, err := s.service.GetProductByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		if errors.Is(err, domain.ErrorProductNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-	c.JSON(http.StatusOK, product)
+
+	setETag(c, product.Version)
+	c.JSON(http.StatusOK, productView(c, product))
+}
+
+// PatchProductHandler applies a JSON Merge Patch to a product.
+func (s *Server) PatchProductHandler(c *gin.Context) {
+	version, err := ifMatchVersion(c, s.productVersion(c.Param("id")))
+	if err != nil {
+		c.JSON(ifMatchStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var dto ProductDTO
+	mask, err := bindMergePatch(c, &dto)
+	if err != nil {
+		c.JSON(patchBindStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	product := dto.toDomain()
+	product.ID = c.Param("id")
+	product.Version = version
+
+	if err := s.service.PatchProduct(c.Request.Context(), product, mask); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrorProductNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorInvalidPatch):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	setETag(c, product.Version)
+	c.JSON(http.StatusOK, newProductDTO(product))
 }
 
 func (s *Server) ListProductsHandler(c *gin.Context) {
@@ -99,28 +207,44 @@ func (s *Server) ListProductsHandler(c *gin.Context) {
 
 	products, err := s.service.ListProducts(c.Request.Context(), limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]any, 0, len(products))
+	for _, product := range products {
+		dtos = append(dtos, productView(c, product))
 	}
-	c.JSON(http.StatusOK, products)
+	c.JSON(http.StatusOK, dtos)
 }
 
 func (s *Server) CreateOrderHandler(c *gin.Context) {
-	var order domain.Order
-	if err := c.ShouldBind(&order); err != nil {
+	dto, err := bindOrderDTO(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	now := time.Now()
-	order.CreatedAt = now
-	if order.Status == "" {
-		order.Status = domain.StatusPending
-	}
-	if err := s.service.CreateOrder(c.Request.Context(), &order); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+	order := dto.toDomain()
+	if err := s.service.CreateOrder(c.Request.Context(), order); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrorOrderHasNoItems), errors.Is(err, domain.ErrorInvalidQuantity), errors.Is(err, domain.ErrorInvalidVariant), errors.Is(err, domain.ErrorInvalidCurrency):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorProductNotFound), errors.Is(err, domain.ErrorVariantNotFound), errors.Is(err, domain.ErrorUserNotFound), errors.Is(err, domain.ErrorAddressNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorEmailNotVerified):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorInsufficientStock):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorPriceUnavailable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
-	c.JSON(http.StatusCreated, order)
 
+	writeOrder(c, http.StatusCreated, order)
 }
 
 func (s *Server) UpdateOrderHandler(c *gin.Context) {
@@ -130,29 +254,132 @@ func (s *Server) UpdateOrderHandler(c *gin.Context) {
 		return
 	}
 
-	var order domain.Order
-	if err := c.ShouldBind(&order); err != nil {
+	version, err := ifMatchVersion(c, s.orderVersion(id))
+	if err != nil {
+		c.JSON(ifMatchStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	dto, err := bindOrderDTO(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	order.ID = id
+	dto.ID = id
+	order := dto.toDomain()
+	order.Version = version
 
-	if err := s.service.UpdateOrder(c.Request.Context(), &order); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := s.service.UpdateOrder(c.Request.Context(), order); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrorOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorInvalidStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorOrderTransition), errors.Is(err, domain.ErrorOrderNotPending), errors.Is(err, domain.ErrorCurrencyMismatch):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorInsufficientBalance):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
-	c.JSON(http.StatusOK, order)
+
+	writeOrder(c, http.StatusOK, order)
+}
+
+// PatchOrderHandler applies a JSON Merge Patch to an order.
+func (s *Server) PatchOrderHandler(c *gin.Context) {
+	version, err := ifMatchVersion(c, s.orderVersion(c.Param("id")))
+	if err != nil {
+		c.JSON(ifMatchStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	var dto OrderDTO
+	mask, err := bindMergePatch(c, &dto)
+	if err != nil {
+		c.JSON(patchBindStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	order := dto.toDomain()
+	order.ID = c.Param("id")
+	order.Version = version
+
+	if err := s.service.PatchOrder(c.Request.Context(), order, mask); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrorOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorInvalidPatch):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorOrderTransition), errors.Is(err, domain.ErrorOrderNotPending), errors.Is(err, domain.ErrorCurrencyMismatch):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorInsufficientBalance):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	writeOrder(c, http.StatusOK, order)
 }
 
 func (s *Server) GetOrderByIDHandler(c *gin.Context) {
 	id := c.Param("id")
 	order, err := s.service.GetOrderByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		if errors.Is(err, domain.ErrorOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeOrder(c, http.StatusOK, order)
+}
+
+func (s *Server) PayOrderHandler(c *gin.Context) {
+	id := c.Param("id")
+	order, err := s.service.PayOrder(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrorOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorOrderNotPending), errors.Is(err, domain.ErrorCurrencyMismatch):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrorInsufficientBalance):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	writeOrder(c, http.StatusOK, order)
+}
+
+// writeOrder answers with order, in the legacy shape on legacy routes, and
+// its version as ETag.
+func writeOrder(c *gin.Context, code int, order *domain.Order) {
+	dto, err := newOrderDTO(order)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	setETag(c, order.Version)
+	if isLegacy(c) {
+		c.JSON(code, newLegacyOrderDTO(dto))
 		return
 	}
-	c.JSON(http.StatusOK, order)
+	c.JSON(code, dto)
 }
 
 func (s *Server) AddProductToCategoryHandler(c *gin.Context) {
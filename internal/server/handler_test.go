@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/aibekfatkhulla/shop/internal/domain"
 	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
@@ -20,21 +21,14 @@ func TestServer_CreateUser(t *testing.T) {
 
 	tests := []struct {
 		name         string
-		user         server.UserDTO
+		body         string
 		svc          server.Service
 		expectedCode int
 		expectedBody []byte
 	}{
 		{
 			"success case",
-			server.UserDTO{
-				Name:     "arnur",
-				Password: "qwe",
-				Email:    "qwe@qwe.qwe",
-				Number:   "123",
-				Address:  "123",
-				Balance:  100,
-			},
+			`{"name":"arnur","password":"qwe","email":"qwe@qwe.qwe","number":"123","address":"123","balance":100}`,
 			func() server.Service {
 				s := internalMock.NewMockService(ctrl)
 				s.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
@@ -45,14 +39,7 @@ func TestServer_CreateUser(t *testing.T) {
 			nil,
 		}, {
 			"user already exists",
-			server.UserDTO{
-				Name:     "arnur",
-				Password: "qwe",
-				Email:    "qwe@qwe.qwe",
-				Number:   "123",
-				Address:  "123",
-				Balance:  100,
-			},
+			`{"name":"arnur","password":"qwe","email":"qwe@qwe.qwe","number":"123","address":"123","balance":100}`,
 			func() server.Service {
 				s := internalMock.NewMockService(ctrl)
 				s.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(domain.ErrorUserAlreadyExists)
@@ -61,7 +48,7 @@ func TestServer_CreateUser(t *testing.T) {
 			}(),
 			http.StatusConflict,
 			func() []byte {
-				b, err := json.Marshal(map[string]string{"error": domain.ErrorUserAlreadyExists.Error()})
+				b, err := json.Marshal(map[string]string{"error": "User already exists"})
 				assert.NoError(t, err)
 
 				return b
@@ -71,19 +58,17 @@ func TestServer_CreateUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			reqJSON, err := json.Marshal(tt.user)
-			assert.NoError(t, err)
-
 			s := server.NewServer(tt.svc)
 
 			r := s.SetupRouter()
 
 			w := httptest.NewRecorder()
 
-			body := bytes.NewBuffer(reqJSON)
+			body := bytes.NewBufferString(tt.body)
 
 			req, err := http.NewRequest("POST", "/users", body)
 			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
 
 			r.ServeHTTP(w, req)
 
@@ -333,3 +318,45 @@ func TestServer_DeleteSupplierByID(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_ListUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().ListUsers(gomock.Any()).Return([]*domain.User{{
+		ID:        "u1",
+		Name:      "arnur",
+		Email:     "qwe@qwe.qwe",
+		Password:  "hash",
+		Number:    "123",
+		Address:   "the capella",
+		Balance:   domain.NewMoney(100, "KZT"),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}}, nil)
+
+	s := server.NewServer(svc)
+	r := s.SetupRouter()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/users", nil)
+	assert.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{
+		"id":"u1",
+		"name":"arnur",
+		"email":"qwe@qwe.qwe",
+		"number":"123",
+		"address":"the capella",
+		"balance":100,
+		"email_verified":false,
+		"created_at":"2025-01-02T03:04:05Z",
+		"updated_at":"2025-01-02T03:04:05Z"
+	}]`, w.Body.String())
+}
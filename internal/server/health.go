@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/version"
+	"github.com/gin-gonic/gin"
+)
+
+const readinessTimeout = 2 * time.Second
+
+const (
+	checkStatusOK   = "ok"
+	checkStatusFail = "fail"
+)
+
+// HealthzHandler reports that the process is up and able to serve requests.
+func (s *Server) HealthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": checkStatusOK})
+}
+
+// ReadyzHandler reports whether every dependency the service needs is reachable.
+func (s *Server) ReadyzHandler(c *gin.Context) {
+	checks := map[string]func(ctx context.Context) error{
+		"postgres": s.service.Ping,
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	resp := ReadinessDTO{
+		Status: checkStatusOK,
+		Checks: make(map[string]CheckDTO, len(checks)),
+	}
+	code := http.StatusOK
+	for name, check := range checks {
+		if err := check(ctx); err != nil {
+			resp.Status = checkStatusFail
+			resp.Checks[name] = CheckDTO{Status: checkStatusFail, Error: err.Error()}
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[name] = CheckDTO{Status: checkStatusOK}
+	}
+
+	c.JSON(code, resp)
+}
+
+func (s *Server) VersionHandler(c *gin.Context) {
+	info := version.Get()
+	c.JSON(http.StatusOK, VersionDTO{
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime,
+		GoVersion: info.GoVersion,
+	})
+}
@@ -0,0 +1,96 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_Healthz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := server.NewServer(internalMock.NewMockService(ctrl))
+	r := s.SetupRouter()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/healthz", nil)
+	assert.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestServer_Readyz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		svc          server.Service
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "postgres reachable",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().Ping(gomock.Any()).Return(nil)
+				return s
+			}(),
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ok","checks":{"postgres":{"status":"ok"}}}`,
+		},
+		{
+			name: "postgres unreachable",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+				return s
+			}(),
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"fail","checks":{"postgres":{"status":"fail","error":"connection refused"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := server.NewServer(tt.svc)
+			r := s.SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/readyz", nil)
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestServer_Version(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := server.NewServer(internalMock.NewMockService(ctrl))
+	r := s.SetupRouter()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/version", nil)
+	assert.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"go_version":"go`)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// idempotencyMiddleware makes mutating requests carrying an Idempotency-Key
+// header safe to retry: the first response is stored and replayed for later
+// requests with the same key, and reusing a key for a different request is
+// rejected with 422. Keys are scoped per route and caller, see idempotencyScope.
+func (s *Server) idempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "idempotency key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentRequestBytes+1))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(body) > maxIdempotentRequestBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body is too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := s.service.ClaimIdempotencyKey(c.Request.Context(), &domain.IdempotencyKey{
+			Key:         key,
+			Scope:       idempotencyScope(c),
+			Fingerprint: requestFingerprint(c.Request, body),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrorIdempotencyKeyMismatch):
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, domain.ErrorIdempotencyKeyInProgress):
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		if record.Completed {
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		// The outcome must be stored even if the client has gone away.
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// Unless a response is stored, the key is released so that the request
+		// can be retried, also when a handler panics; otherwise the key would
+		// answer 409 until it expires.
+		stored := false
+		defer func() {
+			if stored {
+				return
+			}
+			if err := s.service.ReleaseIdempotencyKey(ctx, record.Key, record.Scope); err != nil {
+				slog.ErrorContext(ctx, "release idempotency key", "key", record.Key, "error", err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		stored = true
+		record.StatusCode = recorder.Status()
+		record.ContentType = recorder.Header().Get("Content-Type")
+		record.Body = recorder.body.Bytes()
+		if err := s.service.CompleteIdempotencyKey(ctx, record); err != nil {
+			slog.ErrorContext(ctx, "store idempotent response", "key", record.Key, "error", err)
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyScope is what a key is unique within: the method and the concrete
+// path, and for callers sending credentials a hash of their Authorization
+// header, so that they never see each other's responses. Anonymous callers
+// share the scope of the route; the IP address does not tell clients apart
+// behind a NAT and changes when a client retries from another network.
+func idempotencyScope(c *gin.Context) string {
+	scope := c.Request.Method + " " + c.Request.URL.Path
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		scope += " auth:" + hex.EncodeToString(sum[:8])
+	}
+	return scope
+}
+
+// requestFingerprint identifies the request a key was first used with.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies everything written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,230 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_Idempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const orderBody = `{"user_id":"999","items":[{"product_id":"p1","quantity":1}]}`
+
+	tests := []struct {
+		name             string
+		svc              server.Service
+		expectedCode     int
+		expectedBody     string
+		expectedReplayed string
+	}{
+		{
+			name: "first request is processed and stored",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+						assert.Equal(t, "k1", key.Key)
+						assert.Equal(t, "POST /api/v1/orders", key.Scope)
+						assert.NotEmpty(t, key.Fingerprint)
+						return key, nil
+					})
+				s.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order) error {
+					order.ID = "o1"
+					order.Status = domain.StatusPending
+					order.Currency = "KZT"
+					order.Items[0].Price = domain.NewMoney(10, "KZT")
+					return nil
+				})
+				s.EXPECT().CompleteIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, key *domain.IdempotencyKey) error {
+					assert.Equal(t, http.StatusCreated, key.StatusCode)
+					assert.Contains(t, string(key.Body), `"id":"o1"`)
+					return nil
+				})
+				return s
+			}(),
+			expectedCode: http.StatusCreated,
+			expectedBody: `{"id":"o1","user_id":"999","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","status":"pending","currency":"KZT","items":[{"product_id":"p1","quantity":1,"price":{"amount":10,"currency":"KZT"}}],"total":{"amount":10,"currency":"KZT"}}`,
+		},
+		{
+			name: "retry replays the stored response",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).Return(&domain.IdempotencyKey{
+					Key:         "k1",
+					Completed:   true,
+					StatusCode:  http.StatusCreated,
+					ContentType: "application/json; charset=utf-8",
+					Body:        []byte(`{"id":"o1"}`),
+				}, nil)
+				return s
+			}(),
+			expectedCode:     http.StatusCreated,
+			expectedBody:     `{"id":"o1"}`,
+			expectedReplayed: "true",
+		},
+		{
+			name: "key reused with a different payload",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).Return(nil, domain.ErrorIdempotencyKeyMismatch)
+				return s
+			}(),
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"error":"idempotency key was used with a different request"}`,
+		},
+		{
+			name: "failed request releases the key",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+						return key, nil
+					})
+				s.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(assert.AnError)
+				s.EXPECT().ReleaseIdempotencyKey(gomock.Any(), "k1", "POST /api/v1/orders").Return(nil)
+				return s
+			}(),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"` + assert.AnError.Error() + `"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := server.NewServer(tt.svc)
+			r := s.SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/orders", bytes.NewBufferString(orderBody))
+			assert.NoError(t, err)
+			req.Header.Set("Idempotency-Key", "k1")
+			req.RemoteAddr = "192.0.2.1:1234"
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectedReplayed, w.Header().Get("Idempotent-Replayed"))
+		})
+	}
+}
+
+func TestServer_IdempotencyScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		path          string
+		remoteAddr    string
+		authorization string
+		expectedScope string
+	}{
+		{
+			name:          "concrete path",
+			path:          "/api/v1/orders/o1/pay",
+			remoteAddr:    "192.0.2.1:1234",
+			expectedScope: "POST /api/v1/orders/o1/pay",
+		},
+		{
+			name:          "anonymous callers share the route",
+			path:          "/api/v1/orders/o1/pay",
+			remoteAddr:    "192.0.2.2:1234",
+			expectedScope: "POST /api/v1/orders/o1/pay",
+		},
+		{
+			name:          "authenticated caller",
+			path:          "/api/v1/orders/o2/pay",
+			remoteAddr:    "192.0.2.1:1234",
+			authorization: "Bearer secret",
+			expectedScope: "POST /api/v1/orders/o2/pay auth:bffde20413347b7a",
+		},
+		{
+			name:          "legacy route",
+			path:          "/orders",
+			remoteAddr:    "192.0.2.1:1234",
+			expectedScope: "POST /orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			svc.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ any, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+					assert.Equal(t, tt.expectedScope, key.Scope)
+					return nil, domain.ErrorIdempotencyKeyInProgress
+				})
+
+			r := server.NewServer(svc).SetupRouter()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", tt.path, nil)
+			req.Header.Set("Idempotency-Key", "k1")
+			req.RemoteAddr = tt.remoteAddr
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+		})
+	}
+}
+
+func TestServer_IdempotencyReleasesKeyOnPanic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+			return key, nil
+		})
+	svc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, _ *domain.Order) error {
+		panic("boom")
+	})
+	svc.EXPECT().ReleaseIdempotencyKey(gomock.Any(), "k1", "POST /api/v1/orders").Return(nil)
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBufferString(`{"user_id":"999","items":[{"product_id":"p1","quantity":1}]}`))
+	req.Header.Set("Idempotency-Key", "k1")
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestServer_IdempotencyFingerprintCoversQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var fingerprints []string
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().ClaimIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+			fingerprints = append(fingerprints, key.Fingerprint)
+			return nil, domain.ErrorIdempotencyKeyInProgress
+		}).Times(2)
+
+	r := server.NewServer(svc).SetupRouter()
+	for _, path := range []string{"/api/v1/orders/o1/pay?currency=USD", "/api/v1/orders/o1/pay?currency=EUR"} {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("Idempotency-Key", "k1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if assert.Len(t, fingerprints, 2) {
+		assert.NotEqual(t, fingerprints[0], fingerprints[1])
+	}
+}
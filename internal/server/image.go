@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// imageFormField is the multipart field an uploaded product image is sent in.
+const imageFormField = "image"
+
+// UploadProductImageHandler adds the image in the "image" field of a
+// multipart form to the end of the gallery of a product. The part is streamed
+// to the service rather than buffered as a form.
+func (s *Server) UploadProductImageHandler(c *gin.Context) {
+	form, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for {
+		part, err := form.NextPart()
+		if errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "the form has no " + imageFormField + " field"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if part.FormName() != imageFormField {
+			part.Close()
+			continue
+		}
+
+		img, err := s.service.UploadProductImage(c.Request.Context(), c.Param("id"), part)
+		part.Close()
+		if err != nil {
+			writeImageError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, newProductImageDTO(img))
+		return
+	}
+}
+
+func (s *Server) ListProductImagesHandler(c *gin.Context) {
+	images, err := s.service.ListProductImages(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeImageError(c, err)
+		return
+	}
+	dtos := make([]ProductImageDTO, 0, len(images))
+	for _, img := range images {
+		dtos = append(dtos, newProductImageDTO(img))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+// ReorderProductImagesHandler rearranges the gallery of a product. The body
+// lists every image of the product in the new order.
+func (s *Server) ReorderProductImagesHandler(c *gin.Context) {
+	var dto ImageOrderDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	images, err := s.service.ReorderProductImages(c.Request.Context(), c.Param("id"), dto.ImageIDs)
+	if err != nil {
+		writeImageError(c, err)
+		return
+	}
+	dtos := make([]ProductImageDTO, 0, len(images))
+	for _, img := range images {
+		dtos = append(dtos, newProductImageDTO(img))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+// GetProductImageHandler serves a rendition of a product image. Renditions
+// never change once uploaded, so they may be cached indefinitely.
+func (s *Server) GetProductImageHandler(c *gin.Context) {
+	size := domain.ImageSize(c.Param("size"))
+	img, r, err := s.service.OpenProductImage(c.Request.Context(), c.Param("id"), c.Param("imageID"), size)
+	if err != nil {
+		writeImageError(c, err)
+		return
+	}
+	defer r.Close()
+
+	c.Header("Content-Type", img.SizeContentType(size))
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	if size == domain.ImageOriginal {
+		c.Header("Content-Length", strconv.FormatInt(img.Size, 10))
+	}
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, r)
+}
+
+func (s *Server) DeleteProductImageHandler(c *gin.Context) {
+	if err := s.service.DeleteProductImage(c.Request.Context(), c.Param("id"), c.Param("imageID")); err != nil {
+		writeImageError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeImageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrorProductNotFound), errors.Is(err, domain.ErrorImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorInvalidImage):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
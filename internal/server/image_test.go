@@ -0,0 +1,234 @@
+package server_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_UploadProductImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusCreated},
+		{name: "unknown product", err: domain.ErrorProductNotFound, expectedCode: http.StatusNotFound},
+		{name: "not an image", err: domain.ErrorInvalidImage, expectedCode: http.StatusUnprocessableEntity},
+		{name: "too large", err: domain.ErrorImageTooLarge, expectedCode: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			svc.EXPECT().UploadProductImage(gomock.Any(), "p1", gomock.Any()).DoAndReturn(func(_ any, productID string, r io.Reader) (*domain.ProductImage, error) {
+				data, err := io.ReadAll(r)
+				assert.NoError(t, err)
+				assert.Equal(t, "image bytes", string(data))
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &domain.ProductImage{ID: "i1", ProductID: productID, ContentType: "image/png"}, nil
+			})
+
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			assert.NoError(t, form.WriteField("caption", "ignored"))
+			part, err := form.CreateFormFile("image", "shirt.png")
+			assert.NoError(t, err)
+			part.Write([]byte("image bytes"))
+			assert.NoError(t, form.Close())
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/products/p1/images", &body)
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			r.ServeHTTP(w, asAdmin(req))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+
+	t.Run("no image field", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		assert.NoError(t, form.WriteField("caption", "ignored"))
+		assert.NoError(t, form.Close())
+
+		r := server.NewServer(internalMock.NewMockService(ctrl), withTestAdmin).SetupRouter()
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/api/v1/products/p1/images", &body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		r.ServeHTTP(w, asAdmin(req))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("no admin credentials", func(t *testing.T) {
+		r := server.NewServer(internalMock.NewMockService(ctrl), withTestAdmin).SetupRouter()
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("POST", "/api/v1/products/p1/images", bytes.NewBufferString("image bytes"))
+		assert.NoError(t, err)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestServer_ListProductImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().ListProductImages(gomock.Any(), "p1").Return([]*domain.ProductImage{{
+		ID:          "i1",
+		ProductID:   "p1",
+		ContentType: "image/jpeg",
+		Width:       800,
+		Height:      600,
+		Size:        2048,
+		CreatedAt:   time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC),
+	}}, nil)
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/products/p1/images", nil)
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{
+		"id": "i1",
+		"position": 0,
+		"content_type": "image/jpeg",
+		"width": 800,
+		"height": 600,
+		"size": 2048,
+		"urls": {
+			"original": "/api/v1/products/p1/images/i1/original",
+			"small": "/api/v1/products/p1/images/i1/small",
+			"medium": "/api/v1/products/p1/images/i1/medium",
+			"large": "/api/v1/products/p1/images/i1/large"
+		},
+		"created_at": "2026-03-01T12:00:00Z"
+	}]`, w.Body.String())
+}
+
+func TestServer_ReorderProductImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		body         string
+		err          error
+		expectedCode int
+	}{
+		{name: "success", body: `{"image_ids":["i2","i1"]}`, expectedCode: http.StatusOK},
+		{name: "missing ids", body: `{}`, expectedCode: http.StatusBadRequest},
+		{name: "not every image", body: `{"image_ids":["i2","i1"]}`, err: domain.ErrorInvalidImage, expectedCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if tt.expectedCode != http.StatusBadRequest {
+				svc.EXPECT().ReorderProductImages(gomock.Any(), "p1", []string{"i2", "i1"}).Return(nil, tt.err)
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("PUT", "/api/v1/products/p1/images/order", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			r.ServeHTTP(w, asAdmin(req))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_GetProductImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("thumbnail of a gif is png", func(t *testing.T) {
+		svc := internalMock.NewMockService(ctrl)
+		svc.EXPECT().OpenProductImage(gomock.Any(), "p1", "i1", domain.ImageSmall).
+			Return(&domain.ProductImage{ID: "i1", ProductID: "p1", ContentType: "image/gif"}, io.NopCloser(bytes.NewBufferString("thumbnail")), nil)
+
+		r := server.NewServer(svc).SetupRouter()
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/api/v1/products/p1/images/i1/small", nil)
+		assert.NoError(t, err)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Cache-Control"), "immutable")
+		assert.Equal(t, "thumbnail", w.Body.String())
+	})
+
+	t.Run("unknown size", func(t *testing.T) {
+		svc := internalMock.NewMockService(ctrl)
+		svc.EXPECT().OpenProductImage(gomock.Any(), "p1", "i1", domain.ImageSize("huge")).Return(nil, nil, domain.ErrorImageNotFound)
+
+		r := server.NewServer(svc).SetupRouter()
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/api/v1/products/p1/images/i1/huge", nil)
+		assert.NoError(t, err)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestServer_DeleteProductImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		anonymous    bool
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusNoContent},
+		{name: "unknown image", err: domain.ErrorImageNotFound, expectedCode: http.StatusNotFound},
+		{name: "no admin credentials", anonymous: true, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if !tt.anonymous {
+				svc.EXPECT().DeleteProductImage(gomock.Any(), "p1", "i1").Return(tt.err)
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("DELETE", "/api/v1/products/p1/images/i1", nil)
+			assert.NoError(t, err)
+			if !tt.anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
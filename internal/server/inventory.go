@@ -0,0 +1,102 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) AdjustStockHandler(c *gin.Context) {
+	var dto StockAdjustmentDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m := &domain.InventoryMovement{
+		ProductID:   c.Param("id"),
+		VariantID:   dto.VariantID,
+		WarehouseID: dto.WarehouseID,
+		Quantity:    dto.Quantity,
+		Reason:      dto.Reason,
+		Actor:       adminName(c),
+	}
+	if err := s.service.AdjustStock(c.Request.Context(), m); err != nil {
+		writeInventoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newInventoryMovementDTO(m))
+}
+
+func (s *Server) ListInventoryMovementsHandler(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	movements, err := s.service.ListInventoryMovements(c.Request.Context(), c.Param("id"), limit, offset)
+	if err != nil {
+		writeInventoryError(c, err)
+		return
+	}
+
+	dtos := make([]InventoryMovementDTO, 0, len(movements))
+	for _, m := range movements {
+		dtos = append(dtos, newInventoryMovementDTO(m))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+// ListStockDiscrepanciesHandler reports the products whose amount does not
+// match their inventory ledger; an empty list means the ledger reconciles.
+func (s *Server) ListStockDiscrepanciesHandler(c *gin.Context) {
+	discrepancies, err := s.service.ListStockDiscrepancies(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]StockDiscrepancyDTO, 0, len(discrepancies))
+	for _, d := range discrepancies {
+		dtos = append(dtos, newStockDiscrepancyDTO(d))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+// ListReorderLinesHandler lists the products at or below their reorder point
+// with their suppliers.
+func (s *Server) ListReorderLinesHandler(c *gin.Context) {
+	lines, err := s.service.ListReorderLines(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]ReorderLineDTO, 0, len(lines))
+	for _, line := range lines {
+		dtos = append(dtos, newReorderLineDTO(line))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+func writeInventoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrorProductNotFound), errors.Is(err, domain.ErrorWarehouseNotFound), errors.Is(err, domain.ErrorVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorInvalidStockAdjustment):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
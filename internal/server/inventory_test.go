@@ -0,0 +1,101 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_AdjustStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		anonymous    bool
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusCreated},
+		{name: "no admin credentials", anonymous: true, expectedCode: http.StatusUnauthorized},
+		{name: "unknown product", err: domain.ErrorProductNotFound, expectedCode: http.StatusNotFound},
+		{name: "unknown warehouse", err: domain.ErrorWarehouseNotFound, expectedCode: http.StatusNotFound},
+		{name: "negative stock", err: domain.ErrorInsufficientStock, expectedCode: http.StatusConflict},
+		{name: "no reason", err: domain.ErrorInvalidStockAdjustment, expectedCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if !tt.anonymous {
+				svc.EXPECT().AdjustStock(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, m *domain.InventoryMovement) error {
+					assert.Equal(t, &domain.InventoryMovement{ProductID: "p1", WarehouseID: "main", Quantity: -3, Reason: "broken", Actor: "ops"}, m)
+					return tt.err
+				})
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/products/p1/stock-adjustments",
+				bytes.NewBufferString(`{"warehouse_id":"main","quantity":-3,"reason":"broken","actor":"jane"}`))
+			assert.NoError(t, err)
+			if !tt.anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_ListStockDiscrepancies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().ListStockDiscrepancies(gomock.Any()).Return([]domain.StockDiscrepancy{{ProductID: "p1", Amount: 5, LedgerSum: 7, WarehouseSum: 5}}, nil)
+
+	r := server.NewServer(svc, withTestAdmin).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/admin/inventory/discrepancies", nil)
+	assert.NoError(t, err)
+	r.ServeHTTP(w, asAdmin(req))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"product_id":"p1","amount":5,"ledger_sum":7,"warehouse_sum":5,"difference":-2}]`, w.Body.String())
+}
+
+func TestServer_ListReorderLines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	supplierID := "s1"
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().ListReorderLines(gomock.Any()).Return([]domain.ReorderLine{
+		{
+			Product:  &domain.Product{ID: "p1", Name: "Tea", Price: domain.NewMoney(10, "KZT"), Amount: 2, ReorderPoint: 5, ReorderQuantity: 40, SupplierID: &supplierID},
+			Supplier: &domain.Supplier{ID: "s1", Name: "Green Leaf"},
+		},
+		{Product: &domain.Product{ID: "p2", Name: "Mug", Price: domain.NewMoney(20, "KZT"), ReorderPoint: 1}},
+	}, nil)
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/products/reorder", nil)
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[
+		{"product":{"id":"p1","name":"Tea","price":{"amount":10,"currency":"KZT"},"sku":"","amount":2,"reorder_point":5,"reorder_quantity":40,"supplier_id":"s1"},"supplier":{"id":"s1","name":"Green Leaf"}},
+		{"product":{"id":"p2","name":"Mug","price":{"amount":20,"currency":"KZT"},"sku":"","amount":0,"reorder_point":1,"reorder_quantity":0,"supplier_id":null},"supplier":null}
+	]`, w.Body.String())
+}
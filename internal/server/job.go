@@ -0,0 +1,22 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListJobRunsHandler reports the last run of the background jobs.
+func (s *Server) ListJobRunsHandler(c *gin.Context) {
+	runs, err := s.service.ListJobRuns(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]JobRunDTO, 0, len(runs))
+	for _, run := range runs {
+		dtos = append(dtos, newJobRunDTO(run))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
@@ -0,0 +1,37 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_ListJobRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().ListJobRuns(gomock.Any()).Return([]*domain.JobRun{{
+		Name:       domain.JobCancelStaleOrders,
+		StartedAt:  started,
+		FinishedAt: started.Add(time.Second),
+		Processed:  2,
+	}}, nil)
+
+	r := server.NewServer(svc, withTestAdmin).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/admin/jobs", nil)
+	assert.NoError(t, err)
+	r.ServeHTTP(w, asAdmin(req))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"name":"cancel_stale_orders","started_at":"2025-01-02T03:04:05Z","finished_at":"2025-01-02T03:04:06Z","processed":2}]`, w.Body.String())
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// legacyKey is the gin context key marking requests made to the unversioned
+// routes, which keep the shapes they had before amounts carried a currency.
+const legacyKey = "legacy"
+
+// legacyShapes marks the requests of the legacy routes so that users,
+// products and orders are read and written in their legacy shape.
+func legacyShapes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(legacyKey, true)
+		c.Next()
+	}
+}
+
+func isLegacy(c *gin.Context) bool {
+	return c.GetBool(legacyKey)
+}
+
+// The legacy shapes carry prices, balances and totals as plain integers: the
+// amount in minor units of its currency, as stored. Legacy clients know no
+// other currency than the default one; amounts in others are only told apart
+// on /api/v1. The fields shadow those of the embedded DTOs when encoding and
+// decoding.
+
+type legacyUserDTO struct {
+	UserDTO
+	Balance int64 `json:"balance"`
+}
+
+type legacyProductDTO struct {
+	ProductDTO
+	Price    int64              `json:"price"`
+	Variants []legacyVariantDTO `json:"variants,omitempty"`
+}
+
+type legacyVariantDTO struct {
+	VariantDTO
+	Price *int64 `json:"price"`
+}
+
+type legacyOrderDTO struct {
+	OrderDTO
+	Items []legacyOrderItemDTO `json:"items"`
+	Total int64                `json:"total"`
+}
+
+type legacyOrderItemDTO struct {
+	OrderItemDTO
+	Price int64 `json:"price"`
+}
+
+func newLegacyUserDTO(user *domain.User) legacyUserDTO {
+	return legacyUserDTO{UserDTO: newUserDTO(user), Balance: user.Balance.Amount}
+}
+
+// newLegacyProductDTO maps product without its prices in other currencies,
+// which the legacy shape has no room for.
+func newLegacyProductDTO(product *domain.Product) legacyProductDTO {
+	dto := legacyProductDTO{ProductDTO: newProductDTO(product), Price: product.Price.Amount}
+	dto.Prices = nil
+	for i := range product.Variants {
+		variant := legacyVariantDTO{VariantDTO: newVariantDTO(&product.Variants[i])}
+		if price := product.Variants[i].Price; price != nil {
+			variant.Price = &price.Amount
+		}
+		dto.Variants = append(dto.Variants, variant)
+	}
+	return dto
+}
+
+func newLegacyOrderDTO(order OrderDTO) legacyOrderDTO {
+	items := make([]legacyOrderItemDTO, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, legacyOrderItemDTO{OrderItemDTO: item, Price: item.Price.Amount})
+	}
+	return legacyOrderDTO{OrderDTO: order, Items: items, Total: order.Total.Amount}
+}
+
+// userView returns the shape user is answered with on the route of c.
+func userView(c *gin.Context, user *domain.User) any {
+	if isLegacy(c) {
+		return newLegacyUserDTO(user)
+	}
+	return newUserDTO(user)
+}
+
+// productView returns the shape product is answered with on the route of c.
+func productView(c *gin.Context, product *domain.Product) any {
+	if isLegacy(c) {
+		return newLegacyProductDTO(product)
+	}
+	return newProductDTO(product)
+}
+
+// bindUserDTO binds the request body with bind, reading the legacy shape on
+// legacy routes. Amounts in requests are read-only, so they are dropped.
+func bindUserDTO(c *gin.Context, bind func(any) error) (UserDTO, error) {
+	if isLegacy(c) {
+		var dto legacyUserDTO
+		err := bind(&dto)
+		return dto.UserDTO, err
+	}
+	var dto UserDTO
+	err := bind(&dto)
+	return dto, err
+}
+
+// bindOrderDTO binds the JSON request body, reading the legacy shape on
+// legacy routes. Prices and totals in requests are computed, so they are
+// dropped.
+func bindOrderDTO(c *gin.Context) (OrderDTO, error) {
+	if !isLegacy(c) {
+		var dto OrderDTO
+		err := c.ShouldBindJSON(&dto)
+		return dto, err
+	}
+	var legacy legacyOrderDTO
+	if err := c.ShouldBindJSON(&legacy); err != nil {
+		return OrderDTO{}, err
+	}
+	dto := legacy.OrderDTO
+	dto.Items = make([]OrderItemDTO, 0, len(legacy.Items))
+	for _, item := range legacy.Items {
+		dto.Items = append(dto.Items, item.OrderItemDTO)
+	}
+	return dto, nil
+}
@@ -0,0 +1,105 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_LegacyAmounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	variantPrice := domain.NewMoney(1200, "KZT")
+	product := &domain.Product{
+		ID:       "p1",
+		Name:     "Tea",
+		Price:    domain.NewMoney(1000, "KZT"),
+		Prices:   []domain.Money{domain.NewMoney(250, "USD")},
+		Variants: []domain.Variant{{ID: "v1", Price: &variantPrice}},
+	}
+	order := &domain.Order{ID: "o1", Status: domain.StatusPending, Currency: "KZT", Version: 3, Items: []domain.OrderItem{
+		{ProductID: "p1", Quantity: 2, Price: domain.NewMoney(1000, "KZT")},
+	}}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected map[string]string
+	}{
+		{name: "legacy product", path: "/products/p1", expected: map[string]string{"price": `1000`, "prices": `null`}},
+		{name: "current product", path: "/api/v1/products/p1", expected: map[string]string{
+			"price":  `{"amount":1000,"currency":"KZT"}`,
+			"prices": `[{"amount":250,"currency":"USD"}]`,
+		}},
+		{name: "legacy order", path: "/orders/o1", expected: map[string]string{"total": `2000`}},
+		{name: "current order", path: "/api/v1/orders/o1", expected: map[string]string{"total": `{"amount":2000,"currency":"KZT"}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			svc.EXPECT().GetProductByID(gomock.Any(), "p1").Return(product, nil).AnyTimes()
+			svc.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order, nil).AnyTimes()
+
+			r := server.NewServer(svc).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.path, nil)
+			assert.NoError(t, err)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var body map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			for field, expected := range tt.expected {
+				actual := string(body[field])
+				if actual == "" {
+					actual = "null"
+				}
+				assert.JSONEq(t, expected, actual, field)
+			}
+		})
+	}
+}
+
+func TestServer_LegacyUpdateOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order) error {
+		assert.Equal(t, domain.StatusPaid, order.Status)
+		order.Currency = "KZT"
+		order.Items = []domain.OrderItem{{ProductID: "p1", Quantity: 2, Price: domain.NewMoney(1000, "KZT")}}
+		return nil
+	})
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "/orders/o1",
+		bytes.NewBufferString(`{"status":"paid","items":[{"product_id":"p1","quantity":2,"price":1000}],"total":2000}`))
+	assert.NoError(t, err)
+	req.Header.Set("If-Match", "*")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Total int64 `json:"total"`
+		Items []struct {
+			Price int64 `json:"price"`
+		} `json:"items"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(2000), body.Total)
+	if assert.Len(t, body.Items, 1) {
+		assert.Equal(t, int64(1000), body.Items[0].Price)
+	}
+}
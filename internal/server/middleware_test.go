@@ -0,0 +1,111 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := server.NewServer(internalMock.NewMockService(ctrl))
+	r := s.SetupRouter()
+
+	for _, path := range []string{"/healthz", "/no-such-route"} {
+		req, err := http.NewRequest("GET", path, nil)
+		assert.NoError(t, err)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/metrics", nil)
+	assert.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `shop_http_requests_total{method="GET",route="/healthz",status="200"}`)
+	assert.Contains(t, w.Body.String(), `shop_http_requests_total{method="GET",route="unmatched",status="404"}`)
+	assert.Contains(t, w.Body.String(), `shop_http_request_duration_seconds_bucket{method="GET",route="/healthz"`)
+}
+
+func TestServer_RequestID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name      string
+		requestID string
+	}{
+		{
+			name:      "propagates incoming id",
+			requestID: "req-123",
+		},
+		{
+			name:      "generates missing id",
+			requestID: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := server.NewServer(internalMock.NewMockService(ctrl))
+			r := s.SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/healthz", nil)
+			assert.NoError(t, err)
+			if tt.requestID != "" {
+				req.Header.Set("X-Request-ID", tt.requestID)
+			}
+
+			r.ServeHTTP(w, req)
+
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, w.Header().Get("X-Request-ID"))
+			} else {
+				assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+			}
+		})
+	}
+}
+
+func TestServer_TracePropagation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	s := server.NewServer(internalMock.NewMockService(ctrl))
+	r := s.SetupRouter()
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/healthz", nil)
+	assert.NoError(t, err)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	r.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "GET /healthz", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+}
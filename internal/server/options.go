@@ -0,0 +1,40 @@
+package server
+
+import "github.com/aibekfatkhulla/shop/internal/ratelimit"
+
+// Option configures optional behaviour of the server.
+type Option func(*Server)
+
+// RateLimits are the per-client limits of each route group. A zero limit
+// leaves the group unlimited.
+type RateLimits struct {
+	// API applies to every /api/v1 and legacy API route.
+	API ratelimit.Limit
+	// Signup additionally applies to account creation and to requests that
+	// mail account tokens.
+	Signup ratelimit.Limit
+}
+
+// WithRateLimits enables rate limiting, keeping the token buckets in store.
+func WithRateLimits(store ratelimit.Store, limits RateLimits) Option {
+	return func(s *Server) {
+		s.rateLimitStore = store
+		s.rateLimits = limits
+	}
+}
+
+// WithTrustedProxies sets the proxies (IPs or CIDRs) whose X-Forwarded-For
+// header is believed when determining the client IP. By default no proxy is
+// trusted.
+func WithTrustedProxies(proxies []string) Option {
+	return func(s *Server) {
+		s.trustedProxies = proxies
+	}
+}
+
+// WithAdmins lets the given staff call admin routes. By default nobody can.
+func WithAdmins(admins Admins) Option {
+	return func(s *Server) {
+		s.admins = admins
+	}
+}
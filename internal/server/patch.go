@@ -0,0 +1,59 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var (
+	errUnsupportedPatchType = errors.New("patch must be sent as " + mergePatchContentType)
+	errPatchNotObject       = errors.New("patch must be a JSON object")
+)
+
+// bindMergePatch decodes a JSON Merge Patch (RFC 7396) request body into dst,
+// a DTO, and returns the members it sets as a field mask. Members set to null
+// decode to the zero value, i.e. they clear the field.
+func bindMergePatch(c *gin.Context, dst any) (domain.FieldMask, error) {
+	contentType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || (contentType != mergePatchContentType && contentType != gin.MIMEJSON) {
+		return nil, errUnsupportedPatchType
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return nil, errPatchNotObject
+	}
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(dst); err != nil {
+		return nil, err
+	}
+
+	mask := make(domain.FieldMask, 0, len(members))
+	for field := range members {
+		mask = append(mask, field)
+	}
+	slices.Sort(mask)
+	return mask, nil
+}
+
+// patchBindStatus maps a bindMergePatch error to a response status.
+func patchBindStatus(err error) int {
+	if errors.Is(err, errUnsupportedPatchType) {
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
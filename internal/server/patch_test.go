@@ -0,0 +1,154 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_PatchUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		contentType  string
+		ifMatch      string
+		body         string
+		svc          server.Service
+		expectedCode int
+	}{
+		{
+			name:        "null clears, missing keeps",
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"2"`,
+			body:        `{"address":null,"number":"555"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().PatchUser(gomock.Any(), gomock.Any(), domain.FieldMask{"address", "number"}).
+					DoAndReturn(func(_ any, user *domain.User, _ domain.FieldMask) error {
+						assert.Equal(t, "123", user.ID)
+						assert.Equal(t, 2, user.Version)
+						assert.Equal(t, "", user.Address)
+						assert.Equal(t, "555", user.Number)
+						user.Version = 3
+						return nil
+					})
+				return s
+			}(),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unsupported content type",
+			contentType:  "text/plain",
+			ifMatch:      `"2"`,
+			body:         `{"name":"x"}`,
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:         "not an object",
+			contentType:  "application/merge-patch+json",
+			ifMatch:      `"2"`,
+			body:         `["name"]`,
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing If-Match",
+			contentType:  "application/merge-patch+json",
+			body:         `{"name":"x"}`,
+			svc:          internalMock.NewMockService(ctrl),
+			expectedCode: http.StatusPreconditionRequired,
+		},
+		{
+			name:        "read-only field",
+			contentType: "application/json",
+			ifMatch:     `"2"`,
+			body:        `{"balance":{"amount":1000,"currency":"KZT"}}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().PatchUser(gomock.Any(), gomock.Any(), domain.FieldMask{"balance"}).Return(domain.ErrorInvalidPatch)
+				return s
+			}(),
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "stale version",
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"1"`,
+			body:        `{"name":"x"}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().PatchUser(gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.ErrorVersionConflict)
+				return s
+			}(),
+			expectedCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := server.NewServer(tt.svc)
+			r := s.SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("PATCH", "/api/v1/users/123", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_PatchProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		anonymous    bool
+		expectedCode int
+	}{
+		{name: "admin", expectedCode: http.StatusOK},
+		{name: "no admin credentials", anonymous: true, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if !tt.anonymous {
+				svc.EXPECT().PatchProduct(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, product *domain.Product, _ any) error {
+					assert.Equal(t, domain.NewMoney(900, "KZT"), product.Price)
+					return nil
+				})
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("PATCH", "/api/v1/products/p1", bytes.NewBufferString(`{"price":{"amount":900,"currency":"KZT"}}`))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			req.Header.Set("If-Match", "*")
+			if !tt.anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// SetProductPriceHandler sets what a product costs in the currency named by
+// the path. The base price is changed by patching the product.
+func (s *Server) SetProductPriceHandler(c *gin.Context) {
+	currency, err := domain.ParseCurrency(c.Param("currency"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var dto PriceDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	price := domain.NewMoney(dto.Amount, currency)
+	if err := s.service.SetProductPrice(c.Request.Context(), c.Param("id"), price); err != nil {
+		writePriceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, price)
+}
+
+func (s *Server) DeleteProductPriceHandler(c *gin.Context) {
+	currency, err := domain.ParseCurrency(c.Param("currency"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.service.DeleteProductPrice(c.Request.Context(), c.Param("id"), currency); err != nil {
+		writePriceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writePriceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrorProductNotFound), errors.Is(err, domain.ErrorPriceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorInvalidPrice), errors.Is(err, domain.ErrorInvalidCurrency):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
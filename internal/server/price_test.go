@@ -0,0 +1,127 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_SetProductPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "success", path: "/api/v1/products/p1/prices/usd", body: `{"amount":1999}`, expectedCode: http.StatusOK, expectedBody: `{"amount":1999,"currency":"USD"}`},
+		{name: "unknown product", path: "/api/v1/products/p1/prices/USD", body: `{"amount":1999}`, err: domain.ErrorProductNotFound, expectedCode: http.StatusNotFound},
+		{name: "base currency", path: "/api/v1/products/p1/prices/USD", body: `{"amount":1999}`, err: domain.ErrorInvalidPrice, expectedCode: http.StatusUnprocessableEntity},
+		{name: "unsupported currency", path: "/api/v1/products/p1/prices/XYZ", body: `{"amount":1999}`, expectedCode: http.StatusBadRequest},
+		{name: "missing amount", path: "/api/v1/products/p1/prices/USD", body: `{}`, expectedCode: http.StatusBadRequest},
+		{name: "no admin credentials", path: "/api/v1/products/p1/prices/USD", body: `{"amount":1}`, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			anonymous := tt.expectedCode == http.StatusUnauthorized
+			if tt.expectedCode != http.StatusBadRequest && !anonymous {
+				svc.EXPECT().SetProductPrice(gomock.Any(), "p1", domain.NewMoney(1999, "USD")).Return(tt.err)
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("PUT", tt.path, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			if !anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_DeleteProductPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusNoContent},
+		{name: "not listed", err: domain.ErrorPriceNotFound, expectedCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			svc.EXPECT().DeleteProductPrice(gomock.Any(), "p1", domain.Currency("USD")).Return(tt.err)
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("DELETE", "/api/v1/products/p1/prices/USD", nil)
+			assert.NoError(t, err)
+			r.ServeHTTP(w, asAdmin(req))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_CreateOrderCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order) error {
+		assert.Equal(t, domain.Currency("USD"), order.Currency)
+		return domain.ErrorPriceUnavailable
+	})
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/api/v1/orders",
+		bytes.NewBufferString(`{"user_id":"999","currency":"usd","items":[{"product_id":"p1","quantity":1}]}`))
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestServer_GetOrderWithBrokenTotal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(&domain.Order{ID: "o1", Currency: "KZT", Items: []domain.OrderItem{
+		{ProductID: "p1", Quantity: 1, Price: domain.NewMoney(100, "USD")},
+	}}, nil)
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/orders/o1", nil)
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/metrics"
+	"github.com/aibekfatkhulla/shop/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitMiddleware allows each client limit requests to the routes of
+// group and answers 429 beyond that. Clients are identified by IP address
+// only: the API has no user authentication, so users behind a shared address
+// share a bucket. The
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers describe
+// the client's bucket; rejected requests also get Retry-After.
+func (s *Server) rateLimitMiddleware(group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.rateLimitStore == nil || !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		res, err := s.rateLimitStore.Take(c.Request.Context(), group+":"+rateLimitKey(c), limit)
+		if err != nil {
+			// Fail open: an unavailable store must not take the API down.
+			slog.ErrorContext(c.Request.Context(), "rate limit store", "group", group, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", seconds(res.Reset))
+
+		if !res.Allowed {
+			metrics.RateLimitedRequests.WithLabelValues(group).Inc()
+			c.Header("Retry-After", seconds(res.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitKey identifies the client a request is counted against.
+func rateLimitKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// seconds formats d as whole seconds, rounding up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,85 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/ratelimit"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_RateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().ListUsers(gomock.Any()).Return(nil, nil).Times(2)
+
+	s := server.NewServer(svc, server.WithRateLimits(ratelimit.NewMemoryStore(), server.RateLimits{
+		API: ratelimit.Limit{Burst: 2, Period: time.Minute},
+	}))
+	r := s.SetupRouter()
+
+	do := func(remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/users", nil)
+		req.RemoteAddr = remoteAddr
+		// Not a trusted proxy, so this must not change the client IP.
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+
+	w = do("192.0.2.1:1235")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = do("192.0.2.1:1236")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	// Probes are never limited and other clients have their own bucket.
+	svc.EXPECT().Ping(gomock.Any()).Return(nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+
+	svc.EXPECT().ListUsers(gomock.Any()).Return(nil, nil)
+	w = do("192.0.2.2:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServer_RateLimitSignup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+
+	s := server.NewServer(svc, server.WithRateLimits(ratelimit.NewMemoryStore(), server.RateLimits{
+		API:    ratelimit.Limit{Burst: 100, Period: time.Minute},
+		Signup: ratelimit.Limit{Burst: 1, Period: time.Hour},
+	}))
+	r := s.SetupRouter()
+
+	for _, code := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/v1/users", strings.NewReader(`{"name":"a","email":"a@b.c","password":"x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code)
+	}
+}
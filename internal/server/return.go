@@ -0,0 +1,81 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) CreateReturnHandler(c *gin.Context) {
+	var dto ReturnDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ret := dto.toDomain()
+	ret.OrderID = c.Param("id")
+	if err := s.service.CreateReturn(c.Request.Context(), ret); err != nil {
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newReturnDTO(ret))
+}
+
+func (s *Server) ListReturnsHandler(c *gin.Context) {
+	returns, err := s.service.ListReturns(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeReturnError(c, err)
+		return
+	}
+
+	dtos := make([]ReturnDTO, 0, len(returns))
+	for _, ret := range returns {
+		dtos = append(dtos, newReturnDTO(ret))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+func (s *Server) GetReturnHandler(c *gin.Context) {
+	ret, err := s.service.GetReturn(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeReturnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newReturnDTO(ret))
+}
+
+// ReturnStatusHandler returns a handler that moves a return to status. The
+// request body, an optional note, may be empty.
+func (s *Server) ReturnStatusHandler(status domain.ReturnStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var dto NoteDTO
+		if err := c.ShouldBindJSON(&dto); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ret, err := s.service.UpdateReturnStatus(c.Request.Context(), c.Param("id"), status, dto.Note)
+		if err != nil {
+			writeReturnError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, newReturnDTO(ret))
+	}
+}
+
+func writeReturnError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrorOrderNotFound), errors.Is(err, domain.ErrorReturnNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorOrderNotReturnable), errors.Is(err, domain.ErrorReturnTransition), errors.Is(err, domain.ErrorCurrencyMismatch):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorInvalidReturn), errors.Is(err, domain.ErrorInvalidQuantity):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,97 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_CreateReturn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusCreated},
+		{name: "unknown order", err: domain.ErrorOrderNotFound, expectedCode: http.StatusNotFound},
+		{name: "order not completed", err: domain.ErrorOrderNotReturnable, expectedCode: http.StatusConflict},
+		{name: "too many items", err: domain.ErrorInvalidReturn, expectedCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			svc.EXPECT().CreateReturn(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, ret *domain.Return) error {
+				assert.Equal(t, "o1", ret.OrderID)
+				assert.Equal(t, "box was crushed", ret.Comment)
+				assert.Equal(t, []domain.ReturnItem{{ProductID: "p1", Quantity: 1, Reason: domain.ReasonDamaged}}, ret.Items)
+				return tt.err
+			})
+
+			r := server.NewServer(svc).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/orders/o1/returns",
+				bytes.NewBufferString(`{"comment":"box was crushed","items":[{"product_id":"p1","quantity":1,"reason":"damaged"}]}`))
+			assert.NoError(t, err)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_ReturnStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		status       domain.ReturnStatus
+		note         string
+		anonymous    bool
+		err          error
+		expectedCode int
+	}{
+		{name: "approve", path: "approve", body: `{"note":"ok"}`, status: domain.ReturnApproved, note: "ok", expectedCode: http.StatusOK},
+		{name: "receive without a body", path: "receive", status: domain.ReturnReceived, expectedCode: http.StatusOK},
+		{name: "refund too early", path: "refund", status: domain.ReturnRefunded, err: domain.ErrorReturnTransition, expectedCode: http.StatusConflict},
+		{name: "unknown return", path: "reject", status: domain.ReturnRejected, err: domain.ErrorReturnNotFound, expectedCode: http.StatusNotFound},
+		{name: "refund without admin credentials", path: "refund", anonymous: true, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if !tt.anonymous {
+				var ret *domain.Return
+				if tt.err == nil {
+					ret = &domain.Return{ID: "r1", Status: tt.status}
+				}
+				svc.EXPECT().UpdateReturnStatus(gomock.Any(), "r1", tt.status, tt.note).Return(ret, tt.err)
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/returns/r1/"+tt.path, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			if !tt.anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
@@ -2,68 +2,291 @@ package server
 
 import (
 	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/aibekfatkhulla/shop/config"
 	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/ratelimit"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// tracingServiceName is the server name recorded on HTTP spans.
+const tracingServiceName = "shop"
+
+// Deprecation schedule of the unversioned routes replaced by /api/v1.
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
 )
 
 type Server struct {
 	service Service
 	router  *gin.Engine
+
+	rateLimitStore ratelimit.Store
+	rateLimits     RateLimits
+	trustedProxies []string
+	admins         Admins
 }
 
 //go:generate mockgen -source=server.go -destination=../mocks/service.go -package=mocks Service
 type Service interface {
+	Ping(ctx context.Context) error
+
 	CreateUser(ctx context.Context, user *domain.User) error
 	UpdateUser(ctx context.Context, user *domain.User) error
+	PatchUser(ctx context.Context, user *domain.User, mask domain.FieldMask) error
+	GetUserByID(ctx context.Context, ID string) (*domain.User, error)
 	ListUsers(ctx context.Context) ([]*domain.User, error)
+	CreateAddress(ctx context.Context, address *domain.Address) error
+	GetAddress(ctx context.Context, userID string, ID string) (*domain.Address, error)
+	ListAddresses(ctx context.Context, userID string) ([]*domain.Address, error)
+	UpdateAddress(ctx context.Context, address *domain.Address) error
+	DeleteAddress(ctx context.Context, userID string, ID string) error
+	RequestEmailVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
 	CreateOrder(ctx context.Context, order *domain.Order) error
 	UpdateOrder(ctx context.Context, order *domain.Order) error
+	PatchOrder(ctx context.Context, order *domain.Order, mask domain.FieldMask) error
 	GetOrderByID(ctx context.Context, ID string) (*domain.Order, error)
+	PayOrder(ctx context.Context, ID string) (*domain.Order, error)
+	CreateShipment(ctx context.Context, shipment *domain.Shipment) error
+	DeliverShipment(ctx context.Context, orderID string, shipmentID string) (*domain.Shipment, error)
+	ListShipments(ctx context.Context, orderID string) ([]*domain.Shipment, error)
+	CreateReturn(ctx context.Context, ret *domain.Return) error
+	UpdateReturnStatus(ctx context.Context, ID string, status domain.ReturnStatus, note string) (*domain.Return, error)
+	GetReturn(ctx context.Context, ID string) (*domain.Return, error)
+	ListReturns(ctx context.Context, orderID string) ([]*domain.Return, error)
+	CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error
+	ListWarehouses(ctx context.Context) ([]*domain.Warehouse, error)
+	AdjustStock(ctx context.Context, m *domain.InventoryMovement) error
+	ListInventoryMovements(ctx context.Context, productID string, limit, offset int) ([]*domain.InventoryMovement, error)
+	ListStockDiscrepancies(ctx context.Context) ([]domain.StockDiscrepancy, error)
+	ListReorderLines(ctx context.Context) ([]domain.ReorderLine, error)
 
 	GetProductByID(ctx context.Context, ID string) (*domain.Product, error)
 	ListProducts(ctx context.Context, limit int, offset int) ([]*domain.Product, error)
+	PatchProduct(ctx context.Context, product *domain.Product, mask domain.FieldMask) error
+	CreateVariant(ctx context.Context, variant *domain.Variant) error
+	SetProductPrice(ctx context.Context, productID string, price domain.Money) error
+	DeleteProductPrice(ctx context.Context, productID string, currency domain.Currency) error
+	UploadProductImage(ctx context.Context, productID string, r io.Reader) (*domain.ProductImage, error)
+	ListProductImages(ctx context.Context, productID string) ([]*domain.ProductImage, error)
+	OpenProductImage(ctx context.Context, productID, id string, size domain.ImageSize) (*domain.ProductImage, io.ReadCloser, error)
+	ReorderProductImages(ctx context.Context, productID string, ids []string) ([]*domain.ProductImage, error)
+	DeleteProductImage(ctx context.Context, productID, id string) error
 
 	AddProductToCategory(ctx context.Context, categoryID string, productID string) error
 	RemoveProductFromCategory(ctx context.Context, categoryID string, productID string) error
 
 	GetSupplierByID(ctx context.Context, ID string) (*domain.Supplier, error)
 	DeleteSupplierByID(ctx context.Context, ID string) error
+
+	ClaimIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error
+	ReleaseIdempotencyKey(ctx context.Context, key string, scope string) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+
+	DispatchEvents(ctx context.Context) (int, error)
+
+	CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error
+	GetWebhookSubscription(ctx context.Context, ID string) (*domain.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, ID string) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID string) ([]*domain.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, subscriptionID string, deliveryID string) (*domain.WebhookDelivery, error)
+	DeliverWebhooks(ctx context.Context) (int, error)
+	CancelStaleOrders(ctx context.Context) (int, error)
+	CheckLowStock(ctx context.Context) (int, error)
+	ListJobRuns(ctx context.Context) ([]*domain.JobRun, error)
 }
 
-func (s *Server) Run(addr string) error {
-	router := s.SetupRouter()
-	return router.Run(addr)
+// Run serves the API until ctx is canceled, then stops accepting new
+// connections and waits up to cfg.ShutdownTimeout for in-flight requests.
+func (s *Server) Run(ctx context.Context, cfg config.Config) error {
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           s.SetupRouter(),
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+		MaxHeaderBytes:    cfg.HTTPMaxHeaderBytes,
+	}
 
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 func (s *Server) SetupRouter() *gin.Engine {
-	s.router = gin.Default()
+	s.router = gin.New()
+	if err := s.router.SetTrustedProxies(s.trustedProxies); err != nil {
+		slog.Error("invalid trusted proxies, trusting none", "error", err)
+		_ = s.router.SetTrustedProxies(nil)
+	}
+	s.router.Use(otelgin.Middleware(tracingServiceName), requestIDMiddleware(), loggerMiddleware(), recoveryMiddleware(), metricsMiddleware())
+
+	// Probes
+	s.router.GET("/healthz", s.HealthzHandler)
+	s.router.GET("/readyz", s.ReadyzHandler)
+	s.router.GET("/version", s.VersionHandler)
+	s.router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Docs
+	s.router.GET("/openapi.json", s.OpenAPIHandler)
+	s.router.GET("/docs", s.DocsHandler)
+
+	apiLimit := s.rateLimitMiddleware("api", s.rateLimits.API)
+	s.registerV1Routes(s.router.Group("/api/v1", apiLimit, s.idempotencyMiddleware()))
+	s.registerLegacyRoutes(s.router.Group("/", apiLimit, deprecatedMiddleware(legacyDeprecatedAt, legacySunsetAt, legacySuccessor), legacyShapes(), s.idempotencyMiddleware()))
+
+	return s.router
+}
+
+func (s *Server) registerV1Routes(api *gin.RouterGroup) {
 	// Users
-	s.router.POST("/users", s.CreateUserHandler)
-	s.router.PUT("/users/:id", s.UpdateUserHandler)
-	s.router.GET("/users", s.ListUsersHandler)
+	api.POST("/users", s.rateLimitMiddleware("signup", s.rateLimits.Signup), s.CreateUserHandler)
+	api.PUT("/users/:id", s.UpdateUserHandler)
+	api.PATCH("/users/:id", s.PatchUserHandler)
+	api.GET("/users/:id", s.GetUserByIDHandler)
+	api.GET("/users", s.ListUsersHandler)
+	api.POST("/users/:id/addresses", s.CreateAddressHandler)
+	api.GET("/users/:id/addresses", s.ListAddressesHandler)
+	api.GET("/users/:id/addresses/:addressID", s.GetAddressHandler)
+	api.PUT("/users/:id/addresses/:addressID", s.UpdateAddressHandler)
+	api.DELETE("/users/:id/addresses/:addressID", s.DeleteAddressHandler)
+
+	// Account tokens; mailing them is limited like signups.
+	authLimit := s.rateLimitMiddleware("auth", s.rateLimits.Signup)
+	api.POST("/auth/verify-email/request", authLimit, s.RequestEmailVerificationHandler)
+	api.POST("/auth/verify-email", s.VerifyEmailHandler)
+	api.POST("/auth/password-reset/request", authLimit, s.RequestPasswordResetHandler)
+	api.POST("/auth/password-reset/confirm", s.ConfirmPasswordResetHandler)
 
 	// Products
-	s.router.GET("/products/:id", s.GetProductByIDHandler)
-	s.router.GET("/products", s.ListProductsHandler)
+	api.GET("/products/:id", s.GetProductByIDHandler)
+	api.PATCH("/products/:id", s.requireAdmin(), s.PatchProductHandler)
+	api.GET("/products", s.ListProductsHandler)
+	api.GET("/products/reorder", s.ListReorderLinesHandler)
+	api.POST("/products/:id/variants", s.requireAdmin(), s.CreateVariantHandler)
+	api.PUT("/products/:id/prices/:currency", s.requireAdmin(), s.SetProductPriceHandler)
+	api.DELETE("/products/:id/prices/:currency", s.requireAdmin(), s.DeleteProductPriceHandler)
+	api.POST("/products/:id/images", s.requireAdmin(), s.UploadProductImageHandler)
+	api.GET("/products/:id/images", s.ListProductImagesHandler)
+	api.PUT("/products/:id/images/order", s.requireAdmin(), s.ReorderProductImagesHandler)
+	api.GET("/products/:id/images/:imageID/:size", s.GetProductImageHandler)
+	api.DELETE("/products/:id/images/:imageID", s.requireAdmin(), s.DeleteProductImageHandler)
+	api.POST("/products/:id/stock-adjustments", s.requireAdmin(), s.AdjustStockHandler)
+	api.GET("/products/:id/inventory-movements", s.ListInventoryMovementsHandler)
 
 	// Orders
-	s.router.POST("/orders", s.CreateOrderHandler)
-	s.router.PUT("/orders/:id", s.UpdateOrderHandler)
-	s.router.GET("/orders/:id", s.GetOrderByIDHandler)
+	api.POST("/orders", s.CreateOrderHandler)
+	api.PUT("/orders/:id", s.UpdateOrderHandler)
+	api.PATCH("/orders/:id", s.PatchOrderHandler)
+	api.GET("/orders/:id", s.GetOrderByIDHandler)
+	api.POST("/orders/:id/pay", s.PayOrderHandler)
+	api.POST("/orders/:id/shipments", s.requireAdmin(), s.CreateShipmentHandler)
+	api.GET("/orders/:id/shipments", s.ListShipmentsHandler)
+	api.POST("/orders/:id/shipments/:shipmentID/deliver", s.requireAdmin(), s.DeliverShipmentHandler)
+	api.POST("/orders/:id/returns", s.CreateReturnHandler)
+	api.GET("/orders/:id/returns", s.ListReturnsHandler)
+
+	// Returns; only staff move them through the workflow, as a refund credits the user's balance.
+	api.GET("/returns/:id", s.GetReturnHandler)
+	returns := api.Group("/returns/:id", s.requireAdmin())
+	returns.POST("/approve", s.ReturnStatusHandler(domain.ReturnApproved))
+	returns.POST("/reject", s.ReturnStatusHandler(domain.ReturnRejected))
+	returns.POST("/receive", s.ReturnStatusHandler(domain.ReturnReceived))
+	returns.POST("/refund", s.ReturnStatusHandler(domain.ReturnRefunded))
 
 	// Categories
-	s.router.POST("/categories/:id/products/:productID", s.AddProductToCategoryHandler)
-	s.router.DELETE("/categories/:id/products/:productID", s.RemoveProductFromCategoryHandler)
+	api.POST("/categories/:id/products/:productID", s.AddProductToCategoryHandler)
+	api.DELETE("/categories/:id/products/:productID", s.RemoveProductFromCategoryHandler)
 
 	// Suppliers
-	s.router.GET("supplier/:id", s.GetSupplierByIDHandler)
-	s.router.DELETE("supplier/:id", s.DeleteSupplierByIDHandler)
+	api.GET("/suppliers/:id", s.GetSupplierByIDHandler)
+	api.DELETE("/suppliers/:id", s.DeleteSupplierByIDHandler)
 
-	return s.router
+	// Webhooks; subscriptions make the server call out, so only admins manage them.
+	webhooks := api.Group("/webhooks", s.requireAdmin())
+	webhooks.POST("", s.CreateWebhookHandler)
+	webhooks.GET("", s.ListWebhooksHandler)
+	webhooks.GET("/:id", s.GetWebhookHandler)
+	webhooks.DELETE("/:id", s.DeleteWebhookHandler)
+	webhooks.GET("/:id/deliveries", s.ListWebhookDeliveriesHandler)
+	webhooks.POST("/:id/deliveries/:deliveryID/redeliver", s.RedeliverWebhookHandler)
+
+	// Warehouses
+	api.POST("/warehouses", s.requireAdmin(), s.CreateWarehouseHandler)
+	api.GET("/warehouses", s.ListWarehousesHandler)
+
+	// Admin
+	admin := api.Group("/admin", s.requireAdmin())
+	admin.GET("/jobs", s.ListJobRunsHandler)
+	admin.GET("/inventory/discrepancies", s.ListStockDiscrepanciesHandler)
+}
+
+// registerLegacyRoutes keeps the unversioned paths served before /api/v1 existed.
+// They are deprecated and will be removed after legacySunsetAt; do not add new routes here.
+func (s *Server) registerLegacyRoutes(legacy *gin.RouterGroup) {
+	legacy.POST("/users", s.rateLimitMiddleware("signup", s.rateLimits.Signup), s.CreateUserHandler)
+	legacy.PUT("/users/:id", s.UpdateUserHandler)
+	legacy.GET("/users", s.ListUsersHandler)
+
+	legacy.GET("/products/:id", s.GetProductByIDHandler)
+	legacy.GET("/products", s.ListProductsHandler)
+
+	legacy.POST("/orders", s.CreateOrderHandler)
+	legacy.PUT("/orders/:id", s.UpdateOrderHandler)
+	legacy.GET("/orders/:id", s.GetOrderByIDHandler)
+
+	legacy.POST("/categories/:id/products/:productID", s.AddProductToCategoryHandler)
+	legacy.DELETE("/categories/:id/products/:productID", s.RemoveProductFromCategoryHandler)
+
+	legacy.GET("/supplier/:id", s.GetSupplierByIDHandler)
+	legacy.DELETE("/supplier/:id", s.DeleteSupplierByIDHandler)
+}
+
+// legacySuccessor maps an unversioned path to its /api/v1 equivalent.
+func legacySuccessor(path string) string {
+	if rest, ok := strings.CutPrefix(path, "/supplier/"); ok {
+		return "/api/v1/suppliers/" + rest
+	}
+	return "/api/v1" + path
 }
 
-func NewServer(service Service) *Server {
-	return &Server{service: service}
+func NewServer(service Service, opts ...Option) *Server {
+	s := &Server{service: service}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
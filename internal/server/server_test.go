@@ -0,0 +1,145 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/config"
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_RunGracefulShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Run does not report the address it listens on, so a free port is
+	// reserved up front.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().GetSupplierByID(gomock.Any(), "sup123").DoAndReturn(func(context.Context, string) (*domain.Supplier, error) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		finished.Store(true)
+		return &domain.Supplier{ID: "sup123", Name: "Test Supplier"}, nil
+	})
+	s := server.NewServer(svc)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Run(ctx, config.Config{
+			HTTPAddr:        addr,
+			ShutdownTimeout: 5 * time.Second,
+		})
+	}()
+
+	codeCh := make(chan int, 1)
+	go func() {
+		// Retry until the server listens; only a served request gets a response.
+		for {
+			resp, err := http.Get("http://" + addr + "/api/v1/suppliers/sup123")
+			if err != nil {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			resp.Body.Close()
+			codeCh <- resp.StatusCode
+			return
+		}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not reach the handler")
+	}
+	cancel()
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+		assert.True(t, finished.Load(), "Run returned before the in-flight request completed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+	assert.Equal(t, http.StatusOK, <-codeCh)
+}
+
+func TestServer_RunListenError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := server.NewServer(internalMock.NewMockService(ctrl))
+
+	err := s.Run(t.Context(), config.Config{HTTPAddr: "invalid-addr"})
+	assert.Error(t, err)
+}
+
+func TestServer_VersionedRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name              string
+		path              string
+		deprecated        bool
+		expectedSuccessor string
+	}{
+		{
+			name: "v1 route",
+			path: "/api/v1/suppliers/sup123",
+		},
+		{
+			name:              "legacy alias",
+			path:              "/supplier/sup123",
+			deprecated:        true,
+			expectedSuccessor: `</api/v1/suppliers/sup123>; rel="successor-version"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			svc.EXPECT().
+				GetSupplierByID(gomock.Any(), "sup123").
+				Return(&domain.Supplier{ID: "sup123", Name: "Test Supplier"}, nil)
+
+			s := server.NewServer(svc)
+			r := s.SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.path, nil)
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, `{"id":"sup123","name":"Test Supplier"}`, w.Body.String())
+			if tt.deprecated {
+				assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+				assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+				assert.Equal(t, tt.expectedSuccessor, w.Header().Get("Link"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+				assert.Empty(t, w.Header().Get("Sunset"))
+			}
+		})
+	}
+}
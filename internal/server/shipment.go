@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateShipmentHandler records a parcel sent for an order. Without items it
+// ships everything not shipped yet.
+func (s *Server) CreateShipmentHandler(c *gin.Context) {
+	var dto ShipmentDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shipment := dto.toDomain()
+	shipment.OrderID = c.Param("id")
+	if err := s.service.CreateShipment(c.Request.Context(), shipment); err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newShipmentDTO(shipment))
+}
+
+func (s *Server) ListShipmentsHandler(c *gin.Context) {
+	shipments, err := s.service.ListShipments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+
+	dtos := make([]ShipmentDTO, 0, len(shipments))
+	for _, shipment := range shipments {
+		dtos = append(dtos, newShipmentDTO(shipment))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+func (s *Server) DeliverShipmentHandler(c *gin.Context) {
+	shipment, err := s.service.DeliverShipment(c.Request.Context(), c.Param("id"), c.Param("shipmentID"))
+	if err != nil {
+		writeShipmentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newShipmentDTO(shipment))
+}
+
+func writeShipmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrorOrderNotFound), errors.Is(err, domain.ErrorShipmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorOrderNotShippable), errors.Is(err, domain.ErrorVersionConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorInvalidShipment), errors.Is(err, domain.ErrorInvalidQuantity):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
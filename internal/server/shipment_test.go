@@ -0,0 +1,98 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_CreateShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		anonymous    bool
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusCreated},
+		{name: "no admin credentials", anonymous: true, expectedCode: http.StatusUnauthorized},
+		{name: "unknown order", err: domain.ErrorOrderNotFound, expectedCode: http.StatusNotFound},
+		{name: "unpaid order", err: domain.ErrorOrderNotShippable, expectedCode: http.StatusConflict},
+		{name: "too many items", err: domain.ErrorInvalidShipment, expectedCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if !tt.anonymous {
+				svc.EXPECT().CreateShipment(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, shipment *domain.Shipment) error {
+					assert.Equal(t, "o1", shipment.OrderID)
+					assert.Equal(t, "DHL", shipment.Carrier)
+					assert.Equal(t, []domain.ShipmentItem{{ProductID: "p1", Quantity: 1}}, shipment.Items)
+					return tt.err
+				})
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/orders/o1/shipments",
+				bytes.NewBufferString(`{"carrier":"DHL","tracking_number":"JD01","items":[{"product_id":"p1","quantity":1}]}`))
+			assert.NoError(t, err)
+			if !tt.anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_DeliverShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		anonymous    bool
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusOK},
+		{name: "unknown shipment", err: domain.ErrorShipmentNotFound, expectedCode: http.StatusNotFound},
+		{name: "no admin credentials", anonymous: true, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if !tt.anonymous {
+				var shipment *domain.Shipment
+				if tt.err == nil {
+					shipment = &domain.Shipment{ID: "s1", OrderID: "o1"}
+				}
+				svc.EXPECT().DeliverShipment(gomock.Any(), "o1", "s1").Return(shipment, tt.err)
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/orders/o1/shipments/s1/deliver", nil)
+			assert.NoError(t, err)
+			if !tt.anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateVariantHandler adds a variant to a product. Stock is brought in with
+// stock adjustments naming the variant.
+func (s *Server) CreateVariantHandler(c *gin.Context) {
+	var dto VariantDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant := &domain.Variant{
+		ProductID: c.Param("id"),
+		SKU:       dto.SKU,
+		Options:   dto.Options,
+		Price:     dto.Price,
+	}
+	if err := s.service.CreateVariant(c.Request.Context(), variant); err != nil {
+		writeVariantError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newVariantDTO(variant))
+}
+
+func writeVariantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrorProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorVariantExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrorInvalidVariant):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,89 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_CreateVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		anonymous    bool
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusCreated},
+		{name: "no admin credentials", anonymous: true, expectedCode: http.StatusUnauthorized},
+		{name: "unknown product", err: domain.ErrorProductNotFound, expectedCode: http.StatusNotFound},
+		{name: "duplicate sku", err: domain.ErrorVariantExists, expectedCode: http.StatusConflict},
+		{name: "no options", err: domain.ErrorInvalidVariant, expectedCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if !tt.anonymous {
+				svc.EXPECT().CreateVariant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, v *domain.Variant) error {
+					price := domain.NewMoney(1200, "KZT")
+					assert.Equal(t, &domain.Variant{ProductID: "p1", SKU: "TS-M-RED", Options: map[string]string{"size": "M", "color": "red"}, Price: &price}, v)
+					return tt.err
+				})
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/products/p1/variants",
+				bytes.NewBufferString(`{"sku":"TS-M-RED","options":{"size":"M","color":"red"},"price":{"amount":1200,"currency":"KZT"}}`))
+			assert.NoError(t, err)
+			if !tt.anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_GetProductVariants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	price := domain.NewMoney(1200, "KZT")
+	createdAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{
+		ID:     "p1",
+		Name:   "T-shirt",
+		Price:  domain.NewMoney(1000, "KZT"),
+		Amount: 3,
+		Variants: []domain.Variant{
+			{ID: "v1", ProductID: "p1", SKU: "TS-M", Options: map[string]string{"size": "M"}, Amount: 1, CreatedAt: createdAt},
+			{ID: "v2", ProductID: "p1", SKU: "TS-XL", Options: map[string]string{"size": "XL"}, Price: &price, Amount: 2, CreatedAt: createdAt},
+		},
+	}, nil)
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/products/p1", nil)
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"p1","name":"T-shirt","price":{"amount":1000,"currency":"KZT"},"sku":"","amount":3,"reorder_point":0,"reorder_quantity":0,"supplier_id":null,"variants":[
+		{"id":"v1","sku":"TS-M","options":{"size":"M"},"price":null,"amount":1,"created_at":"2026-03-01T12:00:00Z"},
+		{"id":"v2","sku":"TS-XL","options":{"size":"XL"},"price":{"amount":1200,"currency":"KZT"},"amount":2,"created_at":"2026-03-01T12:00:00Z"}
+	]}`, w.Body.String())
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) CreateWarehouseHandler(c *gin.Context) {
+	var dto WarehouseDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	warehouse := &domain.Warehouse{Name: dto.Name, Country: dto.Country}
+	if err := s.service.CreateWarehouse(c.Request.Context(), warehouse); err != nil {
+		if errors.Is(err, domain.ErrorInvalidWarehouse) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, newWarehouseDTO(warehouse))
+}
+
+func (s *Server) ListWarehousesHandler(c *gin.Context) {
+	warehouses, err := s.service.ListWarehouses(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]WarehouseDTO, 0, len(warehouses))
+	for _, warehouse := range warehouses {
+		dtos = append(dtos, newWarehouseDTO(warehouse))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
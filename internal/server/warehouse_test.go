@@ -0,0 +1,73 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_CreateWarehouse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		anonymous    bool
+		err          error
+		expectedCode int
+	}{
+		{name: "success", expectedCode: http.StatusCreated},
+		{name: "invalid country", err: domain.ErrorInvalidWarehouse, expectedCode: http.StatusUnprocessableEntity},
+		{name: "no admin credentials", anonymous: true, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := internalMock.NewMockService(ctrl)
+			if !tt.anonymous {
+				svc.EXPECT().CreateWarehouse(gomock.Any(), &domain.Warehouse{Name: "Astana", Country: "KZ"}).Return(tt.err)
+			}
+
+			r := server.NewServer(svc, withTestAdmin).SetupRouter()
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/warehouses", bytes.NewBufferString(`{"name":"Astana","country":"KZ"}`))
+			assert.NoError(t, err)
+			if !tt.anonymous {
+				asAdmin(req)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_GetProductStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{
+		ID:     "p1",
+		Name:   "Pen",
+		Price:  domain.NewMoney(10, "KZT"),
+		Amount: 5,
+		Stock:  []domain.WarehouseStock{{WarehouseID: "almaty", Amount: 2}, {WarehouseID: "main", Amount: 3}},
+	}, nil)
+
+	r := server.NewServer(svc).SetupRouter()
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/api/v1/products/p1", nil)
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"p1","name":"Pen","price":{"amount":10,"currency":"KZT"},"sku":"","amount":5,"stock":[{"warehouse_id":"almaty","amount":2},{"warehouse_id":"main","amount":3}],"reorder_point":0,"reorder_quantity":0,"supplier_id":null}`, w.Body.String())
+}
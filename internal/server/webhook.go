@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) CreateWebhookHandler(c *gin.Context) {
+	var dto WebhookDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub := dto.toDomain()
+	if err := s.service.CreateWebhookSubscription(c.Request.Context(), sub); err != nil {
+		if errors.Is(err, domain.ErrorInvalidWebhook) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := newWebhookDTO(sub)
+	resp.Secret = sub.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (s *Server) ListWebhooksHandler(c *gin.Context) {
+	subs, err := s.service.ListWebhookSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]WebhookDTO, 0, len(subs))
+	for _, sub := range subs {
+		dtos = append(dtos, newWebhookDTO(sub))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+func (s *Server) GetWebhookHandler(c *gin.Context) {
+	sub, err := s.service.GetWebhookSubscription(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrorWebhookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newWebhookDTO(sub))
+}
+
+func (s *Server) DeleteWebhookHandler(c *gin.Context) {
+	if err := s.service.DeleteWebhookSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrorWebhookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) ListWebhookDeliveriesHandler(c *gin.Context) {
+	deliveries, err := s.service.ListWebhookDeliveries(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrorWebhookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	dtos := make([]WebhookDeliveryDTO, 0, len(deliveries))
+	for _, d := range deliveries {
+		dtos = append(dtos, newWebhookDeliveryDTO(d))
+	}
+	c.JSON(http.StatusOK, dtos)
+}
+
+func (s *Server) RedeliverWebhookHandler(c *gin.Context) {
+	delivery, err := s.service.RedeliverWebhook(c.Request.Context(), c.Param("id"), c.Param("deliveryID"))
+	if err != nil {
+		if errors.Is(err, domain.ErrorDeliveryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, newWebhookDeliveryDTO(delivery))
+}
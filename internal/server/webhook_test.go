@@ -0,0 +1,135 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	internalMock "github.com/aibekfatkhulla/shop/internal/mocks"
+	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_CreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		body         string
+		svc          server.Service
+		expectedCode int
+		expectSecret bool
+	}{
+		{
+			name: "success",
+			body: `{"url":"https://partner.example/hook","event_types":["order.status_changed"]}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, sub *domain.WebhookSubscription) error {
+					assert.Equal(t, []domain.EventType{domain.EventOrderStatusChanged}, sub.EventTypes)
+					sub.ID = "w1"
+					sub.Secret = "whsec_generated"
+					return nil
+				})
+				return s
+			}(),
+			expectedCode: http.StatusCreated,
+			expectSecret: true,
+		},
+		{
+			name: "invalid subscription",
+			body: `{"url":"ftp://partner.example","event_types":["order.status_changed"]}`,
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Return(domain.ErrorInvalidWebhook)
+				return s
+			}(),
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := server.NewServer(tt.svc, withTestAdmin).SetupRouter()
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/api/v1/webhooks", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			r.ServeHTTP(w, asAdmin(req))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectSecret {
+				var resp server.WebhookDTO
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "whsec_generated", resp.Secret)
+			}
+		})
+	}
+}
+
+func TestServer_GetWebhookHidesSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := internalMock.NewMockService(ctrl)
+	svc.EXPECT().GetWebhookSubscription(gomock.Any(), "w1").Return(&domain.WebhookSubscription{ID: "w1", Secret: "whsec_hidden"}, nil)
+
+	r := server.NewServer(svc, withTestAdmin).SetupRouter()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, asAdmin(httptest.NewRequest("GET", "/api/v1/webhooks/w1", nil)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "whsec_hidden")
+}
+
+func TestServer_RedeliverWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		svc          server.Service
+		expectedCode int
+	}{
+		{
+			name: "success",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				original := "d1"
+				s.EXPECT().RedeliverWebhook(gomock.Any(), "w1", "d1").Return(&domain.WebhookDelivery{
+					ID:             "d2",
+					SubscriptionID: "w1",
+					Status:         domain.DeliveryPending,
+					RedeliveryOf:   &original,
+				}, nil)
+				return s
+			}(),
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name: "unknown delivery",
+			svc: func() server.Service {
+				s := internalMock.NewMockService(ctrl)
+				s.EXPECT().RedeliverWebhook(gomock.Any(), "w1", "d1").Return(nil, domain.ErrorDeliveryNotFound)
+				return s
+			}(),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := server.NewServer(tt.svc, withTestAdmin).SetupRouter()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, asAdmin(httptest.NewRequest("POST", "/api/v1/webhooks/w1/deliveries/d1/redeliver", nil)))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
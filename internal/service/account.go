@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// AccountMailer delivers account tokens to their users.
+type AccountMailer interface {
+	SendEmailVerification(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error
+	SendPasswordReset(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error
+}
+
+// RequestEmailVerification is a method for mailing a new verification token
+// to the user with the given email. Unknown and already verified addresses are
+// ignored so that the response does not reveal which emails are registered.
+func (s *service) RequestEmailVerification(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "Service.RequestEmailVerification")
+	defer span.End()
+
+	user, err := s.repo.GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrorUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	s.requestAccountToken(ctx, user, domain.TokenEmailVerification)
+	return nil
+}
+
+// VerifyEmail is a method for marking a user's email as verified with a token
+// mailed to them
+func (s *service) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := tracer.Start(ctx, "Service.VerifyEmail")
+	defer span.End()
+
+	if token == "" {
+		return domain.ErrorInvalidToken
+	}
+	return s.repo.VerifyEmail(ctx, hashToken(token), time.Now())
+}
+
+// RequestPasswordReset is a method for mailing a password reset token to the
+// user with the given email. Unknown addresses are ignored so that the
+// response does not reveal which emails are registered.
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "Service.RequestPasswordReset")
+	defer span.End()
+
+	user, err := s.repo.GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrorUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.requestAccountToken(ctx, user, domain.TokenPasswordReset)
+	return nil
+}
+
+// ResetPassword is a method for setting a new password with a token mailed to
+// the user
+func (s *service) ResetPassword(ctx context.Context, token, password string) error {
+	ctx, span := tracer.Start(ctx, "Service.ResetPassword")
+	defer span.End()
+
+	if token == "" {
+		return domain.ErrorInvalidToken
+	}
+	return s.repo.ResetPassword(ctx, hashToken(token), hashPassword(password), time.Now())
+}
+
+// requestAccountToken queues a token of the given purpose to be mailed to
+// user by the event dispatcher, so the request does not wait for the mailer.
+// Errors are only logged: failing the request for a registered address alone
+// would reveal that it is registered.
+func (s *service) requestAccountToken(ctx context.Context, user *domain.User, purpose domain.TokenPurpose) {
+	if s.accountMailer == nil {
+		slog.WarnContext(ctx, "account email skipped, no mailer is configured", "user_id", user.ID, "purpose", string(purpose))
+		return
+	}
+	if err := s.repo.AddEvents(ctx, domain.NewAccountTokenRequested(user, purpose)); err != nil {
+		slog.ErrorContext(ctx, "queue account email", "user_id", user.ID, "purpose", string(purpose), "error", err)
+	}
+}
+
+// mailAccountToken issues and mails the token event asks for. A failed send is
+// retried with the event and issues a new token, which voids the undelivered
+// one.
+func (s *service) mailAccountToken(ctx context.Context, event domain.Event) error {
+	if s.accountMailer == nil {
+		return nil
+	}
+	var p domain.AccountTokenRequested
+	if err := decodePayload(event, &p); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, p.UserID)
+	if errors.Is(err, domain.ErrorUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch p.Purpose {
+	case domain.TokenEmailVerification:
+		if user.EmailVerified {
+			return nil
+		}
+		token, expiresAt, err := s.issueToken(ctx, user, p.Purpose, s.emailVerificationTTL)
+		if err != nil {
+			return err
+		}
+		return s.accountMailer.SendEmailVerification(ctx, user, token, expiresAt)
+	case domain.TokenPasswordReset:
+		token, expiresAt, err := s.issueToken(ctx, user, p.Purpose, s.passwordResetTTL)
+		if err != nil {
+			return err
+		}
+		return s.accountMailer.SendPasswordReset(ctx, user, token, expiresAt)
+	}
+	return fmt.Errorf("unknown token purpose %q", p.Purpose)
+}
+
+// issueToken stores a new token of the given purpose for user and returns it
+// in the clear; only its hash is kept.
+func (s *service) issueToken(ctx context.Context, user *domain.User, purpose domain.TokenPurpose, ttl time.Duration) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	stored := &domain.UserToken{
+		Hash:      hashToken(token),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.repo.CreateUserToken(ctx, stored); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, stored.ExpiresAt, nil
+}
+
+// hashToken returns the form in which a token is stored. Tokens are random, so
+// unlike passwords they need no salt.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/google/uuid"
+)
+
+// CreateAddress is a method for adding an address to a user's address book
+func (s *service) CreateAddress(ctx context.Context, address *domain.Address) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateAddress")
+	defer span.End()
+
+	if err := normalizeAddress(address); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetUserByID(ctx, address.UserID); err != nil {
+		return err
+	}
+
+	address.ID = uuid.New().String()
+	now := time.Now()
+	address.CreatedAt = now
+	address.UpdatedAt = now
+
+	return s.repo.CreateAddress(ctx, address)
+}
+
+func (s *service) GetAddress(ctx context.Context, userID, id string) (*domain.Address, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetAddress")
+	defer span.End()
+
+	return s.repo.GetAddress(ctx, userID, id)
+}
+
+func (s *service) ListAddresses(ctx context.Context, userID string) ([]*domain.Address, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListAddresses")
+	defer span.End()
+
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAddresses(ctx, userID)
+}
+
+// UpdateAddress is a method for replacing an address of a user's address book;
+// orders placed with it keep the old copy
+func (s *service) UpdateAddress(ctx context.Context, address *domain.Address) error {
+	ctx, span := tracer.Start(ctx, "Service.UpdateAddress")
+	defer span.End()
+
+	if err := normalizeAddress(address); err != nil {
+		return err
+	}
+	address.UpdatedAt = time.Now()
+
+	return s.repo.UpdateAddress(ctx, address)
+}
+
+func (s *service) DeleteAddress(ctx context.Context, userID, id string) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteAddress")
+	defer span.End()
+
+	return s.repo.DeleteAddress(ctx, userID, id)
+}
+
+// normalizeAddress trims the fields of address and checks that the required
+// ones are set.
+func normalizeAddress(a *domain.Address) error {
+	for _, field := range []*string{&a.Recipient, &a.Line1, &a.Line2, &a.City, &a.Region, &a.PostalCode, &a.Phone} {
+		*field = strings.TrimSpace(*field)
+	}
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+
+	switch {
+	case a.Recipient == "":
+		return fmt.Errorf("%w: recipient is required", domain.ErrorInvalidAddress)
+	case a.Line1 == "":
+		return fmt.Errorf("%w: line1 is required", domain.ErrorInvalidAddress)
+	case a.City == "":
+		return fmt.Errorf("%w: city is required", domain.ErrorInvalidAddress)
+	case a.PostalCode == "":
+		return fmt.Errorf("%w: postal_code is required", domain.ErrorInvalidAddress)
+	case !isCountryCode(a.Country):
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", domain.ErrorInvalidAddress)
+	}
+	return nil
+}
+
+func isCountryCode(s string) bool {
+	return len(s) == 2 && s[0] >= 'A' && s[0] <= 'Z' && s[1] >= 'A' && s[1] <= 'Z'
+}
+
+// orderAddresses resolves the addresses selected for an order to copies of the
+// user's address book entries. Without a selection the shipping address is
+// the user's default one, if any, and billing goes to the shipping address.
+func (s *service) orderAddresses(ctx context.Context, order *domain.Order) error {
+	shipping, err := s.addressOrDefault(ctx, order.UserID, order.ShippingAddress)
+	if err != nil {
+		return err
+	}
+	billing := shipping
+	if order.BillingAddress != nil {
+		if billing, err = s.repo.GetAddress(ctx, order.UserID, order.BillingAddress.ID); err != nil {
+			return err
+		}
+	}
+
+	order.ShippingAddress = shipping
+	order.BillingAddress = billing
+	return nil
+}
+
+func (s *service) addressOrDefault(ctx context.Context, userID string, selected *domain.Address) (*domain.Address, error) {
+	if selected != nil {
+		return s.repo.GetAddress(ctx, userID, selected.ID)
+	}
+	address, err := s.repo.GetDefaultAddress(ctx, userID)
+	if errors.Is(err, domain.ErrorAddressNotFound) {
+		return nil, nil
+	}
+	return address, err
+}
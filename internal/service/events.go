@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/events"
+	"github.com/aibekfatkhulla/shop/internal/metrics"
+)
+
+const (
+	// eventLease is how long a claimed event is hidden from other dispatchers.
+	eventLease = time.Minute
+	// maxRetryBackoff caps the delay between delivery attempts.
+	maxRetryBackoff = time.Hour
+)
+
+// DispatchEvents is a method for delivering due outbox events to the event
+// sink. Failed deliveries are retried with exponential backoff until the
+// attempts are exhausted. It returns the number of delivered events.
+func (s *service) DispatchEvents(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "Service.DispatchEvents")
+	defer span.End()
+
+	now := time.Now()
+	due, err := s.repo.ClaimEvents(ctx, s.eventBatchSize, s.eventMaxAttempts, now, now.Add(eventLease))
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range due {
+		if err := s.publishEvent(ctx, event); err != nil {
+			metrics.EventsPublished.WithLabelValues(string(event.Type), "error").Inc()
+			if event.Attempts >= s.eventMaxAttempts {
+				slog.ErrorContext(ctx, "event delivery abandoned", "event_id", event.ID, "event_type", string(event.Type), "attempts", event.Attempts, "error", err)
+			}
+			if err := s.repo.FailEvent(ctx, event.ID, err, time.Now().Add(retryDelay(s.eventBackoff, event.Attempts))); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		metrics.EventsPublished.WithLabelValues(string(event.Type), "ok").Inc()
+		if err := s.repo.MarkEventDispatched(ctx, event.ID, time.Now()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// publishEvent fans event out to the webhook subscriptions and hands it to
+// the event sink. Account token requests are mailed instead.
+func (s *service) publishEvent(ctx context.Context, event domain.Event) error {
+	if event.Type == domain.EventAccountTokenRequested {
+		return s.mailAccountToken(ctx, event)
+	}
+
+	payload, err := events.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.EnqueueWebhookDeliveries(ctx, event, payload, time.Now()); err != nil {
+		return err
+	}
+	return s.eventSink.Publish(ctx, event)
+}
+
+// decodePayload reads the payload of an event taken from the outbox into dst.
+// Events raised in process still carry their typed payload.
+func decodePayload(event domain.Event, dst any) error {
+	raw, ok := event.Payload.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(event.Payload); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// retryDelay returns the delay after the given number of failed attempts:
+// base, doubled for every further attempt, at most maxRetryBackoff.
+func retryDelay(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// ClaimIdempotencyKey is a method for reserving an idempotency key before a
+// request is processed. If the key was already used for the same request the
+// stored record is returned; callers must replay it when Completed is set.
+func (s *service) ClaimIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	ctx, span := tracer.Start(ctx, "Service.ClaimIdempotencyKey")
+	defer span.End()
+
+	now := time.Now()
+	key.CreatedAt = now
+	key.ExpiresAt = now.Add(s.idempotencyTTL)
+
+	err := s.repo.CreateIdempotencyKey(ctx, key)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, domain.ErrorIdempotencyKeyExists) {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetIdempotencyKey(ctx, key.Key, key.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Fingerprint != key.Fingerprint {
+		return nil, domain.ErrorIdempotencyKeyMismatch
+	}
+	if !existing.Completed {
+		return nil, domain.ErrorIdempotencyKeyInProgress
+	}
+	return existing, nil
+}
+
+// CompleteIdempotencyKey is a method for storing the response of a claimed key
+func (s *service) CompleteIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error {
+	ctx, span := tracer.Start(ctx, "Service.CompleteIdempotencyKey")
+	defer span.End()
+
+	key.Completed = true
+	return s.repo.CompleteIdempotencyKey(ctx, key)
+}
+
+// ReleaseIdempotencyKey is a method for dropping a claim whose request failed,
+// so that the client can retry it with the same key
+func (s *service) ReleaseIdempotencyKey(ctx context.Context, key, scope string) error {
+	ctx, span := tracer.Start(ctx, "Service.ReleaseIdempotencyKey")
+	defer span.End()
+
+	return s.repo.DeleteIdempotencyKey(ctx, key, scope)
+}
+
+// PurgeExpiredIdempotencyKeys is a method for deleting keys past their TTL
+func (s *service) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "Service.PurgeExpiredIdempotencyKeys")
+	defer span.End()
+
+	return s.repo.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	// GIF uploads are decoded for their thumbnails.
+	_ "image/gif"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/imaging"
+	"github.com/aibekfatkhulla/shop/internal/storage"
+	"github.com/google/uuid"
+)
+
+// maxImagePixels bounds the decoded size of uploads, so that a small file
+// cannot claim gigabytes of memory.
+const maxImagePixels = 50_000_000
+
+// imageTypes are the content types accepted for product images.
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// UploadProductImage is a method for adding an image to the end of the
+// gallery of a product. The type is sniffed from the content, not taken from
+// the client. The original and its thumbnails are stored in the blob store
+// before the image is added to the gallery.
+func (s *service) UploadProductImage(ctx context.Context, productID string, r io.Reader) (*domain.ProductImage, error) {
+	ctx, span := tracer.Start(ctx, "Service.UploadProductImage")
+	defer span.End()
+
+	if s.blobs == nil {
+		return nil, errors.New("no blob store is configured for product images")
+	}
+	if _, err := s.repo.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.imageMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.imageMaxBytes {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", domain.ErrorImageTooLarge, s.imageMaxBytes)
+	}
+	contentType := http.DetectContentType(data)
+	if !imageTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s is not a JPEG, PNG or GIF image", domain.ErrorInvalidImage, contentType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrorInvalidImage, err)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w: %d×%d pixels is more than %d", domain.ErrorImageTooLarge, config.Width, config.Height, maxImagePixels)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrorInvalidImage, err)
+	}
+
+	img := &domain.ProductImage{
+		ID:          uuid.New().String(),
+		ProductID:   productID,
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now(),
+	}
+	renditions := map[domain.ImageSize][]byte{domain.ImageOriginal: data}
+	for size, edge := range domain.ThumbnailSizes {
+		if renditions[size], err = encodeThumbnail(imaging.Fit(src, edge), img.SizeContentType(size)); err != nil {
+			return nil, err
+		}
+	}
+
+	for size, data := range renditions {
+		if err := s.blobs.Put(ctx, img.Key(size), data); err != nil {
+			s.deleteImageBlobs(ctx, img)
+			return nil, err
+		}
+	}
+	if err := s.repo.CreateProductImage(ctx, img); err != nil {
+		s.deleteImageBlobs(ctx, img)
+		return nil, err
+	}
+	return img, nil
+}
+
+func encodeThumbnail(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	return buf.Bytes(), err
+}
+
+func (s *service) ListProductImages(ctx context.Context, productID string) ([]*domain.ProductImage, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListProductImages")
+	defer span.End()
+
+	if _, err := s.repo.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListProductImages(ctx, productID)
+}
+
+// OpenProductImage is a method for reading a rendition of a product image.
+// The caller closes the returned reader.
+func (s *service) OpenProductImage(ctx context.Context, productID, id string, size domain.ImageSize) (*domain.ProductImage, io.ReadCloser, error) {
+	ctx, span := tracer.Start(ctx, "Service.OpenProductImage")
+	defer span.End()
+
+	if !size.Valid() {
+		return nil, nil, domain.ErrorImageNotFound
+	}
+	if s.blobs == nil {
+		return nil, nil, errors.New("no blob store is configured for product images")
+	}
+	img, err := s.repo.GetProductImage(ctx, productID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := s.blobs.Open(ctx, img.Key(size))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, domain.ErrorImageNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return img, r, nil
+}
+
+// ReorderProductImages is a method for rearranging the gallery of a product;
+// ids lists every image of the product in the new order.
+func (s *service) ReorderProductImages(ctx context.Context, productID string, ids []string) ([]*domain.ProductImage, error) {
+	ctx, span := tracer.Start(ctx, "Service.ReorderProductImages")
+	defer span.End()
+
+	if err := s.repo.ReorderProductImages(ctx, productID, ids); err != nil {
+		return nil, err
+	}
+	return s.repo.ListProductImages(ctx, productID)
+}
+
+// DeleteProductImage is a method for removing an image from the gallery of a
+// product. Its files are removed from the blob store afterwards; a failure to
+// do so is only logged, as the image is no longer reachable.
+func (s *service) DeleteProductImage(ctx context.Context, productID, id string) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteProductImage")
+	defer span.End()
+
+	img, err := s.repo.GetProductImage(ctx, productID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteProductImage(ctx, productID, id); err != nil {
+		return err
+	}
+	if s.blobs != nil {
+		s.deleteImageBlobs(ctx, img)
+	}
+	return nil
+}
+
+// deleteImageBlobs removes every rendition of img from the blob store.
+func (s *service) deleteImageBlobs(ctx context.Context, img *domain.ProductImage) {
+	sizes := []domain.ImageSize{domain.ImageOriginal}
+	for size := range domain.ThumbnailSizes {
+		sizes = append(sizes, size)
+	}
+	for _, size := range sizes {
+		if err := s.blobs.Delete(ctx, img.Key(size)); err != nil {
+			slog.ErrorContext(ctx, "delete image blob", "key", img.Key(size), "error", err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// AdjustStock is a method for correcting the amount of a product in a
+// warehouse by m.Quantity, e.g. after a stock count or a delivery from a
+// supplier. The correction is recorded in the inventory ledger with its
+// reason and actor.
+func (s *service) AdjustStock(ctx context.Context, m *domain.InventoryMovement) error {
+	ctx, span := tracer.Start(ctx, "Service.AdjustStock")
+	defer span.End()
+
+	m.Reason = strings.TrimSpace(m.Reason)
+	m.Actor = strings.TrimSpace(m.Actor)
+	m.WarehouseID = strings.TrimSpace(m.WarehouseID)
+	switch {
+	case m.WarehouseID == "":
+		return fmt.Errorf("%w: warehouse_id is required", domain.ErrorInvalidStockAdjustment)
+	case m.Quantity == 0:
+		return fmt.Errorf("%w: quantity must not be zero", domain.ErrorInvalidStockAdjustment)
+	case m.Reason == "":
+		return fmt.Errorf("%w: reason is required", domain.ErrorInvalidStockAdjustment)
+	case m.Actor == "":
+		return fmt.Errorf("%w: actor is required", domain.ErrorInvalidStockAdjustment)
+	}
+
+	m.Type = domain.MovementAdjustment
+	m.Reference = ""
+	m.CreatedAt = time.Now()
+	return s.repo.AdjustStock(ctx, m)
+}
+
+func (s *service) ListInventoryMovements(ctx context.Context, productID string, limit, offset int) ([]*domain.InventoryMovement, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListInventoryMovements")
+	defer span.End()
+
+	if _, err := s.repo.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInventoryMovements(ctx, productID, limit, offset)
+}
+
+// ListStockDiscrepancies is a method for reconciling the inventory ledger
+// with the product amounts; it returns the products where they disagree.
+func (s *service) ListStockDiscrepancies(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListStockDiscrepancies")
+	defer span.End()
+
+	return s.repo.ListStockDiscrepancies(ctx)
+}
+
+// ListReorderLines is a method for listing the products at or below their
+// reorder point together with the suppliers to order them from.
+func (s *service) ListReorderLines(ctx context.Context) ([]domain.ReorderLine, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListReorderLines")
+	defer span.End()
+
+	return s.repo.ListReorderLines(ctx)
+}
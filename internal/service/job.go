@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/metrics"
+)
+
+const (
+	// staleOrderBatchSize is how many orders are canceled in one transaction.
+	staleOrderBatchSize = 100
+	// lowStockBatchSize is how many products are checked in one transaction.
+	lowStockBatchSize = 100
+)
+
+// CancelStaleOrders is a method for canceling the orders that have been
+// pending for longer than the pending order TTL, releasing their reserved
+// stock. The outcome is recorded as the last run of domain.JobCancelStaleOrders.
+// It returns the number of canceled orders.
+func (s *service) CancelStaleOrders(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "Service.CancelStaleOrders")
+	defer span.End()
+
+	return s.runJob(ctx, domain.JobCancelStaleOrders, s.cancelStaleOrders)
+}
+
+func (s *service) cancelStaleOrders(ctx context.Context, run *domain.JobRun) error {
+	createdBefore := run.StartedAt.Add(-s.pendingOrderTTL)
+	for {
+		canceled, err := s.repo.CancelStaleOrders(ctx, createdBefore, staleOrderBatchSize, time.Now())
+		if err != nil {
+			return err
+		}
+		run.Processed += len(canceled)
+		metrics.OrderStatusTransitions.WithLabelValues(string(domain.StatusPending), string(domain.StatusCanceled)).Add(float64(len(canceled)))
+		if len(canceled) < staleOrderBatchSize {
+			return nil
+		}
+	}
+}
+
+// CheckLowStock is a method for raising a StockLow event for every product
+// that reached its reorder point since the last check. The events reach the
+// event sink and webhooks like any other. The outcome is recorded as the last
+// run of domain.JobCheckLowStock. It returns the number of alerts raised.
+func (s *service) CheckLowStock(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "Service.CheckLowStock")
+	defer span.End()
+
+	return s.runJob(ctx, domain.JobCheckLowStock, func(ctx context.Context, run *domain.JobRun) error {
+		for {
+			alerted, err := s.repo.ClaimLowStock(ctx, lowStockBatchSize, time.Now())
+			if err != nil {
+				return err
+			}
+			run.Processed += len(alerted)
+			if len(alerted) < lowStockBatchSize {
+				return nil
+			}
+		}
+	})
+}
+
+// runJob runs job and records its outcome as the last run of the job name.
+func (s *service) runJob(ctx context.Context, name string, job func(context.Context, *domain.JobRun) error) (int, error) {
+	run := &domain.JobRun{Name: name, StartedAt: time.Now()}
+	err := job(ctx, run)
+	run.FinishedAt = time.Now()
+	if err != nil {
+		run.Error = err.Error()
+	}
+	if err := s.repo.SaveJobRun(ctx, run); err != nil {
+		slog.ErrorContext(ctx, "save job run", "job", run.Name, "error", err)
+	}
+	return run.Processed, err
+}
+
+// ListJobRuns is a method for reporting the last run of every background job
+// that has run so far, on any replica.
+func (s *service) ListJobRuns(ctx context.Context) ([]*domain.JobRun, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListJobRuns")
+	defer span.End()
+
+	return s.repo.ListJobRuns(ctx)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/events"
+	"github.com/aibekfatkhulla/shop/internal/storage"
+	"github.com/aibekfatkhulla/shop/internal/webhook"
+)
+
+const (
+	defaultIdempotencyTTL = 24 * time.Hour
+
+	defaultEmailVerificationTTL = 48 * time.Hour
+	defaultPasswordResetTTL     = time.Hour
+
+	defaultPendingOrderTTL = 24 * time.Hour
+
+	defaultEventBatchSize   = 100
+	defaultEventMaxAttempts = 10
+	defaultEventBackoff     = time.Second
+
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookBackoff     = 10 * time.Second
+
+	defaultImageMaxBytes = 10 << 20
+)
+
+// Option configures optional behaviour of the service.
+type Option func(*service)
+
+// WithIdempotencyTTL sets how long a stored idempotent response is replayed.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.idempotencyTTL = ttl
+	}
+}
+
+// WithAccountMailer sets how email verification and password reset tokens
+// are delivered. Without one no tokens are issued.
+func WithAccountMailer(m AccountMailer) Option {
+	return func(s *service) {
+		s.accountMailer = m
+	}
+}
+
+// WithTokenTTLs sets how long email verification and password reset tokens
+// stay valid.
+func WithTokenTTLs(emailVerification, passwordReset time.Duration) Option {
+	return func(s *service) {
+		s.emailVerificationTTL = emailVerification
+		s.passwordResetTTL = passwordReset
+	}
+}
+
+// WithVerifiedEmailRequiredToOrder rejects orders of users whose email is not
+// verified.
+func WithVerifiedEmailRequiredToOrder(required bool) Option {
+	return func(s *service) {
+		s.requireVerifiedToOrder = required
+	}
+}
+
+// WithPendingOrderTTL sets how long an order may stay pending before
+// CancelStaleOrders cancels it.
+func WithPendingOrderTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.pendingOrderTTL = ttl
+	}
+}
+
+// WithFulfillmentStrategy sets how order lines are spread over the
+// warehouses. Orders are fulfilled from the nearest warehouse by default.
+func WithFulfillmentStrategy(strategy domain.FulfillmentStrategy) Option {
+	return func(s *service) {
+		s.fulfillment = strategy
+	}
+}
+
+// WithDefaultCurrency sets the currency orders are placed in when they do not
+// name one and new users keep their balance in. It is domain.DefaultCurrency
+// by default.
+func WithDefaultCurrency(currency domain.Currency) Option {
+	return func(s *service) {
+		s.currency = currency
+	}
+}
+
+// WithEventSink sets where DispatchEvents delivers outbox events. Events are
+// logged by default.
+func WithEventSink(sink events.Sink) Option {
+	return func(s *service) {
+		s.eventSink = sink
+	}
+}
+
+// WithEventRetries sets how often delivery of an event is tried and the delay
+// before the first retry, which doubles on every further attempt.
+func WithEventRetries(maxAttempts int, backoff time.Duration) Option {
+	return func(s *service) {
+		s.eventMaxAttempts = maxAttempts
+		s.eventBackoff = backoff
+	}
+}
+
+// WithWebhookClient sets the HTTP client used for webhook deliveries.
+func WithWebhookClient(client *http.Client) Option {
+	return func(s *service) {
+		s.webhookSender = webhook.Sender{Client: client}
+	}
+}
+
+// WithWebhookRetries sets how often a webhook delivery is tried and the delay
+// before the first retry, which doubles on every further attempt.
+func WithWebhookRetries(maxAttempts int, backoff time.Duration) Option {
+	return func(s *service) {
+		s.webhookMaxAttempts = maxAttempts
+		s.webhookBackoff = backoff
+	}
+}
+
+// WithBlobStore sets where product images and their thumbnails are kept.
+// Without one images cannot be uploaded.
+func WithBlobStore(store storage.BlobStore) Option {
+	return func(s *service) {
+		s.blobs = store
+	}
+}
+
+// WithImageMaxBytes sets the largest product image that can be uploaded.
+func WithImageMaxBytes(n int64) Option {
+	return func(s *service) {
+		s.imageMaxBytes = n
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// PatchUser is a method for partially updating a user. Only the fields in mask
+// are taken from user, the others keep their stored values; user.Version must
+// match the stored version. On success user holds the updated user.
+func (s *service) PatchUser(ctx context.Context, user *domain.User, mask domain.FieldMask) error {
+	ctx, span := tracer.Start(ctx, "Service.PatchUser")
+	defer span.End()
+
+	existing, err := s.repo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if user.Version == domain.AnyVersion {
+		user.Version = existing.Version
+	}
+	if user.Version != existing.Version {
+		return domain.ErrorVersionConflict
+	}
+
+	patched := *existing
+	for _, field := range mask {
+		switch field {
+		case "name":
+			patched.Name = user.Name
+		case "email":
+			if user.Email != existing.Email {
+				patched.Email = user.Email
+				patched.EmailVerified = false
+			}
+		case "password":
+			if user.Password == "" {
+				return fmt.Errorf("%w: password must not be empty", domain.ErrorInvalidPatch)
+			}
+			patched.Password = hashPassword(user.Password)
+		case "number":
+			patched.Number = user.Number
+		case "address":
+			patched.Address = user.Address
+		default:
+			return fmt.Errorf("%w: field %q cannot be changed", domain.ErrorInvalidPatch, field)
+		}
+	}
+	if patched.Name == "" || patched.Email == "" {
+		return fmt.Errorf("%w: name and email must not be empty", domain.ErrorInvalidPatch)
+	}
+
+	if len(mask) > 0 {
+		patched.UpdatedAt = time.Now()
+		if err := s.repo.PatchUser(ctx, &patched, mask); err != nil {
+			return err
+		}
+	}
+
+	*user = patched
+	return nil
+}
+
+// PatchProduct is a method for partially updating a product, see PatchUser.
+func (s *service) PatchProduct(ctx context.Context, product *domain.Product, mask domain.FieldMask) error {
+	ctx, span := tracer.Start(ctx, "Service.PatchProduct")
+	defer span.End()
+
+	existing, err := s.repo.GetProductByID(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+	if product.Version == domain.AnyVersion {
+		product.Version = existing.Version
+	}
+	if product.Version != existing.Version {
+		return domain.ErrorVersionConflict
+	}
+
+	patched := *existing
+	for _, field := range mask {
+		switch field {
+		case "name":
+			patched.Name = product.Name
+		case "price":
+			patched.Price = product.Price
+		case "sku":
+			patched.SKU = product.SKU
+		case "amount":
+			return fmt.Errorf("%w: amount is kept per warehouse, use a stock adjustment", domain.ErrorInvalidPatch)
+		case "reorder_point":
+			patched.ReorderPoint = product.ReorderPoint
+		case "reorder_quantity":
+			patched.ReorderQuantity = product.ReorderQuantity
+		case "supplier_id":
+			patched.SupplierID = product.SupplierID
+			if patched.SupplierID != nil && *patched.SupplierID == "" {
+				patched.SupplierID = nil
+			}
+			if patched.SupplierID != nil {
+				if _, err := s.repo.GetSupplierByID(ctx, *patched.SupplierID); err != nil {
+					if errors.Is(err, domain.ErrorSupplierNotFound) {
+						return fmt.Errorf("%w: unknown supplier %q", domain.ErrorInvalidPatch, *patched.SupplierID)
+					}
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("%w: field %q cannot be changed", domain.ErrorInvalidPatch, field)
+		}
+	}
+	if patched.Name == "" {
+		return fmt.Errorf("%w: name must not be empty", domain.ErrorInvalidPatch)
+	}
+	if !patched.Price.IsPositive() {
+		return fmt.Errorf("%w: price must be positive", domain.ErrorInvalidPatch)
+	}
+	if patched.Price.Currency != existing.Price.Currency {
+		return fmt.Errorf("%w: price must be in %s, prices in other currencies are kept in the price list", domain.ErrorInvalidPatch, existing.Price.Currency)
+	}
+	if patched.ReorderPoint < 0 || patched.ReorderQuantity < 0 {
+		return fmt.Errorf("%w: reorder point and quantity must not be negative", domain.ErrorInvalidPatch)
+	}
+
+	if len(mask) > 0 {
+		if err := s.repo.PatchProduct(ctx, &patched, mask); err != nil {
+			return err
+		}
+	}
+
+	*product = patched
+	return nil
+}
+
+// PatchOrder is a method for partially updating an order, see PatchUser. Only
+// the status can be changed, as described by changeOrderStatus.
+func (s *service) PatchOrder(ctx context.Context, order *domain.Order, mask domain.FieldMask) error {
+	ctx, span := tracer.Start(ctx, "Service.PatchOrder")
+	defer span.End()
+
+	existing, err := s.repo.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if order.Version == domain.AnyVersion {
+		order.Version = existing.Version
+	}
+	if order.Version != existing.Version {
+		return domain.ErrorVersionConflict
+	}
+
+	patched := *existing
+	for _, field := range mask {
+		switch field {
+		case "status":
+			if !order.Status.Valid() {
+				return fmt.Errorf("%w: unknown status %q", domain.ErrorInvalidPatch, order.Status)
+			}
+			patched.Status = order.Status
+		default:
+			return fmt.Errorf("%w: field %q cannot be changed", domain.ErrorInvalidPatch, field)
+		}
+	}
+
+	if patched.Status != existing.Status {
+		changed, err := s.changeOrderStatus(ctx, existing, patched.Status)
+		if err != nil {
+			return err
+		}
+		*order = *changed
+		return nil
+	}
+
+	if len(mask) > 0 {
+		patched.UpdatedAt = time.Now()
+		if err := s.repo.PatchOrder(ctx, &patched, mask); err != nil {
+			return err
+		}
+	}
+
+	*order = patched
+	return nil
+}
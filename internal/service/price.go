@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+)
+
+// SetProductPrice is a method for setting what a product costs in a currency
+// other than the one of its base price, which is changed with PatchProduct.
+func (s *service) SetProductPrice(ctx context.Context, productID string, price domain.Money) error {
+	ctx, span := tracer.Start(ctx, "Service.SetProductPrice")
+	defer span.End()
+
+	if !price.Currency.Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrorInvalidCurrency, price.Currency)
+	}
+	if !price.IsPositive() {
+		return fmt.Errorf("%w: price must be positive", domain.ErrorInvalidPrice)
+	}
+
+	product, err := s.repo.GetProductByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if price.Currency == product.Price.Currency {
+		return fmt.Errorf("%w: %s is the currency of the base price, patch the product instead", domain.ErrorInvalidPrice, price.Currency)
+	}
+	return s.repo.SetProductPrice(ctx, productID, price)
+}
+
+// DeleteProductPrice is a method for no longer selling a product in a
+// currency other than the one of its base price.
+func (s *service) DeleteProductPrice(ctx context.Context, productID string, currency domain.Currency) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteProductPrice")
+	defer span.End()
+
+	product, err := s.repo.GetProductByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if currency == product.Price.Currency {
+		return fmt.Errorf("%w: the base price cannot be removed", domain.ErrorInvalidPrice)
+	}
+	return s.repo.DeleteProductPrice(ctx, productID, currency)
+}
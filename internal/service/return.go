@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/google/uuid"
+)
+
+// CreateReturn is a method for requesting the return of items of a completed
+// order, that is one that was paid and whose items were all shipped and
+// delivered. The refund is what the items cost when the order was placed.
+func (s *service) CreateReturn(ctx context.Context, ret *domain.Return) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateReturn")
+	defer span.End()
+
+	order, err := s.repo.GetOrderByID(ctx, ret.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != domain.StatusCompleted {
+		return domain.ErrorOrderNotReturnable
+	}
+	shipments, err := s.repo.ListShipments(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if len(shipments) == 0 || !fullyDelivered(order, shipments) {
+		return fmt.Errorf("%w: not every item has been delivered", domain.ErrorOrderNotReturnable)
+	}
+	existing, err := s.repo.ListReturns(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	refund, err := validateReturnItems(ret.Items, returnable(order, existing), order.Currency)
+	if err != nil {
+		return err
+	}
+
+	ret.ID = uuid.New().String()
+	ret.UserID = order.UserID
+	ret.Status = domain.ReturnRequested
+	ret.Comment = strings.TrimSpace(ret.Comment)
+	ret.RefundAmount = refund
+	now := time.Now()
+	ret.CreatedAt = now
+	ret.UpdatedAt = now
+	ret.History = []domain.ReturnStatusChange{{To: domain.ReturnRequested, Note: ret.Comment, At: now}}
+
+	return s.repo.CreateReturn(ctx, ret, domain.NewReturnStatusChanged(ret, "", domain.ReturnRequested))
+}
+
+// UpdateReturnStatus is a method for moving a return through the staff
+// workflow: requested returns are approved or rejected, approved ones are
+// received back into stock, and received ones are refunded to the user's balance
+func (s *service) UpdateReturnStatus(ctx context.Context, id string, status domain.ReturnStatus, note string) (*domain.Return, error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateReturnStatus")
+	defer span.End()
+
+	ret, err := s.repo.GetReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ret.Status.CanBecome(status) {
+		return nil, fmt.Errorf("%w: %s to %s", domain.ErrorReturnTransition, ret.Status, status)
+	}
+
+	change := domain.ReturnStatusChange{From: ret.Status, To: status, Note: strings.TrimSpace(note), At: time.Now()}
+	if err := s.repo.UpdateReturnStatus(ctx, ret, change, domain.NewReturnStatusChanged(ret, change.From, change.To)); err != nil {
+		return nil, err
+	}
+
+	ret.Status = status
+	ret.UpdatedAt = change.At
+	ret.History = append(ret.History, change)
+	return ret, nil
+}
+
+func (s *service) GetReturn(ctx context.Context, id string) (*domain.Return, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetReturn")
+	defer span.End()
+
+	return s.repo.GetReturn(ctx, id)
+}
+
+func (s *service) ListReturns(ctx context.Context, orderID string) ([]*domain.Return, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListReturns")
+	defer span.End()
+
+	if _, err := s.repo.GetOrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReturns(ctx, orderID)
+}
+
+// returnable returns the order lines with the quantities that are not part of
+// a return yet. Rejected returns free their items again.
+func returnable(order *domain.Order, returns []*domain.Return) map[domain.OrderLine]domain.OrderItem {
+	lines := make(map[domain.OrderLine]domain.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		lines[item.Line()] = item
+	}
+	for _, ret := range returns {
+		if ret.Status == domain.ReturnRejected {
+			continue
+		}
+		for _, item := range ret.Items {
+			line := lines[item.Line()]
+			line.Quantity -= item.Quantity
+			lines[item.Line()] = line
+		}
+	}
+	return lines
+}
+
+// validateReturnItems checks the requested items against the returnable order
+// lines and returns their refund.
+func validateReturnItems(items []domain.ReturnItem, lines map[domain.OrderLine]domain.OrderItem, currency domain.Currency) (domain.Money, error) {
+	if len(items) == 0 {
+		return domain.Money{}, fmt.Errorf("%w: at least one item is required", domain.ErrorInvalidReturn)
+	}
+
+	refund := domain.NewMoney(0, currency)
+	seen := make(map[domain.OrderLine]bool, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return domain.Money{}, domain.ErrorInvalidQuantity
+		}
+		if !item.Reason.Valid() {
+			return domain.Money{}, fmt.Errorf("%w: unknown reason %q", domain.ErrorInvalidReturn, item.Reason)
+		}
+		if seen[item.Line()] {
+			return domain.Money{}, fmt.Errorf("%w: %s is listed twice", domain.ErrorInvalidReturn, item.Line())
+		}
+		seen[item.Line()] = true
+
+		line, ok := lines[item.Line()]
+		if !ok {
+			return domain.Money{}, fmt.Errorf("%w: %s is not part of the order", domain.ErrorInvalidReturn, item.Line())
+		}
+		if item.Quantity > line.Quantity {
+			return domain.Money{}, fmt.Errorf("%w: only %d of %s can be returned", domain.ErrorInvalidReturn, max(line.Quantity, 0), item.Line())
+		}
+		price, err := line.Price.Mul(item.Quantity)
+		if err != nil {
+			return domain.Money{}, err
+		}
+		if refund, err = refund.Add(price); err != nil {
+			return domain.Money{}, err
+		}
+	}
+	return refund, nil
+}
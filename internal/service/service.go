@@ -8,44 +8,178 @@ import (
 	"time"
 
 	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/events"
+	"github.com/aibekfatkhulla/shop/internal/metrics"
 	"github.com/aibekfatkhulla/shop/internal/server"
+	"github.com/aibekfatkhulla/shop/internal/storage"
+	"github.com/aibekfatkhulla/shop/internal/webhook"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/aibekfatkhulla/shop/internal/service")
+
 const salt = "6472386&*@^&*@#^&*@#^364732#@&^@*&hjdskdhkjashd38247328&@#*$&@#7283"
 
 type service struct {
 	repo Repository
+
+	idempotencyTTL time.Duration
+
+	accountMailer          AccountMailer
+	emailVerificationTTL   time.Duration
+	passwordResetTTL       time.Duration
+	requireVerifiedToOrder bool
+	pendingOrderTTL        time.Duration
+	fulfillment            domain.FulfillmentStrategy
+	currency               domain.Currency
+
+	eventSink        events.Sink
+	eventBatchSize   int
+	eventMaxAttempts int
+	eventBackoff     time.Duration
+
+	webhookSender      webhook.Sender
+	webhookMaxAttempts int
+	webhookBackoff     time.Duration
+
+	blobs         storage.BlobStore
+	imageMaxBytes int64
 }
 
 //go:generate mockgen -source=service.go -destination=../mocks/repository.go -package=mocks Repository
 type Repository interface {
-	CreateUser(ctx context.Context, user *domain.User) error
+	Ping(ctx context.Context) error
+
+	CreateUser(ctx context.Context, user *domain.User, events ...domain.Event) error
 	UpdateUser(ctx context.Context, user *domain.User) error
+	PatchUser(ctx context.Context, user *domain.User, mask domain.FieldMask) error
 	GetUserByID(ctx context.Context, id string) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	ListUsers(ctx context.Context) ([]*domain.User, error)
 
+	CreateAddress(ctx context.Context, address *domain.Address) error
+	GetAddress(ctx context.Context, userID, id string) (*domain.Address, error)
+	GetDefaultAddress(ctx context.Context, userID string) (*domain.Address, error)
+	ListAddresses(ctx context.Context, userID string) ([]*domain.Address, error)
+	UpdateAddress(ctx context.Context, address *domain.Address) error
+	DeleteAddress(ctx context.Context, userID, id string) error
+
+	CreateUserToken(ctx context.Context, token *domain.UserToken) error
+	VerifyEmail(ctx context.Context, hash string, now time.Time) error
+	ResetPassword(ctx context.Context, hash, password string, now time.Time) error
+
 	GetProductByID(ctx context.Context, id string) (*domain.Product, error)
 	ListProducts(ctx context.Context, limit, offset int) ([]*domain.Product, error)
-
-	CreateOrder(ctx context.Context, order *domain.Order) error
+	PatchProduct(ctx context.Context, product *domain.Product, mask domain.FieldMask) error
+	CreateVariant(ctx context.Context, variant *domain.Variant) error
+	SetProductPrice(ctx context.Context, productID string, price domain.Money) error
+	DeleteProductPrice(ctx context.Context, productID string, currency domain.Currency) error
+
+	CreateProductImage(ctx context.Context, img *domain.ProductImage) error
+	GetProductImage(ctx context.Context, productID, id string) (*domain.ProductImage, error)
+	ListProductImages(ctx context.Context, productID string) ([]*domain.ProductImage, error)
+	ReorderProductImages(ctx context.Context, productID string, ids []string) error
+	DeleteProductImage(ctx context.Context, productID, id string) error
+
+	CreateOrder(ctx context.Context, order *domain.Order, strategy domain.FulfillmentStrategy, events ...domain.Event) error
 	GetOrderByID(ctx context.Context, ID string) (*domain.Order, error)
-	UpdateOrder(ctx context.Context, order *domain.Order) error
+	UpdateOrder(ctx context.Context, order *domain.Order, events ...domain.Event) error
+	PatchOrder(ctx context.Context, order *domain.Order, mask domain.FieldMask, events ...domain.Event) error
+	PayOrder(ctx context.Context, order *domain.Order, events ...domain.Event) error
+	CancelOrder(ctx context.Context, order *domain.Order, events ...domain.Event) error
+
+	CreateShipment(ctx context.Context, shipment *domain.Shipment, order *domain.Order, events ...domain.Event) error
+	DeliverShipment(ctx context.Context, shipment *domain.Shipment, order *domain.Order, events ...domain.Event) error
+	ListShipments(ctx context.Context, orderID string) ([]*domain.Shipment, error)
+
+	CreateReturn(ctx context.Context, ret *domain.Return, events ...domain.Event) error
+	UpdateReturnStatus(ctx context.Context, ret *domain.Return, change domain.ReturnStatusChange, events ...domain.Event) error
+	GetReturn(ctx context.Context, id string) (*domain.Return, error)
+	ListReturns(ctx context.Context, orderID string) ([]*domain.Return, error)
+
+	CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error
+	ListWarehouses(ctx context.Context) ([]*domain.Warehouse, error)
+	AdjustStock(ctx context.Context, m *domain.InventoryMovement, events ...domain.Event) error
+	ListInventoryMovements(ctx context.Context, productID string, limit, offset int) ([]*domain.InventoryMovement, error)
+	ListStockDiscrepancies(ctx context.Context) ([]domain.StockDiscrepancy, error)
+
+	ListReorderLines(ctx context.Context) ([]domain.ReorderLine, error)
+	ClaimLowStock(ctx context.Context, limit int, at time.Time) ([]domain.ReorderLine, error)
+
+	CancelStaleOrders(ctx context.Context, createdBefore time.Time, limit int, at time.Time) ([]*domain.Order, error)
+	SaveJobRun(ctx context.Context, run *domain.JobRun) error
+	ListJobRuns(ctx context.Context) ([]*domain.JobRun, error)
 
 	AddProductToCategory(ctx context.Context, categoryID, productID string) error
 	RemoveProductFromCategory(ctx context.Context, categoryID, productID string) error
 
 	GetSupplierByID(ctx context.Context, id string) (*domain.Supplier, error)
 	DeleteSupplierByID(ctx context.Context, id string) error
+
+	CreateIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error
+	GetIdempotencyKey(ctx context.Context, key, scope string) (*domain.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, key *domain.IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, key, scope string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+
+	AddEvents(ctx context.Context, events ...domain.Event) error
+	ClaimEvents(ctx context.Context, limit, maxAttempts int, now, leaseUntil time.Time) ([]domain.Event, error)
+	MarkEventDispatched(ctx context.Context, id string, at time.Time) error
+	FailEvent(ctx context.Context, id string, cause error, retryAt time.Time) error
+
+	CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error
+	GetWebhookSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id string) error
+	EnqueueWebhookDeliveries(ctx context.Context, event domain.Event, payload []byte, now time.Time) (int64, error)
+	CreateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error
+	ClaimWebhookDeliveries(ctx context.Context, limit int, now, leaseUntil time.Time) ([]*domain.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, d *domain.WebhookDelivery) error
+	GetWebhookDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+func NewService(repo Repository, opts ...Option) server.Service {
+	s := &service{
+		repo:           repo,
+		idempotencyTTL: defaultIdempotencyTTL,
+
+		emailVerificationTTL: defaultEmailVerificationTTL,
+		passwordResetTTL:     defaultPasswordResetTTL,
+		pendingOrderTTL:      defaultPendingOrderTTL,
+		fulfillment:          domain.FulfillNearest,
+		currency:             domain.DefaultCurrency,
+
+		eventSink:        events.LogSink{},
+		eventBatchSize:   defaultEventBatchSize,
+		eventMaxAttempts: defaultEventMaxAttempts,
+		eventBackoff:     defaultEventBackoff,
+
+		webhookMaxAttempts: defaultWebhookMaxAttempts,
+		webhookBackoff:     defaultWebhookBackoff,
+
+		imageMaxBytes: defaultImageMaxBytes,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func NewService(repo Repository) server.Service {
-	return &service{repo: repo}
+// Ping is a method for checking that the storage is reachable
+func (s *service) Ping(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "Service.Ping")
+	defer span.End()
+
+	return s.repo.Ping(ctx)
 }
 
 // CreateUser is a method for creating a new user in the system
 func (s *service) CreateUser(ctx context.Context, user *domain.User) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateUser")
+	defer span.End()
+
 	_, err := s.repo.GetByEmail(ctx, user.Email)
 	if err != nil && !errors.Is(err, domain.ErrorUserNotFound) {
 		return err
@@ -55,6 +189,10 @@ func (s *service) CreateUser(ctx context.Context, user *domain.User) error {
 		return domain.ErrorUserAlreadyExists
 	}
 
+	// New users start with nothing; the balance only moves through payments
+	// and refunds, never through what the client sends on sign-up.
+	user.Balance = domain.NewMoney(0, s.currency)
+
 	user.ID = uuid.New().String()
 	now := time.Now()
 
@@ -63,12 +201,13 @@ func (s *service) CreateUser(ctx context.Context, user *domain.User) error {
 
 	user.Password = hashPassword(user.Password)
 
-	err = s.repo.CreateUser(ctx, user)
-	if err != nil {
-		return err
+	// The verification email is sent by the event dispatcher, so signing up
+	// does not wait for the mailer.
+	events := []domain.Event{domain.NewUserRegistered(user)}
+	if s.accountMailer != nil {
+		events = append(events, domain.NewAccountTokenRequested(user, domain.TokenEmailVerification))
 	}
-
-	return nil
+	return s.repo.CreateUser(ctx, user, events...)
 }
 
 // hashPassword is a method for hash users' passwords
@@ -82,14 +221,24 @@ func hashPassword(password string) string {
 	return fmt.Sprintf("%x", hashSum)
 }
 
-// UpdateUser is a method for updating user
+// UpdateUser is a method for updating user; user.Version must match the stored version
 func (s *service) UpdateUser(ctx context.Context, user *domain.User) error {
+	ctx, span := tracer.Start(ctx, "Service.UpdateUser")
+	defer span.End()
+
 	existingUser, err := s.repo.GetUserByID(ctx, user.ID)
 
 	if err != nil {
 		return err
 	}
 
+	if user.Version == domain.AnyVersion {
+		user.Version = existingUser.Version
+	}
+	if user.Version != existingUser.Version {
+		return domain.ErrorVersionConflict
+	}
+
 	user.Balance = existingUser.Balance
 
 	if user.Password == "" {
@@ -105,6 +254,9 @@ func (s *service) UpdateUser(ctx context.Context, user *domain.User) error {
 }
 
 func (s *service) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetUserByID")
+	defer span.End()
+
 	user, err := s.repo.GetUserByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -113,6 +265,9 @@ func (s *service) GetUserByID(ctx context.Context, id string) (*domain.User, err
 }
 
 func (s *service) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetByEmail")
+	defer span.End()
+
 	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
@@ -121,11 +276,17 @@ func (s *service) GetByEmail(ctx context.Context, email string) (*domain.User, e
 }
 
 func (s *service) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListUsers")
+	defer span.End()
+
 	return s.repo.ListUsers(ctx)
 }
 
 // GetProductByID is a method for finding a product by products ID
 func (s *service) GetProductByID(ctx context.Context, id string) (*domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetProductByID")
+	defer span.End()
+
 	product, err := s.repo.GetProductByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -134,6 +295,9 @@ func (s *service) GetProductByID(ctx context.Context, id string) (*domain.Produc
 }
 
 func (s *service) ListProducts(ctx context.Context, limit, offset int) ([]*domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListProducts")
+	defer span.End()
+
 	if limit < 1 {
 		limit = 10
 	}
@@ -148,56 +312,221 @@ func (s *service) ListProducts(ctx context.Context, limit, offset int) ([]*domai
 	return products, nil
 }
 
+// CreateOrder is a method for placing an order; stock for every item is reserved,
+// the item prices are fixed at the current product prices and the selected
+// addresses are copied into the order
 func (s *service) CreateOrder(ctx context.Context, order *domain.Order) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateOrder")
+	defer span.End()
+
+	if s.requireVerifiedToOrder {
+		user, err := s.repo.GetUserByID(ctx, order.UserID)
+		if err != nil {
+			return err
+		}
+		if !user.EmailVerified {
+			return domain.ErrorEmailNotVerified
+		}
+	}
+
+	items, err := mergeOrderItems(order.Items)
+	if err != nil {
+		return err
+	}
+	order.Items = items
+
+	if order.Currency == "" {
+		order.Currency = s.currency
+	}
+	if !order.Currency.Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrorInvalidCurrency, order.Currency)
+	}
+
+	if err := s.orderAddresses(ctx, order); err != nil {
+		return err
+	}
+
+	order.ID = uuid.New().String()
 	now := time.Now()
 	order.CreatedAt = now
 	order.UpdatedAt = now
 
-	if order.Status == "" {
-		order.Status = domain.StatusPending
+	// Orders only leave pending through payment or cancellation.
+	order.Status = domain.StatusPending
+
+	if err := s.repo.CreateOrder(ctx, order, s.fulfillment, domain.NewOrderCreated(order)); err != nil {
+		return err
 	}
 
-	return s.repo.CreateOrder(ctx, order)
+	metrics.OrdersCreated.Inc()
+	return nil
+}
+
+// mergeOrderItems validates the requested items and folds repeated products, or
+// variants, into one line.
+func mergeOrderItems(items []domain.OrderItem) ([]domain.OrderItem, error) {
+	if len(items) == 0 {
+		return nil, domain.ErrorOrderHasNoItems
+	}
+
+	merged := make([]domain.OrderItem, 0, len(items))
+	index := make(map[domain.OrderLine]int, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, domain.ErrorInvalidQuantity
+		}
+		if i, ok := index[item.Line()]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.Line()] = len(merged)
+		merged = append(merged, domain.OrderItem{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity})
+	}
+	return merged, nil
 }
 
 func (s *service) GetOrderByID(ctx context.Context, id string) (*domain.Order, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetOrderByID")
+	defer span.End()
+
 	return s.repo.GetOrderByID(ctx, id)
 }
 
+// UpdateOrder is a method for changing the order status; order.Version must match the stored version.
+// An empty status keeps the current one. See changeOrderStatus for the changes a client can make.
 func (s *service) UpdateOrder(ctx context.Context, order *domain.Order) error {
+	ctx, span := tracer.Start(ctx, "Service.UpdateOrder")
+	defer span.End()
+
 	if order == nil {
 		return domain.ErrorOrderNotFound
 	}
 
-	_, err := s.repo.GetOrderByID(ctx, order.ID)
+	existingOrder, err := s.repo.GetOrderByID(ctx, order.ID)
 	if err != nil {
 		return err
 	}
 
+	if order.Version == domain.AnyVersion {
+		order.Version = existingOrder.Version
+	}
+	if order.Version != existingOrder.Version {
+		return domain.ErrorVersionConflict
+	}
+
 	if order.Status == "" {
-		order.Status = domain.StatusCompleted
+		order.Status = existingOrder.Status
 	}
-	now := time.Now()
-	order.UpdatedAt = now
+	if !order.Status.Valid() {
+		return fmt.Errorf("%w: %q", domain.ErrorInvalidStatus, order.Status)
+	}
+	if order.Status != existingOrder.Status {
+		changed, err := s.changeOrderStatus(ctx, existingOrder, order.Status)
+		if err != nil {
+			return err
+		}
+		*order = *changed
+		return nil
+	}
+
+	// Only the status can be changed; the rest is reported as stored.
+	order.UserID = existingOrder.UserID
+	order.CreatedAt = existingOrder.CreatedAt
+	order.Currency = existingOrder.Currency
+	order.Items = existingOrder.Items
+	order.ShippingAddress = existingOrder.ShippingAddress
+	order.BillingAddress = existingOrder.BillingAddress
 
-	if err := s.repo.UpdateOrder(ctx, order); err != nil {
+	order.UpdatedAt = time.Now()
+	return s.repo.UpdateOrder(ctx, order)
+}
+
+// changeOrderStatus moves the stored order to status on behalf of a client.
+// Paying debits the user's balance like PayOrder and canceling releases the
+// reserved stock like the stale order job; the later statuses follow from
+// shipments, so any other change fails with domain.ErrorOrderTransition.
+// It returns the order as stored afterwards.
+func (s *service) changeOrderStatus(ctx context.Context, order *domain.Order, status domain.Status) (*domain.Order, error) {
+	switch {
+	case order.Status == domain.StatusPending && status == domain.StatusPaid:
+		return order, s.payOrder(ctx, order)
+	case order.Status == domain.StatusPending && status == domain.StatusCanceled:
+		return order, s.cancelOrder(ctx, order)
+	}
+	return nil, fmt.Errorf("%w: %s to %s", domain.ErrorOrderTransition, order.Status, status)
+}
+
+// cancelOrder cancels the pending order and puts its items back into stock.
+func (s *service) cancelOrder(ctx context.Context, order *domain.Order) error {
+	order.UpdatedAt = time.Now()
+	canceled := domain.NewOrderStatusChanged(order, domain.StatusPending, domain.StatusCanceled)
+	if err := s.repo.CancelOrder(ctx, order, canceled); err != nil {
 		return err
 	}
+
+	metrics.OrderStatusTransitions.WithLabelValues(string(domain.StatusPending), string(domain.StatusCanceled)).Inc()
 	return nil
 }
 
 func (s *service) AddProductToCategory(ctx context.Context, categoryID, productID string) error {
+	ctx, span := tracer.Start(ctx, "Service.AddProductToCategory")
+	defer span.End()
+
 	return s.repo.AddProductToCategory(ctx, categoryID, productID)
 }
 
 func (s *service) RemoveProductFromCategory(ctx context.Context, categoryID, productID string) error {
+	ctx, span := tracer.Start(ctx, "Service.RemoveProductFromCategory")
+	defer span.End()
+
 	return s.repo.RemoveProductFromCategory(ctx, categoryID, productID)
 }
 
 func (s *service) GetSupplierByID(ctx context.Context, id string) (*domain.Supplier, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetSupplierByID")
+	defer span.End()
+
 	return s.repo.GetSupplierByID(ctx, id)
 }
 
 func (s *service) DeleteSupplierByID(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteSupplierByID")
+	defer span.End()
+
 	return s.repo.DeleteSupplierByID(ctx, id)
 }
+
+// PayOrder is a method for paying a pending order from the user's balance
+func (s *service) PayOrder(ctx context.Context, id string) (*domain.Order, error) {
+	ctx, span := tracer.Start(ctx, "Service.PayOrder")
+	defer span.End()
+
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.payOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// payOrder debits the total of the pending order from the user's balance and
+// marks it paid.
+func (s *service) payOrder(ctx context.Context, order *domain.Order) error {
+	if order.Status != domain.StatusPending {
+		return domain.ErrorOrderNotPending
+	}
+
+	order.UpdatedAt = time.Now()
+	paid := domain.NewOrderStatusChanged(order, domain.StatusPending, domain.StatusPaid)
+	if err := s.repo.PayOrder(ctx, order, paid); err != nil {
+		return err
+	}
+
+	metrics.OrderStatusTransitions.WithLabelValues(string(domain.StatusPending), string(domain.StatusPaid)).Inc()
+	if total, err := order.Total(); err == nil {
+		metrics.Revenue.WithLabelValues(string(total.Currency)).Add(total.Float64())
+	}
+	return nil
+}
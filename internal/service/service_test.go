@@ -1,13 +1,26 @@
 package service_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/events"
 	"github.com/aibekfatkhulla/shop/internal/mocks"
 	"github.com/aibekfatkhulla/shop/internal/service"
+	"github.com/aibekfatkhulla/shop/internal/storage"
+	"github.com/aibekfatkhulla/shop/internal/webhook"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -32,12 +45,15 @@ func TestCreateUser(t *testing.T) {
 				Email:    "qwe@qwe.qwe",
 				Number:   "123123123",
 				Address:  "the capella",
-				Balance:  100,
+				Balance:  kzt(100),
 			},
 			func() service.Repository {
 				r := mocks.NewMockRepository(ctrl)
 
-				r.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+				r.EXPECT().CreateUser(gomock.Any(), gomock.Any(), eventOfType(domain.EventUserRegistered)).DoAndReturn(func(_ any, user *domain.User, _ ...domain.Event) error {
+					assert.Equal(t, kzt(0), user.Balance)
+					return nil
+				})
 				r.EXPECT().GetByEmail(gomock.Any(), "qwe@qwe.qwe").Return(nil, domain.ErrorUserNotFound)
 
 				return r
@@ -52,7 +68,7 @@ func TestCreateUser(t *testing.T) {
 				Email:    "qwe@qwe.qwe",
 				Number:   "123123123",
 				Address:  "the capella",
-				Balance:  100,
+				Balance:  kzt(100),
 			},
 			func() service.Repository {
 				r := mocks.NewMockRepository(ctrl)
@@ -63,7 +79,7 @@ func TestCreateUser(t *testing.T) {
 					Email:     "qwe@qwe.qwe",
 					Number:    "123123123",
 					Address:   "the capella",
-					Balance:   100,
+					Balance:   kzt(100),
 					CreatedAt: time.Now(),
 					UpdatedAt: time.Now(),
 				}, nil)
@@ -80,7 +96,7 @@ func TestCreateUser(t *testing.T) {
 				Email:    "qwe@qwe.qwe",
 				Number:   "123123123",
 				Address:  "the capella",
-				Balance:  100,
+				Balance:  kzt(100),
 			},
 			func() service.Repository {
 				r := mocks.NewMockRepository(ctrl)
@@ -98,12 +114,12 @@ func TestCreateUser(t *testing.T) {
 				Email:    "qwe@qwe.qwe",
 				Number:   "123123123",
 				Address:  "the capella",
-				Balance:  100,
+				Balance:  kzt(100),
 			},
 			func() service.Repository {
 				r := mocks.NewMockRepository(ctrl)
 				r.EXPECT().GetByEmail(gomock.Any(), "qwe@qwe.qwe").Return(nil, domain.ErrorUserNotFound)
-				r.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(dbErr)
+				r.EXPECT().CreateUser(gomock.Any(), gomock.Any(), eventOfType(domain.EventUserRegistered)).Return(dbErr)
 
 				return r
 			}(),
@@ -163,6 +179,61 @@ func TestUpdateOrder(t *testing.T) {
 			},
 			expectedErr: dbErr,
 		},
+		{
+			name: "any version",
+			inputOrder: &domain.Order{
+				ID:      "123",
+				Version: domain.AnyVersion,
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(&domain.Order{ID: "123", Status: domain.StatusPending, Version: 5}, nil)
+				r.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order, _ ...domain.Event) error {
+					assert.Equal(t, 5, order.Version)
+					return nil
+				})
+				return r
+			},
+		},
+		{
+			name: "keeps what is not changed",
+			inputOrder: &domain.Order{
+				ID:       "123",
+				Currency: "USD",
+			},
+			mockSetup: func() service.Repository {
+				stored := &domain.Order{
+					ID:              "123",
+					Status:          domain.StatusPending,
+					Currency:        "KZT",
+					ShippingAddress: &domain.Address{ID: "a1"},
+					BillingAddress:  &domain.Address{ID: "a2"},
+				}
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(stored, nil)
+				r.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, order *domain.Order, _ ...domain.Event) error {
+					assert.Equal(t, domain.Currency("KZT"), order.Currency)
+					assert.Equal(t, stored.ShippingAddress, order.ShippingAddress)
+					assert.Equal(t, stored.BillingAddress, order.BillingAddress)
+					return nil
+				})
+				return r
+			},
+		},
+		{
+			name: "stale version",
+			inputOrder: &domain.Order{
+				ID:      "123",
+				Status:  domain.StatusPaid,
+				Version: 1,
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), order.ID).Return(&domain.Order{ID: "123", Version: 2}, nil)
+				return r
+			},
+			expectedErr: domain.ErrorVersionConflict,
+		},
 		{
 			name:       "update order error",
 			inputOrder: order,
@@ -190,3 +261,1822 @@ func TestUpdateOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderStatusTransitions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stored := func(status domain.Status) *domain.Order {
+		return &domain.Order{
+			ID:       "123",
+			UserID:   "999",
+			Status:   status,
+			Currency: "KZT",
+			Items:    []domain.OrderItem{{ProductID: "p1", Quantity: 2, Price: kzt(50)}},
+			Version:  3,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		from, to    domain.Status
+		expect      func(r *mocks.MockRepository)
+		expectedErr error
+	}{
+		{
+			name: "paying debits the balance",
+			from: domain.StatusPending, to: domain.StatusPaid,
+			expect: func(r *mocks.MockRepository) {
+				r.EXPECT().PayOrder(gomock.Any(), gomock.Any(), eventOfType(domain.EventOrderStatusChanged)).DoAndReturn(func(_ any, order *domain.Order, _ ...domain.Event) error {
+					order.Status = domain.StatusPaid
+					return nil
+				})
+			},
+		},
+		{
+			name: "canceling releases stock",
+			from: domain.StatusPending, to: domain.StatusCanceled,
+			expect: func(r *mocks.MockRepository) {
+				r.EXPECT().CancelOrder(gomock.Any(), gomock.Any(), eventOfType(domain.EventOrderStatusChanged)).DoAndReturn(func(_ any, order *domain.Order, _ ...domain.Event) error {
+					assert.Equal(t, 3, order.Version)
+					order.Status = domain.StatusCanceled
+					return nil
+				})
+			},
+		},
+		{
+			name: "unchanged status is written as is",
+			from: domain.StatusPaid, to: domain.StatusPaid,
+			expect: func(r *mocks.MockRepository) {
+				r.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "completing is left to shipments",
+			from: domain.StatusPending, to: domain.StatusCompleted,
+			expectedErr: domain.ErrorOrderTransition,
+		},
+		{
+			name: "paid orders cannot be canceled",
+			from: domain.StatusPaid, to: domain.StatusCanceled,
+			expectedErr: domain.ErrorOrderTransition,
+		},
+		{
+			name: "canceled orders cannot be reopened",
+			from: domain.StatusCanceled, to: domain.StatusPending,
+			expectedErr: domain.ErrorOrderTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("update/"+tt.name, func(t *testing.T) {
+			r := mocks.NewMockRepository(ctrl)
+			r.EXPECT().GetOrderByID(gomock.Any(), "123").Return(stored(tt.from), nil)
+			if tt.expect != nil {
+				tt.expect(r)
+			}
+
+			order := &domain.Order{ID: "123", Status: tt.to, Version: 3}
+			err := service.NewService(r).UpdateOrder(t.Context(), order)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.to, order.Status)
+			assert.Equal(t, "999", order.UserID)
+		})
+
+		t.Run("patch/"+tt.name, func(t *testing.T) {
+			r := mocks.NewMockRepository(ctrl)
+			r.EXPECT().GetOrderByID(gomock.Any(), "123").Return(stored(tt.from), nil)
+			if tt.from == tt.to {
+				r.EXPECT().PatchOrder(gomock.Any(), gomock.Any(), domain.FieldMask{"status"}).Return(nil)
+			} else if tt.expect != nil {
+				tt.expect(r)
+			}
+
+			order := &domain.Order{ID: "123", Status: tt.to, Version: 3}
+			err := service.NewService(r).PatchOrder(t.Context(), order, domain.FieldMask{"status"})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.to, order.Status)
+		})
+	}
+
+	t.Run("unknown status", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetOrderByID(gomock.Any(), "123").Return(stored(domain.StatusPending), nil)
+
+		err := service.NewService(r).UpdateOrder(t.Context(), &domain.Order{ID: "123", Status: "shipped", Version: 3})
+		assert.ErrorIs(t, err, domain.ErrorInvalidStatus)
+	})
+}
+
+func TestCreateOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dbErr := errors.New("db error")
+
+	tests := []struct {
+		name          string
+		inputOrder    *domain.Order
+		mockSetup     func() service.Repository
+		expectedItems []domain.OrderItem
+		expectedErr   error
+	}{
+		{
+			name: "success merges repeated products",
+			inputOrder: &domain.Order{
+				UserID: "999",
+				Status: domain.StatusPaid,
+				Items: []domain.OrderItem{
+					{ProductID: "p1", Quantity: 1},
+					{ProductID: "p2", Quantity: 3},
+					{ProductID: "p1", Quantity: 2},
+				},
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetDefaultAddress(gomock.Any(), "999").Return(nil, domain.ErrorAddressNotFound)
+				r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), domain.FulfillNearest, eventOfType(domain.EventOrderCreated)).DoAndReturn(func(_ any, order *domain.Order, _ domain.FulfillmentStrategy, _ ...domain.Event) error {
+					assert.NotEmpty(t, order.ID)
+					assert.Equal(t, domain.StatusPending, order.Status)
+					return nil
+				})
+				return r
+			},
+			expectedItems: []domain.OrderItem{
+				{ProductID: "p1", Quantity: 3},
+				{ProductID: "p2", Quantity: 3},
+			},
+		},
+		{
+			name: "variants of a product are separate lines",
+			inputOrder: &domain.Order{
+				UserID: "999",
+				Items: []domain.OrderItem{
+					{ProductID: "p1", VariantID: "m", Quantity: 1},
+					{ProductID: "p1", VariantID: "l", Quantity: 2},
+					{ProductID: "p1", VariantID: "m", Quantity: 1},
+				},
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetDefaultAddress(gomock.Any(), "999").Return(nil, domain.ErrorAddressNotFound)
+				r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), domain.FulfillNearest, eventOfType(domain.EventOrderCreated)).Return(nil)
+				return r
+			},
+			expectedItems: []domain.OrderItem{
+				{ProductID: "p1", VariantID: "m", Quantity: 2},
+				{ProductID: "p1", VariantID: "l", Quantity: 2},
+			},
+		},
+		{
+			name: "default address is copied for shipping and billing",
+			inputOrder: &domain.Order{
+				UserID: "999",
+				Items:  []domain.OrderItem{{ProductID: "p1", Quantity: 1}},
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetDefaultAddress(gomock.Any(), "999").Return(&domain.Address{ID: "a1", UserID: "999", City: "Almaty", IsDefault: true}, nil)
+				r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), domain.FulfillNearest, eventOfType(domain.EventOrderCreated)).DoAndReturn(func(_ any, order *domain.Order, _ domain.FulfillmentStrategy, _ ...domain.Event) error {
+					assert.Equal(t, "Almaty", order.ShippingAddress.City)
+					assert.Same(t, order.ShippingAddress, order.BillingAddress)
+					return nil
+				})
+				return r
+			},
+			expectedItems: []domain.OrderItem{{ProductID: "p1", Quantity: 1}},
+		},
+		{
+			name: "selected addresses are copied",
+			inputOrder: &domain.Order{
+				UserID:          "999",
+				Items:           []domain.OrderItem{{ProductID: "p1", Quantity: 1}},
+				ShippingAddress: &domain.Address{ID: "a1"},
+				BillingAddress:  &domain.Address{ID: "a2"},
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetAddress(gomock.Any(), "999", "a1").Return(&domain.Address{ID: "a1", City: "Almaty"}, nil)
+				r.EXPECT().GetAddress(gomock.Any(), "999", "a2").Return(&domain.Address{ID: "a2", City: "Astana"}, nil)
+				r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), domain.FulfillNearest, eventOfType(domain.EventOrderCreated)).DoAndReturn(func(_ any, order *domain.Order, _ domain.FulfillmentStrategy, _ ...domain.Event) error {
+					assert.Equal(t, "Almaty", order.ShippingAddress.City)
+					assert.Equal(t, "Astana", order.BillingAddress.City)
+					return nil
+				})
+				return r
+			},
+			expectedItems: []domain.OrderItem{{ProductID: "p1", Quantity: 1}},
+		},
+		{
+			name: "address of another user",
+			inputOrder: &domain.Order{
+				UserID:          "999",
+				Items:           []domain.OrderItem{{ProductID: "p1", Quantity: 1}},
+				ShippingAddress: &domain.Address{ID: "a9"},
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetAddress(gomock.Any(), "999", "a9").Return(nil, domain.ErrorAddressNotFound)
+				return r
+			},
+			expectedErr: domain.ErrorAddressNotFound,
+		},
+		{
+			name:       "no items",
+			inputOrder: &domain.Order{UserID: "999"},
+			mockSetup: func() service.Repository {
+				return mocks.NewMockRepository(ctrl)
+			},
+			expectedErr: domain.ErrorOrderHasNoItems,
+		},
+		{
+			name: "invalid quantity",
+			inputOrder: &domain.Order{
+				UserID: "999",
+				Items:  []domain.OrderItem{{ProductID: "p1", Quantity: 0}},
+			},
+			mockSetup: func() service.Repository {
+				return mocks.NewMockRepository(ctrl)
+			},
+			expectedErr: domain.ErrorInvalidQuantity,
+		},
+		{
+			name: "insufficient stock",
+			inputOrder: &domain.Order{
+				UserID: "999",
+				Items:  []domain.OrderItem{{ProductID: "p1", Quantity: 5}},
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetDefaultAddress(gomock.Any(), "999").Return(nil, domain.ErrorAddressNotFound)
+				r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), domain.FulfillNearest, eventOfType(domain.EventOrderCreated)).Return(domain.ErrorInsufficientStock)
+				return r
+			},
+			expectedErr: domain.ErrorInsufficientStock,
+		},
+		{
+			name: "db error",
+			inputOrder: &domain.Order{
+				UserID: "999",
+				Items:  []domain.OrderItem{{ProductID: "p1", Quantity: 5}},
+			},
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetDefaultAddress(gomock.Any(), "999").Return(nil, domain.ErrorAddressNotFound)
+				r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), domain.FulfillNearest, eventOfType(domain.EventOrderCreated)).Return(dbErr)
+				return r
+			},
+			expectedErr: dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := service.NewService(tt.mockSetup())
+
+			err := s.CreateOrder(t.Context(), tt.inputOrder)
+			if tt.expectedErr == nil {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedItems, tt.inputOrder.Items)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestPayOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pending := func() *domain.Order {
+		return &domain.Order{
+			ID:     "123",
+			UserID: "999",
+			Status: domain.StatusPending,
+			Items:  []domain.OrderItem{{ProductID: "p1", Quantity: 2, Price: kzt(50)}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func() service.Repository
+		expectedErr error
+	}{
+		{
+			name: "success",
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "123").Return(pending(), nil)
+				r.EXPECT().PayOrder(gomock.Any(), gomock.Any(), eventOfType(domain.EventOrderStatusChanged)).Return(nil)
+				return r
+			},
+		},
+		{
+			name: "order not found",
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "123").Return(nil, domain.ErrorOrderNotFound)
+				return r
+			},
+			expectedErr: domain.ErrorOrderNotFound,
+		},
+		{
+			name: "order already paid",
+			mockSetup: func() service.Repository {
+				order := pending()
+				order.Status = domain.StatusPaid
+
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "123").Return(order, nil)
+				return r
+			},
+			expectedErr: domain.ErrorOrderNotPending,
+		},
+		{
+			name: "insufficient balance",
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "123").Return(pending(), nil)
+				r.EXPECT().PayOrder(gomock.Any(), gomock.Any(), eventOfType(domain.EventOrderStatusChanged)).Return(domain.ErrorInsufficientBalance)
+				return r
+			},
+			expectedErr: domain.ErrorInsufficientBalance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := service.NewService(tt.mockSetup())
+
+			_, err := s.PayOrder(t.Context(), "123")
+			if tt.expectedErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestClaimIdempotencyKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name              string
+		mockSetup         func() service.Repository
+		expectedCompleted bool
+		expectedErr       error
+	}{
+		{
+			name: "new key",
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, key *domain.IdempotencyKey) error {
+					assert.Equal(t, time.Hour, key.ExpiresAt.Sub(key.CreatedAt))
+					return nil
+				})
+				return r
+			},
+		},
+		{
+			name: "completed key is replayed",
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Return(domain.ErrorIdempotencyKeyExists)
+				r.EXPECT().GetIdempotencyKey(gomock.Any(), "k1", "POST /api/v1/orders").Return(&domain.IdempotencyKey{
+					Key:         "k1",
+					Scope:       "POST /api/v1/orders",
+					Fingerprint: "fp",
+					Completed:   true,
+					StatusCode:  201,
+				}, nil)
+				return r
+			},
+			expectedCompleted: true,
+		},
+		{
+			name: "key reused with another request",
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Return(domain.ErrorIdempotencyKeyExists)
+				r.EXPECT().GetIdempotencyKey(gomock.Any(), "k1", "POST /api/v1/orders").Return(&domain.IdempotencyKey{
+					Fingerprint: "other",
+					Completed:   true,
+				}, nil)
+				return r
+			},
+			expectedErr: domain.ErrorIdempotencyKeyMismatch,
+		},
+		{
+			name: "key in progress",
+			mockSetup: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Return(domain.ErrorIdempotencyKeyExists)
+				r.EXPECT().GetIdempotencyKey(gomock.Any(), "k1", "POST /api/v1/orders").Return(&domain.IdempotencyKey{
+					Fingerprint: "fp",
+				}, nil)
+				return r
+			},
+			expectedErr: domain.ErrorIdempotencyKeyInProgress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := service.NewService(tt.mockSetup(), service.WithIdempotencyTTL(time.Hour))
+
+			record, err := s.ClaimIdempotencyKey(t.Context(), &domain.IdempotencyKey{
+				Key:         "k1",
+				Scope:       "POST /api/v1/orders",
+				Fingerprint: "fp",
+			})
+			if tt.expectedErr == nil {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedCompleted, record.Completed)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestPatchUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stored := func() *domain.User {
+		return &domain.User{
+			ID:       "123",
+			Name:     "arnur",
+			Email:    "qwe@qwe.qwe",
+			Password: "hash",
+			Number:   "123123123",
+			Address:  "the capella",
+			Balance:  kzt(100),
+			Version:  2,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		patch       *domain.User
+		mask        domain.FieldMask
+		repository  func() service.Repository
+		expected    *domain.User
+		expectedErr error
+	}{
+		{
+			name:  "only masked fields change",
+			patch: &domain.User{ID: "123", Number: "555", Version: 2},
+			mask:  domain.FieldMask{"address", "number"},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetUserByID(gomock.Any(), "123").Return(stored(), nil)
+				r.EXPECT().PatchUser(gomock.Any(), gomock.Any(), domain.FieldMask{"address", "number"}).
+					DoAndReturn(func(_ any, user *domain.User, _ domain.FieldMask) error {
+						user.Version = 3
+						return nil
+					})
+				return r
+			},
+			expected: &domain.User{
+				ID:       "123",
+				Name:     "arnur",
+				Email:    "qwe@qwe.qwe",
+				Password: "hash",
+				Number:   "555",
+				Address:  "",
+				Balance:  kzt(100),
+				Version:  3,
+			},
+		},
+		{
+			name:  "empty patch writes nothing",
+			patch: &domain.User{ID: "123", Version: 2},
+			mask:  domain.FieldMask{},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetUserByID(gomock.Any(), "123").Return(stored(), nil)
+				return r
+			},
+			expected: stored(),
+		},
+		{
+			name:  "stale version",
+			patch: &domain.User{ID: "123", Name: "new", Version: 1},
+			mask:  domain.FieldMask{"name"},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetUserByID(gomock.Any(), "123").Return(stored(), nil)
+				return r
+			},
+			expectedErr: domain.ErrorVersionConflict,
+		},
+		{
+			name:  "read-only field",
+			patch: &domain.User{ID: "123", Balance: kzt(1000), Version: 2},
+			mask:  domain.FieldMask{"balance"},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetUserByID(gomock.Any(), "123").Return(stored(), nil)
+				return r
+			},
+			expectedErr: domain.ErrorInvalidPatch,
+		},
+		{
+			name:  "changing the email clears verification",
+			patch: &domain.User{ID: "123", Email: "new@qwe.qwe", Version: 2},
+			mask:  domain.FieldMask{"email"},
+			repository: func() service.Repository {
+				verified := stored()
+				verified.EmailVerified = true
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetUserByID(gomock.Any(), "123").Return(verified, nil)
+				r.EXPECT().PatchUser(gomock.Any(), gomock.Any(), domain.FieldMask{"email"}).
+					DoAndReturn(func(_ any, user *domain.User, _ domain.FieldMask) error {
+						assert.False(t, user.EmailVerified)
+						user.Version = 3
+						return nil
+					})
+				return r
+			},
+			expected: func() *domain.User {
+				u := stored()
+				u.Email = "new@qwe.qwe"
+				u.Version = 3
+				return u
+			}(),
+		},
+		{
+			name:  "clearing a required field",
+			patch: &domain.User{ID: "123", Version: 2},
+			mask:  domain.FieldMask{"email"},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetUserByID(gomock.Any(), "123").Return(stored(), nil)
+				return r
+			},
+			expectedErr: domain.ErrorInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := service.NewService(tt.repository())
+
+			err := s.PatchUser(t.Context(), tt.patch, tt.mask)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			tt.patch.UpdatedAt = time.Time{}
+			assert.Equal(t, tt.expected, tt.patch)
+		})
+	}
+}
+
+func TestCreateOrderRequiresVerifiedEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name        string
+		verified    bool
+		expectedErr error
+	}{
+		{name: "verified user", verified: true},
+		{name: "unverified user", verified: false, expectedErr: domain.ErrorEmailNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mocks.NewMockRepository(ctrl)
+			r.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1", EmailVerified: tt.verified}, nil)
+			if tt.expectedErr == nil {
+				r.EXPECT().GetDefaultAddress(gomock.Any(), "u1").Return(nil, domain.ErrorAddressNotFound)
+				r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), domain.FulfillNearest, eventOfType(domain.EventOrderCreated)).Return(nil)
+			}
+
+			s := service.NewService(r, service.WithVerifiedEmailRequiredToOrder(true))
+			err := s.CreateOrder(t.Context(), &domain.Order{
+				UserID: "u1",
+				Items:  []domain.OrderItem{{ProductID: "p1", Quantity: 1}},
+			})
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestCreateAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	valid := func() *domain.Address {
+		return &domain.Address{UserID: "u1", Recipient: " Arnur ", Line1: "Abay 1", City: "Almaty", PostalCode: "050000", Country: "kz"}
+	}
+
+	tests := []struct {
+		name        string
+		address     *domain.Address
+		repository  func() service.Repository
+		expectedErr error
+	}{
+		{
+			name:    "success normalizes fields",
+			address: valid(),
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetUserByID(gomock.Any(), "u1").Return(&domain.User{ID: "u1"}, nil)
+				r.EXPECT().CreateAddress(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, a *domain.Address) error {
+					assert.NotEmpty(t, a.ID)
+					assert.Equal(t, "Arnur", a.Recipient)
+					assert.Equal(t, "KZ", a.Country)
+					return nil
+				})
+				return r
+			},
+		},
+		{
+			name: "invalid country",
+			address: func() *domain.Address {
+				a := valid()
+				a.Country = "Kazakhstan"
+				return a
+			}(),
+			repository: func() service.Repository {
+				return mocks.NewMockRepository(ctrl)
+			},
+			expectedErr: domain.ErrorInvalidAddress,
+		},
+		{
+			name: "missing city",
+			address: func() *domain.Address {
+				a := valid()
+				a.City = "  "
+				return a
+			}(),
+			repository: func() service.Repository {
+				return mocks.NewMockRepository(ctrl)
+			},
+			expectedErr: domain.ErrorInvalidAddress,
+		},
+		{
+			name:    "unknown user",
+			address: valid(),
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetUserByID(gomock.Any(), "u1").Return(nil, domain.ErrorUserNotFound)
+				return r
+			},
+			expectedErr: domain.ErrorUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := service.NewService(tt.repository())
+
+			err := s.CreateAddress(t.Context(), tt.address)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestCreateShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	order := func(status domain.Status) *domain.Order {
+		return &domain.Order{
+			ID:      "o1",
+			Status:  status,
+			Version: 4,
+			Items: []domain.OrderItem{
+				{ProductID: "p1", Quantity: 2, Price: kzt(10)},
+				{ProductID: "p2", Quantity: 1, Price: kzt(5)},
+			},
+		}
+	}
+	shipped := []*domain.Shipment{{ID: "s1", OrderID: "o1", Items: []domain.ShipmentItem{{ProductID: "p1", Quantity: 1}}}}
+
+	tests := []struct {
+		name          string
+		shipment      *domain.Shipment
+		repository    func() service.Repository
+		expectedItems []domain.ShipmentItem
+		expectedErr   error
+	}{
+		{
+			name:     "first shipment moves a paid order to delivery",
+			shipment: &domain.Shipment{OrderID: "o1", Carrier: "DHL", Items: []domain.ShipmentItem{{ProductID: "p1", Quantity: 1}}},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order(domain.StatusPaid), nil)
+				r.EXPECT().ListShipments(gomock.Any(), "o1").Return([]*domain.Shipment{}, nil)
+				r.EXPECT().CreateShipment(gomock.Any(), gomock.Any(), gomock.Any(), eventOfType(domain.EventShipmentShipped), eventOfType(domain.EventOrderStatusChanged)).
+					DoAndReturn(func(_ any, _ *domain.Shipment, o *domain.Order, _ ...domain.Event) error {
+						assert.Equal(t, domain.StatusDelivery, o.Status)
+						assert.Equal(t, 4, o.Version)
+						return nil
+					})
+				return r
+			},
+			expectedItems: []domain.ShipmentItem{{ProductID: "p1", Quantity: 1}},
+		},
+		{
+			name:     "without items ships the rest",
+			shipment: &domain.Shipment{OrderID: "o1", Carrier: "DHL"},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order(domain.StatusDelivery), nil)
+				r.EXPECT().ListShipments(gomock.Any(), "o1").Return(shipped, nil)
+				r.EXPECT().CreateShipment(gomock.Any(), gomock.Any(), gomock.Any(), eventOfType(domain.EventShipmentShipped)).Return(nil)
+				return r
+			},
+			expectedItems: []domain.ShipmentItem{{ProductID: "p1", Quantity: 1}, {ProductID: "p2", Quantity: 1}},
+		},
+		{
+			name:     "more than is left to ship",
+			shipment: &domain.Shipment{OrderID: "o1", Carrier: "DHL", Items: []domain.ShipmentItem{{ProductID: "p1", Quantity: 2}}},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order(domain.StatusDelivery), nil)
+				r.EXPECT().ListShipments(gomock.Any(), "o1").Return(shipped, nil)
+				return r
+			},
+			expectedErr: domain.ErrorInvalidShipment,
+		},
+		{
+			name:     "product not in the order",
+			shipment: &domain.Shipment{OrderID: "o1", Carrier: "DHL", Items: []domain.ShipmentItem{{ProductID: "p9", Quantity: 1}}},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order(domain.StatusPaid), nil)
+				r.EXPECT().ListShipments(gomock.Any(), "o1").Return([]*domain.Shipment{}, nil)
+				return r
+			},
+			expectedErr: domain.ErrorInvalidShipment,
+		},
+		{
+			name:     "unpaid order",
+			shipment: &domain.Shipment{OrderID: "o1", Carrier: "DHL"},
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order(domain.StatusPending), nil)
+				return r
+			},
+			expectedErr: domain.ErrorOrderNotShippable,
+		},
+		{
+			name:     "missing carrier",
+			shipment: &domain.Shipment{OrderID: "o1", Carrier: " "},
+			repository: func() service.Repository {
+				return mocks.NewMockRepository(ctrl)
+			},
+			expectedErr: domain.ErrorInvalidShipment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := service.NewService(tt.repository())
+
+			err := s.CreateShipment(t.Context(), tt.shipment)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tt.shipment.ID)
+			assert.Equal(t, tt.expectedItems, tt.shipment.Items)
+		})
+	}
+}
+
+func TestDeliverShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	delivered := time.Now().Add(-time.Hour)
+	order := func() *domain.Order {
+		return &domain.Order{ID: "o1", Status: domain.StatusDelivery, Items: []domain.OrderItem{{ProductID: "p1", Quantity: 2}}}
+	}
+	shipment := func(id string, quantity int, deliveredAt *time.Time) *domain.Shipment {
+		return &domain.Shipment{ID: id, OrderID: "o1", Items: []domain.ShipmentItem{{ProductID: "p1", Quantity: quantity}}, DeliveredAt: deliveredAt}
+	}
+
+	tests := []struct {
+		name           string
+		shipments      []*domain.Shipment
+		expectedEvents []any
+		expectedStatus domain.Status
+		expectDeliver  bool
+	}{
+		{
+			name:           "last parcel completes the order",
+			shipments:      []*domain.Shipment{shipment("s1", 1, &delivered), shipment("s2", 1, nil)},
+			expectedEvents: []any{eventOfType(domain.EventShipmentDelivered), eventOfType(domain.EventOrderStatusChanged)},
+			expectedStatus: domain.StatusCompleted,
+			expectDeliver:  true,
+		},
+		{
+			name:           "items left to ship keep the order in delivery",
+			shipments:      []*domain.Shipment{shipment("s2", 1, nil)},
+			expectedEvents: []any{eventOfType(domain.EventShipmentDelivered)},
+			expectedStatus: domain.StatusDelivery,
+			expectDeliver:  true,
+		},
+		{
+			name:      "delivered already",
+			shipments: []*domain.Shipment{shipment("s2", 2, &delivered)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mocks.NewMockRepository(ctrl)
+			r.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order(), nil)
+			r.EXPECT().ListShipments(gomock.Any(), "o1").Return(tt.shipments, nil)
+			if tt.expectDeliver {
+				r.EXPECT().DeliverShipment(gomock.Any(), gomock.Any(), gomock.Any(), tt.expectedEvents...).DoAndReturn(func(_ any, _ *domain.Shipment, o *domain.Order, _ ...domain.Event) error {
+					assert.Equal(t, tt.expectedStatus, o.Status)
+					return nil
+				})
+			}
+
+			s := service.NewService(r)
+			got, err := s.DeliverShipment(t.Context(), "o1", "s2")
+			assert.NoError(t, err)
+			assert.True(t, got.Delivered())
+		})
+	}
+
+	t.Run("unknown shipment", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order(), nil)
+		r.EXPECT().ListShipments(gomock.Any(), "o1").Return([]*domain.Shipment{}, nil)
+
+		_, err := service.NewService(r).DeliverShipment(t.Context(), "o1", "s9")
+		assert.ErrorIs(t, err, domain.ErrorShipmentNotFound)
+	})
+}
+
+func TestCreateReturn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	order := func(status domain.Status) *domain.Order {
+		return &domain.Order{ID: "o1", UserID: "u1", Status: status, Currency: "KZT", Items: []domain.OrderItem{
+			{ProductID: "p1", Quantity: 2, Price: kzt(100)},
+			{ProductID: "p2", Quantity: 1, Price: kzt(50)},
+		}}
+	}
+	previous := func(status domain.ReturnStatus) []*domain.Return {
+		return []*domain.Return{{ID: "r0", Status: status, Items: []domain.ReturnItem{{ProductID: "p1", Quantity: 1, Reason: domain.ReasonDamaged}}}}
+	}
+	delivered := time.Now()
+	shipped := func(deliveredAt *time.Time) []*domain.Shipment {
+		return []*domain.Shipment{{ID: "s1", OrderID: "o1", DeliveredAt: deliveredAt, Items: []domain.ShipmentItem{
+			{ProductID: "p1", Quantity: 2},
+			{ProductID: "p2", Quantity: 1},
+		}}}
+	}
+
+	tests := []struct {
+		name           string
+		status         domain.Status
+		shipments      []*domain.Shipment
+		previous       []*domain.Return
+		items          []domain.ReturnItem
+		expectedErr    error
+		expectedRefund int64
+	}{
+		{
+			name:           "success",
+			status:         domain.StatusCompleted,
+			items:          []domain.ReturnItem{{ProductID: "p1", Quantity: 2, Reason: domain.ReasonDamaged}, {ProductID: "p2", Quantity: 1, Reason: domain.ReasonOther}},
+			expectedRefund: 250,
+		},
+		{
+			name:           "rejected returns free their items",
+			status:         domain.StatusCompleted,
+			previous:       previous(domain.ReturnRejected),
+			items:          []domain.ReturnItem{{ProductID: "p1", Quantity: 2, Reason: domain.ReasonWrongItem}},
+			expectedRefund: 200,
+		},
+		{
+			name:        "order not completed",
+			status:      domain.StatusDelivery,
+			items:       []domain.ReturnItem{{ProductID: "p1", Quantity: 1, Reason: domain.ReasonDamaged}},
+			expectedErr: domain.ErrorOrderNotReturnable,
+		},
+		{
+			name:        "completed without shipments",
+			status:      domain.StatusCompleted,
+			shipments:   []*domain.Shipment{},
+			items:       []domain.ReturnItem{{ProductID: "p1", Quantity: 1, Reason: domain.ReasonDamaged}},
+			expectedErr: domain.ErrorOrderNotReturnable,
+		},
+		{
+			name:        "shipment not delivered",
+			status:      domain.StatusCompleted,
+			shipments:   shipped(nil),
+			items:       []domain.ReturnItem{{ProductID: "p1", Quantity: 1, Reason: domain.ReasonDamaged}},
+			expectedErr: domain.ErrorOrderNotReturnable,
+		},
+		{
+			name:        "already being returned",
+			status:      domain.StatusCompleted,
+			previous:    previous(domain.ReturnApproved),
+			items:       []domain.ReturnItem{{ProductID: "p1", Quantity: 2, Reason: domain.ReasonDamaged}},
+			expectedErr: domain.ErrorInvalidReturn,
+		},
+		{
+			name:        "product not in the order",
+			status:      domain.StatusCompleted,
+			items:       []domain.ReturnItem{{ProductID: "p3", Quantity: 1, Reason: domain.ReasonDamaged}},
+			expectedErr: domain.ErrorInvalidReturn,
+		},
+		{
+			name:        "variant not in the order",
+			status:      domain.StatusCompleted,
+			items:       []domain.ReturnItem{{ProductID: "p1", VariantID: "v1", Quantity: 1, Reason: domain.ReasonDamaged}},
+			expectedErr: domain.ErrorInvalidReturn,
+		},
+		{
+			name:        "unknown reason",
+			status:      domain.StatusCompleted,
+			items:       []domain.ReturnItem{{ProductID: "p1", Quantity: 1, Reason: "bored"}},
+			expectedErr: domain.ErrorInvalidReturn,
+		},
+		{
+			name:        "no items",
+			status:      domain.StatusCompleted,
+			expectedErr: domain.ErrorInvalidReturn,
+		},
+		{
+			name:        "invalid quantity",
+			status:      domain.StatusCompleted,
+			items:       []domain.ReturnItem{{ProductID: "p1", Quantity: 0, Reason: domain.ReasonDamaged}},
+			expectedErr: domain.ErrorInvalidQuantity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mocks.NewMockRepository(ctrl)
+			r.EXPECT().GetOrderByID(gomock.Any(), "o1").Return(order(tt.status), nil)
+			if tt.status == domain.StatusCompleted {
+				shipments := tt.shipments
+				if shipments == nil {
+					shipments = shipped(&delivered)
+				}
+				r.EXPECT().ListShipments(gomock.Any(), "o1").Return(shipments, nil)
+				if tt.shipments == nil {
+					r.EXPECT().ListReturns(gomock.Any(), "o1").Return(tt.previous, nil)
+				}
+			}
+			if tt.expectedErr == nil {
+				r.EXPECT().CreateReturn(gomock.Any(), gomock.Any(), eventOfType(domain.EventReturnStatusChanged)).Return(nil)
+			}
+
+			ret := &domain.Return{OrderID: "o1", Items: tt.items}
+			err := service.NewService(r).CreateReturn(t.Context(), ret)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "u1", ret.UserID)
+			assert.Equal(t, domain.ReturnRequested, ret.Status)
+			assert.Equal(t, kzt(tt.expectedRefund), ret.RefundAmount)
+			if assert.Len(t, ret.History, 1) {
+				assert.Equal(t, domain.ReturnRequested, ret.History[0].To)
+			}
+		})
+	}
+}
+
+func TestUpdateReturnStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name        string
+		from, to    domain.ReturnStatus
+		expectedErr error
+	}{
+		{name: "approve", from: domain.ReturnRequested, to: domain.ReturnApproved},
+		{name: "reject approved", from: domain.ReturnApproved, to: domain.ReturnRejected},
+		{name: "receive", from: domain.ReturnApproved, to: domain.ReturnReceived},
+		{name: "refund", from: domain.ReturnReceived, to: domain.ReturnRefunded},
+		{name: "refund before receiving", from: domain.ReturnApproved, to: domain.ReturnRefunded, expectedErr: domain.ErrorReturnTransition},
+		{name: "receive rejected", from: domain.ReturnRejected, to: domain.ReturnReceived, expectedErr: domain.ErrorReturnTransition},
+		{name: "refund twice", from: domain.ReturnRefunded, to: domain.ReturnRefunded, expectedErr: domain.ErrorReturnTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mocks.NewMockRepository(ctrl)
+			r.EXPECT().GetReturn(gomock.Any(), "r1").Return(&domain.Return{ID: "r1", Status: tt.from}, nil)
+			if tt.expectedErr == nil {
+				r.EXPECT().UpdateReturnStatus(gomock.Any(), gomock.Any(), gomock.Any(), eventOfType(domain.EventReturnStatusChanged)).
+					DoAndReturn(func(_ any, _ *domain.Return, change domain.ReturnStatusChange, _ ...domain.Event) error {
+						assert.Equal(t, tt.from, change.From)
+						assert.Equal(t, tt.to, change.To)
+						assert.Equal(t, "checked", change.Note)
+						return nil
+					})
+			}
+
+			got, err := service.NewService(r).UpdateReturnStatus(t.Context(), "r1", tt.to, " checked ")
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.to, got.Status)
+			assert.Len(t, got.History, 1)
+		})
+	}
+}
+
+func TestCancelStaleOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batch := func(n int) []*domain.Order {
+		orders := make([]*domain.Order, n)
+		for i := range orders {
+			orders[i] = &domain.Order{ID: fmt.Sprint(i), Status: domain.StatusCanceled}
+		}
+		return orders
+	}
+
+	t.Run("cancels in batches until none are left", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		start := time.Now()
+		gomock.InOrder(
+			r.EXPECT().CancelStaleOrders(gomock.Any(), gomock.Any(), 100, gomock.Any()).DoAndReturn(func(_ any, createdBefore time.Time, _ int, _ time.Time) ([]*domain.Order, error) {
+				assert.WithinDuration(t, start.Add(-2*time.Hour), createdBefore, time.Minute)
+				return batch(100), nil
+			}),
+			r.EXPECT().CancelStaleOrders(gomock.Any(), gomock.Any(), 100, gomock.Any()).Return(batch(3), nil),
+		)
+		r.EXPECT().SaveJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, run *domain.JobRun) error {
+			assert.Equal(t, domain.JobCancelStaleOrders, run.Name)
+			assert.Equal(t, 103, run.Processed)
+			assert.Empty(t, run.Error)
+			assert.False(t, run.FinishedAt.Before(run.StartedAt))
+			return nil
+		})
+
+		n, err := service.NewService(r, service.WithPendingOrderTTL(2*time.Hour)).CancelStaleOrders(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, 103, n)
+	})
+
+	t.Run("failed runs are recorded", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().CancelStaleOrders(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+		r.EXPECT().SaveJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, run *domain.JobRun) error {
+			assert.Equal(t, "db down", run.Error)
+			return nil
+		})
+
+		_, err := service.NewService(r).CancelStaleOrders(t.Context())
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestAdjustStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name        string
+		movement    domain.InventoryMovement
+		expectedErr error
+	}{
+		{name: "success", movement: domain.InventoryMovement{ProductID: "p1", WarehouseID: "main", Quantity: -2, Reason: " stock count ", Actor: "jane"}},
+		{name: "zero quantity", movement: domain.InventoryMovement{ProductID: "p1", WarehouseID: "main", Reason: "stock count", Actor: "jane"}, expectedErr: domain.ErrorInvalidStockAdjustment},
+		{name: "no reason", movement: domain.InventoryMovement{ProductID: "p1", WarehouseID: "main", Quantity: 1, Reason: " ", Actor: "jane"}, expectedErr: domain.ErrorInvalidStockAdjustment},
+		{name: "no actor", movement: domain.InventoryMovement{ProductID: "p1", WarehouseID: "main", Quantity: 1, Reason: "stock count"}, expectedErr: domain.ErrorInvalidStockAdjustment},
+		{name: "no warehouse", movement: domain.InventoryMovement{ProductID: "p1", Quantity: 1, Reason: "stock count", Actor: "jane"}, expectedErr: domain.ErrorInvalidStockAdjustment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mocks.NewMockRepository(ctrl)
+			if tt.expectedErr == nil {
+				r.EXPECT().AdjustStock(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			m := tt.movement
+			err := service.NewService(r).AdjustStock(t.Context(), &m)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, domain.MovementAdjustment, m.Type)
+			assert.Equal(t, "stock count", m.Reason)
+			assert.False(t, m.CreatedAt.IsZero())
+		})
+	}
+}
+
+func TestPatchProductAmount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := mocks.NewMockRepository(ctrl)
+	r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1", Name: "Pen", Price: kzt(10), Amount: 5, Version: 1}, nil)
+
+	err := service.NewService(r).PatchProduct(t.Context(), &domain.Product{ID: "p1", Amount: 9, Version: 1}, domain.FieldMask{"amount"})
+	assert.ErrorIs(t, err, domain.ErrorInvalidPatch)
+}
+
+func TestPatchProductReorderPoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	current := func() *domain.Product {
+		return &domain.Product{ID: "p1", Name: "Pen", Price: kzt(10), Amount: 5, ReorderPoint: 5, Version: 1}
+	}
+	supplierID := "s1"
+
+	t.Run("reorder settings and supplier are set", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(current(), nil)
+		r.EXPECT().GetSupplierByID(gomock.Any(), "s1").Return(&domain.Supplier{ID: "s1", Name: "Green Leaf"}, nil)
+		r.EXPECT().PatchProduct(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, p *domain.Product, _ domain.FieldMask) error {
+			assert.Equal(t, 10, p.ReorderPoint)
+			assert.Equal(t, 50, p.ReorderQuantity)
+			assert.Equal(t, &supplierID, p.SupplierID)
+			return nil
+		})
+
+		patch := &domain.Product{ID: "p1", ReorderPoint: 10, ReorderQuantity: 50, SupplierID: &supplierID, Version: 1}
+		assert.NoError(t, service.NewService(r).PatchProduct(t.Context(), patch, domain.FieldMask{"reorder_point", "reorder_quantity", "supplier_id"}))
+	})
+
+	t.Run("unknown supplier", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(current(), nil)
+		r.EXPECT().GetSupplierByID(gomock.Any(), "s1").Return(nil, domain.ErrorSupplierNotFound)
+
+		patch := &domain.Product{ID: "p1", SupplierID: &supplierID, Version: 1}
+		err := service.NewService(r).PatchProduct(t.Context(), patch, domain.FieldMask{"supplier_id"})
+		assert.ErrorIs(t, err, domain.ErrorInvalidPatch)
+	})
+
+	t.Run("negative reorder point", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(current(), nil)
+
+		patch := &domain.Product{ID: "p1", ReorderPoint: -1, Version: 1}
+		err := service.NewService(r).PatchProduct(t.Context(), patch, domain.FieldMask{"reorder_point"})
+		assert.ErrorIs(t, err, domain.ErrorInvalidPatch)
+	})
+}
+
+func TestCheckLowStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batch := func(n int) []domain.ReorderLine {
+		lines := make([]domain.ReorderLine, n)
+		for i := range lines {
+			lines[i] = domain.ReorderLine{Product: &domain.Product{ID: fmt.Sprint(i)}}
+		}
+		return lines
+	}
+
+	r := mocks.NewMockRepository(ctrl)
+	gomock.InOrder(
+		r.EXPECT().ClaimLowStock(gomock.Any(), 100, gomock.Any()).Return(batch(100), nil),
+		r.EXPECT().ClaimLowStock(gomock.Any(), 100, gomock.Any()).Return(batch(2), nil),
+	)
+	r.EXPECT().SaveJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, run *domain.JobRun) error {
+		assert.Equal(t, domain.JobCheckLowStock, run.Name)
+		assert.Equal(t, 102, run.Processed)
+		assert.Empty(t, run.Error)
+		return nil
+	})
+
+	n, err := service.NewService(r).CheckLowStock(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, 102, n)
+}
+
+func TestCreateWarehouse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("country is normalized", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().CreateWarehouse(gomock.Any(), gomock.Any()).Return(nil)
+
+		warehouse := &domain.Warehouse{Name: " Astana ", Country: "kz"}
+		assert.NoError(t, service.NewService(r).CreateWarehouse(t.Context(), warehouse))
+		assert.NotEmpty(t, warehouse.ID)
+		assert.Equal(t, "Astana", warehouse.Name)
+		assert.Equal(t, "KZ", warehouse.Country)
+	})
+
+	t.Run("invalid country", func(t *testing.T) {
+		err := service.NewService(mocks.NewMockRepository(ctrl)).CreateWarehouse(t.Context(), &domain.Warehouse{Name: "Astana", Country: "Kazakhstan"})
+		assert.ErrorIs(t, err, domain.ErrorInvalidWarehouse)
+	})
+}
+
+func TestCreateOrderFulfillmentStrategy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := mocks.NewMockRepository(ctrl)
+	r.EXPECT().GetDefaultAddress(gomock.Any(), "999").Return(nil, domain.ErrorAddressNotFound)
+	r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), domain.FulfillMostStock, eventOfType(domain.EventOrderCreated)).Return(nil)
+
+	s := service.NewService(r, service.WithFulfillmentStrategy(domain.FulfillMostStock))
+	assert.NoError(t, s.CreateOrder(t.Context(), &domain.Order{UserID: "999", Items: []domain.OrderItem{{ProductID: "p1", Quantity: 1}}}))
+}
+
+func TestCreateVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	price := func(p int64) *domain.Money { m := kzt(p); return &m }
+
+	t.Run("sku and options are normalized", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1", Price: kzt(10)}, nil)
+		r.EXPECT().CreateVariant(gomock.Any(), gomock.Any()).Return(nil)
+
+		variant := &domain.Variant{ProductID: "p1", SKU: " TS-M ", Options: map[string]string{" Size ": " M "}, Price: price(12)}
+		assert.NoError(t, service.NewService(r).CreateVariant(t.Context(), variant))
+		assert.NotEmpty(t, variant.ID)
+		assert.Equal(t, "TS-M", variant.SKU)
+		assert.Equal(t, map[string]string{"size": "M"}, variant.Options)
+		assert.False(t, variant.CreatedAt.IsZero())
+	})
+
+	t.Run("unknown product", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p9").Return(nil, domain.ErrorProductNotFound)
+
+		err := service.NewService(r).CreateVariant(t.Context(), &domain.Variant{ProductID: "p9", SKU: "X", Options: map[string]string{"size": "M"}})
+		assert.ErrorIs(t, err, domain.ErrorProductNotFound)
+	})
+
+	t.Run("price in another currency than the product", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1", Price: kzt(10)}, nil)
+
+		usd := domain.NewMoney(12, "USD")
+		err := service.NewService(r).CreateVariant(t.Context(), &domain.Variant{ProductID: "p1", SKU: "TS-M", Options: map[string]string{"size": "M"}, Price: &usd})
+		assert.ErrorIs(t, err, domain.ErrorInvalidVariant)
+	})
+
+	invalid := []struct {
+		name    string
+		variant *domain.Variant
+	}{
+		{name: "no sku", variant: &domain.Variant{ProductID: "p1", Options: map[string]string{"size": "M"}}},
+		{name: "no options", variant: &domain.Variant{ProductID: "p1", SKU: "TS-M"}},
+		{name: "empty option value", variant: &domain.Variant{ProductID: "p1", SKU: "TS-M", Options: map[string]string{"size": " "}}},
+		{name: "option given twice", variant: &domain.Variant{ProductID: "p1", SKU: "TS-M", Options: map[string]string{"size": "M", "SIZE": "L"}}},
+		{name: "price not positive", variant: &domain.Variant{ProductID: "p1", SKU: "TS-M", Options: map[string]string{"size": "M"}, Price: price(0)}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.NewService(mocks.NewMockRepository(ctrl)).CreateVariant(t.Context(), tt.variant)
+			assert.ErrorIs(t, err, domain.ErrorInvalidVariant)
+		})
+	}
+}
+
+func TestPatchProductPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	current := func() *domain.Product {
+		return &domain.Product{ID: "p1", Name: "Pen", Price: kzt(1000), Version: 1}
+	}
+
+	t.Run("price in the same currency", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(current(), nil)
+		r.EXPECT().PatchProduct(gomock.Any(), gomock.Any(), domain.FieldMask{"price"}).Return(nil)
+
+		patch := &domain.Product{ID: "p1", Price: kzt(1200), Version: 1}
+		assert.NoError(t, service.NewService(r).PatchProduct(t.Context(), patch, domain.FieldMask{"price"}))
+		assert.Equal(t, kzt(1200), patch.Price)
+	})
+
+	t.Run("price in another currency", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(current(), nil)
+
+		patch := &domain.Product{ID: "p1", Price: domain.NewMoney(300, "USD"), Version: 1}
+		err := service.NewService(r).PatchProduct(t.Context(), patch, domain.FieldMask{"price"})
+		assert.ErrorIs(t, err, domain.ErrorInvalidPatch)
+	})
+}
+
+func TestSetProductPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("price in another currency is listed", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1", Price: kzt(1000)}, nil)
+		r.EXPECT().SetProductPrice(gomock.Any(), "p1", domain.NewMoney(250, "USD")).Return(nil)
+
+		assert.NoError(t, service.NewService(r).SetProductPrice(t.Context(), "p1", domain.NewMoney(250, "USD")))
+	})
+
+	t.Run("currency of the base price", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1", Price: kzt(1000)}, nil)
+
+		err := service.NewService(r).SetProductPrice(t.Context(), "p1", kzt(1200))
+		assert.ErrorIs(t, err, domain.ErrorInvalidPrice)
+	})
+
+	t.Run("price not positive", func(t *testing.T) {
+		err := service.NewService(mocks.NewMockRepository(ctrl)).SetProductPrice(t.Context(), "p1", domain.NewMoney(0, "USD"))
+		assert.ErrorIs(t, err, domain.ErrorInvalidPrice)
+	})
+
+	t.Run("base price cannot be deleted", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1", Price: kzt(1000)}, nil)
+
+		err := service.NewService(r).DeleteProductPrice(t.Context(), "p1", "KZT")
+		assert.ErrorIs(t, err, domain.ErrorInvalidPrice)
+	})
+}
+
+func TestCreateOrderCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	items := func() []domain.OrderItem { return []domain.OrderItem{{ProductID: "p1", Quantity: 1}} }
+
+	t.Run("default currency", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetDefaultAddress(gomock.Any(), "999").Return(nil, domain.ErrorAddressNotFound)
+		r.EXPECT().CreateOrder(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ any, order *domain.Order, _ domain.FulfillmentStrategy, _ ...domain.Event) error {
+				assert.Equal(t, domain.Currency("USD"), order.Currency)
+				return nil
+			})
+
+		s := service.NewService(r, service.WithDefaultCurrency("USD"))
+		assert.NoError(t, s.CreateOrder(t.Context(), &domain.Order{UserID: "999", Items: items()}))
+	})
+
+	t.Run("unsupported currency", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetDefaultAddress(gomock.Any(), "999").Return(nil, domain.ErrorAddressNotFound).AnyTimes()
+
+		err := service.NewService(r).CreateOrder(t.Context(), &domain.Order{UserID: "999", Currency: "XYZ", Items: items()})
+		assert.ErrorIs(t, err, domain.ErrorInvalidCurrency)
+	})
+}
+
+func TestCreateWebhookSubscriptionHost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"https://10.0.0.5/hook",
+		"https://[::1]/hook",
+	} {
+		t.Run(url, func(t *testing.T) {
+			s := service.NewService(mocks.NewMockRepository(ctrl))
+			err := s.CreateWebhookSubscription(t.Context(), &domain.WebhookSubscription{
+				URL:        url,
+				EventTypes: []domain.EventType{domain.EventOrderCreated},
+			})
+			assert.ErrorIs(t, err, domain.ErrorInvalidWebhook)
+			assert.ErrorIs(t, err, webhook.ErrNonPublicAddress)
+		})
+	}
+
+	t.Run("public address", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := service.NewService(r).CreateWebhookSubscription(t.Context(), &domain.WebhookSubscription{
+			URL:        "https://93.184.216.34/hook",
+			EventTypes: []domain.EventType{domain.EventOrderCreated},
+		})
+		assert.NoError(t, err)
+	})
+}
+
+// kzt returns amount tiyn.
+func kzt(amount int64) domain.Money {
+	return domain.NewMoney(amount, "KZT")
+}
+
+// encodedPNG returns a PNG image of the given size.
+func encodedPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestUploadProductImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("original and thumbnails are stored", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1"}, nil)
+		r.EXPECT().CreateProductImage(gomock.Any(), gomock.Any()).Return(nil)
+
+		blobs := &storage.MemoryStore{}
+		data := encodedPNG(t, 1600, 800)
+		img, err := service.NewService(r, service.WithBlobStore(blobs)).UploadProductImage(t.Context(), "p1", bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", img.ContentType)
+		assert.Equal(t, 1600, img.Width)
+		assert.Equal(t, 800, img.Height)
+		assert.Equal(t, int64(len(data)), img.Size)
+		assert.ElementsMatch(t, []string{
+			img.Key(domain.ImageOriginal),
+			img.Key(domain.ImageSmall),
+			img.Key(domain.ImageMedium),
+			img.Key(domain.ImageLarge),
+		}, blobs.Keys())
+
+		rc, err := blobs.Open(t.Context(), img.Key(domain.ImageSmall))
+		assert.NoError(t, err)
+		defer rc.Close()
+		thumb, err := png.DecodeConfig(rc)
+		assert.NoError(t, err)
+		assert.Equal(t, 160, thumb.Width)
+		assert.Equal(t, 80, thumb.Height)
+	})
+
+	t.Run("blobs are removed when the image cannot be saved", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1"}, nil)
+		r.EXPECT().CreateProductImage(gomock.Any(), gomock.Any()).Return(domain.ErrorProductNotFound)
+
+		blobs := &storage.MemoryStore{}
+		_, err := service.NewService(r, service.WithBlobStore(blobs)).UploadProductImage(t.Context(), "p1", bytes.NewReader(encodedPNG(t, 10, 10)))
+		assert.ErrorIs(t, err, domain.ErrorProductNotFound)
+		assert.Empty(t, blobs.Keys())
+	})
+
+	invalid := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "not an image", data: []byte("%PDF-1.7 hello"), err: domain.ErrorInvalidImage},
+		{name: "truncated image", data: encodedPNG(t, 10, 10)[:20], err: domain.ErrorInvalidImage},
+		{name: "over the limit", data: encodedPNG(t, 400, 400), err: domain.ErrorImageTooLarge},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mocks.NewMockRepository(ctrl)
+			r.EXPECT().GetProductByID(gomock.Any(), "p1").Return(&domain.Product{ID: "p1"}, nil)
+
+			blobs := &storage.MemoryStore{}
+			s := service.NewService(r, service.WithBlobStore(blobs), service.WithImageMaxBytes(1024))
+			_, err := s.UploadProductImage(t.Context(), "p1", bytes.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.err)
+			assert.Empty(t, blobs.Keys())
+		})
+	}
+}
+
+func TestDeleteProductImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blobs := &storage.MemoryStore{}
+	img := &domain.ProductImage{ID: "i1", ProductID: "p1", ContentType: "image/png"}
+	for _, size := range []domain.ImageSize{domain.ImageOriginal, domain.ImageSmall, domain.ImageMedium, domain.ImageLarge} {
+		assert.NoError(t, blobs.Put(t.Context(), img.Key(size), []byte("x")))
+	}
+
+	r := mocks.NewMockRepository(ctrl)
+	r.EXPECT().GetProductImage(gomock.Any(), "p1", "i1").Return(img, nil)
+	r.EXPECT().DeleteProductImage(gomock.Any(), "p1", "i1").Return(nil)
+
+	assert.NoError(t, service.NewService(r, service.WithBlobStore(blobs)).DeleteProductImage(t.Context(), "p1", "i1"))
+	assert.Empty(t, blobs.Keys())
+}
+
+// sentToken is an account email recorded by accountMailer.
+type sentToken struct {
+	kind, email, token string
+	expiresAt          time.Time
+}
+
+type accountMailer struct {
+	sent []sentToken
+	err  error
+}
+
+func (m *accountMailer) SendEmailVerification(_ context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, sentToken{"verify", user.Email, token, expiresAt})
+	return nil
+}
+
+func (m *accountMailer) SendPasswordReset(_ context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, sentToken{"reset", user.Email, token, expiresAt})
+	return nil
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("unknown email sends nothing", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetByEmail(gomock.Any(), "nobody@qwe.qwe").Return(nil, domain.ErrorUserNotFound)
+		mailer := &accountMailer{}
+
+		s := service.NewService(r, service.WithAccountMailer(mailer))
+		assert.NoError(t, s.RequestPasswordReset(t.Context(), "nobody@qwe.qwe"))
+		assert.Empty(t, mailer.sent)
+	})
+
+	t.Run("registered email is queued, not mailed", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetByEmail(gomock.Any(), "qwe@qwe.qwe").Return(&domain.User{ID: "u1", Email: "qwe@qwe.qwe"}, nil)
+		r.EXPECT().AddEvents(gomock.Any(), eventOfType(domain.EventAccountTokenRequested)).Return(nil)
+		mailer := &accountMailer{}
+
+		s := service.NewService(r, service.WithAccountMailer(mailer))
+		assert.NoError(t, s.RequestPasswordReset(t.Context(), "qwe@qwe.qwe"))
+		assert.Empty(t, mailer.sent)
+	})
+
+	t.Run("failing to queue does not tell registered emails apart", func(t *testing.T) {
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().GetByEmail(gomock.Any(), "qwe@qwe.qwe").Return(&domain.User{ID: "u1", Email: "qwe@qwe.qwe"}, nil)
+		r.EXPECT().AddEvents(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+		s := service.NewService(r, service.WithAccountMailer(&accountMailer{}))
+		assert.NoError(t, s.RequestPasswordReset(t.Context(), "qwe@qwe.qwe"))
+	})
+}
+
+func TestDispatchAccountToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &domain.User{ID: "u1", Email: "qwe@qwe.qwe"}
+	event := domain.NewAccountTokenRequested(user, domain.TokenPasswordReset)
+	event.Attempts = 1
+
+	t.Run("only the token hash is stored", func(t *testing.T) {
+		var stored *domain.UserToken
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().ClaimEvents(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]domain.Event{event}, nil)
+		r.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+		r.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, token *domain.UserToken) error {
+			stored = token
+			return nil
+		})
+		r.EXPECT().MarkEventDispatched(gomock.Any(), event.ID, gomock.Any()).Return(nil)
+		mailer := &accountMailer{}
+		sink := &events.MemorySink{}
+
+		s := service.NewService(r, service.WithAccountMailer(mailer), service.WithEventSink(sink), service.WithTokenTTLs(time.Hour, 15*time.Minute))
+		n, err := s.DispatchEvents(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Empty(t, sink.Events())
+
+		if assert.Len(t, mailer.sent, 1) && assert.NotNil(t, stored) {
+			sent := mailer.sent[0]
+			assert.Equal(t, "reset", sent.kind)
+			assert.Equal(t, "qwe@qwe.qwe", sent.email)
+			sum := sha256.Sum256([]byte(sent.token))
+			assert.Equal(t, hex.EncodeToString(sum[:]), stored.Hash)
+			assert.NotEqual(t, sent.token, stored.Hash)
+			assert.Equal(t, domain.TokenPasswordReset, stored.Purpose)
+			assert.Equal(t, "u1", stored.UserID)
+			assert.Equal(t, "qwe@qwe.qwe", stored.Email)
+			assert.Equal(t, stored.CreatedAt.Add(15*time.Minute), stored.ExpiresAt)
+			assert.Equal(t, stored.ExpiresAt, sent.expiresAt)
+		}
+	})
+
+	t.Run("failed send is retried", func(t *testing.T) {
+		mailErr := errors.New("smtp down")
+		r := mocks.NewMockRepository(ctrl)
+		r.EXPECT().ClaimEvents(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]domain.Event{event}, nil)
+		r.EXPECT().GetUserByID(gomock.Any(), "u1").Return(user, nil)
+		r.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).Return(nil)
+		r.EXPECT().FailEvent(gomock.Any(), event.ID, mailErr, gomock.Any()).Return(nil)
+
+		s := service.NewService(r, service.WithAccountMailer(&accountMailer{err: mailErr}))
+		n, err := s.DispatchEvents(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sum := sha256.Sum256([]byte("token"))
+	hash := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name        string
+		token       string
+		repository  func() service.Repository
+		expectedErr error
+	}{
+		{
+			name:  "success",
+			token: "token",
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().ResetPassword(gomock.Any(), hash, gomock.Not("new password"), gomock.Any()).Return(nil)
+				return r
+			},
+		},
+		{
+			name:  "used or expired token",
+			token: "token",
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().ResetPassword(gomock.Any(), hash, gomock.Any(), gomock.Any()).Return(domain.ErrorInvalidToken)
+				return r
+			},
+			expectedErr: domain.ErrorInvalidToken,
+		},
+		{
+			name:  "empty token",
+			token: "",
+			repository: func() service.Repository {
+				return mocks.NewMockRepository(ctrl)
+			},
+			expectedErr: domain.ErrorInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := service.NewService(tt.repository())
+
+			err := s.ResetPassword(t.Context(), tt.token, "new password")
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+// eventOfType matches a domain.Event of the given type.
+func eventOfType(typ domain.EventType) gomock.Matcher {
+	return gomock.Cond(func(e domain.Event) bool { return e.Type == typ })
+}
+
+func TestDispatchEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sinkErr := errors.New("sink down")
+	event := domain.Event{ID: "e1", Type: domain.EventUserRegistered, Attempts: 3}
+
+	tests := []struct {
+		name          string
+		sinkErr       error
+		repository    func() service.Repository
+		expectedCount int
+	}{
+		{
+			name: "delivered",
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().ClaimEvents(gomock.Any(), 100, 5, gomock.Any(), gomock.Any()).Return([]domain.Event{event}, nil)
+				r.EXPECT().EnqueueWebhookDeliveries(gomock.Any(), event, gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				r.EXPECT().MarkEventDispatched(gomock.Any(), "e1", gomock.Any()).Return(nil)
+				return r
+			},
+			expectedCount: 1,
+		},
+		{
+			name:    "failed delivery is retried with backoff",
+			sinkErr: sinkErr,
+			repository: func() service.Repository {
+				r := mocks.NewMockRepository(ctrl)
+				r.EXPECT().ClaimEvents(gomock.Any(), 100, 5, gomock.Any(), gomock.Any()).Return([]domain.Event{event}, nil)
+				r.EXPECT().EnqueueWebhookDeliveries(gomock.Any(), event, gomock.Any(), gomock.Any()).Return(int64(0), nil)
+				r.EXPECT().FailEvent(gomock.Any(), "e1", sinkErr, gomock.Any()).
+					DoAndReturn(func(_ any, _ string, _ error, retryAt time.Time) error {
+						// Third attempt failed: 1s doubled twice.
+						assert.WithinDuration(t, time.Now().Add(4*time.Second), retryAt, time.Second)
+						return nil
+					})
+				return r
+			},
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &events.MemorySink{Err: tt.sinkErr}
+			s := service.NewService(tt.repository(), service.WithEventSink(sink), service.WithEventRetries(5, time.Second))
+
+			n, err := s.DispatchEvents(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, n)
+			assert.Len(t, sink.Events(), tt.expectedCount)
+		})
+	}
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sub := &domain.WebhookSubscription{ID: "w1", Secret: "0123456789abcdef", EventTypes: []domain.EventType{domain.EventOrderCreated}}
+
+	tests := []struct {
+		name           string
+		receiverStatus int
+		attempts       int
+		expectedStatus domain.DeliveryStatus
+		expectedCount  int
+	}{
+		{name: "delivered", receiverStatus: http.StatusOK, attempts: 1, expectedStatus: domain.DeliverySucceeded, expectedCount: 1},
+		{name: "retried", receiverStatus: http.StatusBadGateway, attempts: 2, expectedStatus: domain.DeliveryPending},
+		{name: "attempts exhausted", receiverStatus: http.StatusBadGateway, attempts: 3, expectedStatus: domain.DeliveryFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.NoError(t, webhook.Verify(sub.Secret, r.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now()))
+				w.WriteHeader(tt.receiverStatus)
+			}))
+			defer receiver.Close()
+			sub.URL = receiver.URL
+
+			delivery := &domain.WebhookDelivery{
+				ID:             "d1",
+				SubscriptionID: "w1",
+				EventID:        "e1",
+				EventType:      domain.EventOrderCreated,
+				Payload:        []byte(`{"id":"e1"}`),
+				Status:         domain.DeliveryPending,
+				Attempts:       tt.attempts,
+			}
+
+			r := mocks.NewMockRepository(ctrl)
+			r.EXPECT().ClaimWebhookDeliveries(gomock.Any(), 100, gomock.Any(), gomock.Any()).Return([]*domain.WebhookDelivery{delivery}, nil)
+			r.EXPECT().GetWebhookSubscription(gomock.Any(), "w1").Return(sub, nil)
+			r.EXPECT().UpdateWebhookDelivery(gomock.Any(), delivery).DoAndReturn(func(_ any, d *domain.WebhookDelivery) error {
+				assert.Equal(t, tt.expectedStatus, d.Status)
+				assert.Equal(t, tt.receiverStatus, d.ResponseStatus)
+				if tt.expectedStatus == domain.DeliveryPending {
+					// Second attempt failed: base backoff doubled once.
+					assert.WithinDuration(t, time.Now().Add(2*time.Second), d.NextAttemptAt, time.Second)
+				}
+				return nil
+			})
+
+			s := service.NewService(r, service.WithWebhookClient(receiver.Client()), service.WithWebhookRetries(3, time.Second))
+			n, err := s.DeliverWebhooks(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, n)
+		})
+	}
+}
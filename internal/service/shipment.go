@@ -0,0 +1,203 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/metrics"
+	"github.com/google/uuid"
+)
+
+// CreateShipment is a method for recording a parcel sent for a paid order.
+// Without items the parcel holds everything not shipped yet. The first
+// shipment moves the order to delivery.
+func (s *service) CreateShipment(ctx context.Context, shipment *domain.Shipment) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateShipment")
+	defer span.End()
+
+	shipment.Carrier = strings.TrimSpace(shipment.Carrier)
+	shipment.TrackingNumber = strings.TrimSpace(shipment.TrackingNumber)
+	if shipment.Carrier == "" {
+		return fmt.Errorf("%w: carrier is required", domain.ErrorInvalidShipment)
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != domain.StatusPaid && order.Status != domain.StatusDelivery {
+		return domain.ErrorOrderNotShippable
+	}
+	shipments, err := s.repo.ListShipments(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	remaining := unshipped(order, shipments)
+	items, err := shipmentItems(shipment.Items, remaining)
+	if err != nil {
+		return err
+	}
+	shipment.Items = items
+
+	shipment.ID = uuid.New().String()
+	now := time.Now()
+	shipment.ShippedAt = now
+	shipment.DeliveredAt = nil
+
+	raised := []domain.Event{domain.NewShipmentShipped(shipment)}
+	from := order.Status
+	if from == domain.StatusPaid {
+		order.Status = domain.StatusDelivery
+		raised = append(raised, domain.NewOrderStatusChanged(order, from, order.Status))
+	}
+	order.UpdatedAt = now
+
+	if err := s.repo.CreateShipment(ctx, shipment, order, raised...); err != nil {
+		return err
+	}
+
+	if from != order.Status {
+		metrics.OrderStatusTransitions.WithLabelValues(string(from), string(order.Status)).Inc()
+	}
+	return nil
+}
+
+// DeliverShipment is a method for marking a shipment delivered. Once every
+// item of the order has been shipped and delivered, the order is completed.
+// Delivering a shipment again changes nothing.
+func (s *service) DeliverShipment(ctx context.Context, orderID, shipmentID string) (*domain.Shipment, error) {
+	ctx, span := tracer.Start(ctx, "Service.DeliverShipment")
+	defer span.End()
+
+	order, err := s.repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	shipments, err := s.repo.ListShipments(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var shipment *domain.Shipment
+	for _, sh := range shipments {
+		if sh.ID == shipmentID {
+			shipment = sh
+		}
+	}
+	if shipment == nil {
+		return nil, domain.ErrorShipmentNotFound
+	}
+	if shipment.Delivered() {
+		return shipment, nil
+	}
+
+	now := time.Now()
+	shipment.DeliveredAt = &now
+
+	raised := []domain.Event{domain.NewShipmentDelivered(shipment)}
+	from := order.Status
+	if from == domain.StatusDelivery && fullyDelivered(order, shipments) {
+		order.Status = domain.StatusCompleted
+		raised = append(raised, domain.NewOrderStatusChanged(order, from, order.Status))
+	}
+	order.UpdatedAt = now
+
+	if err := s.repo.DeliverShipment(ctx, shipment, order, raised...); err != nil {
+		return nil, err
+	}
+
+	if from != order.Status {
+		metrics.OrderStatusTransitions.WithLabelValues(string(from), string(order.Status)).Inc()
+	}
+	return shipment, nil
+}
+
+func (s *service) ListShipments(ctx context.Context, orderID string) ([]*domain.Shipment, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListShipments")
+	defer span.End()
+
+	if _, err := s.repo.GetOrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListShipments(ctx, orderID)
+}
+
+// unshipped returns the quantity of every line of order that is in none of its
+// shipments.
+func unshipped(order *domain.Order, shipments []*domain.Shipment) map[domain.OrderLine]int {
+	remaining := make(map[domain.OrderLine]int, len(order.Items))
+	for _, item := range order.Items {
+		remaining[item.Line()] += item.Quantity
+	}
+	for _, sh := range shipments {
+		for _, item := range sh.Items {
+			remaining[item.Line()] -= item.Quantity
+		}
+	}
+	return remaining
+}
+
+// shipmentItems validates the requested items against the remaining
+// quantities and merges repeated products. No items means all remaining ones.
+func shipmentItems(requested []domain.ShipmentItem, remaining map[domain.OrderLine]int) ([]domain.ShipmentItem, error) {
+	if len(requested) == 0 {
+		for line, quantity := range remaining {
+			if quantity > 0 {
+				requested = append(requested, domain.ShipmentItem{ProductID: line.ProductID, VariantID: line.VariantID, Quantity: quantity})
+			}
+		}
+		if len(requested) == 0 {
+			return nil, fmt.Errorf("%w: every item has been shipped", domain.ErrorInvalidShipment)
+		}
+	}
+
+	merged := make([]domain.ShipmentItem, 0, len(requested))
+	index := make(map[domain.OrderLine]int, len(requested))
+	for _, item := range requested {
+		if item.Quantity <= 0 {
+			return nil, domain.ErrorInvalidQuantity
+		}
+		if i, ok := index[item.Line()]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.Line()] = len(merged)
+		merged = append(merged, item)
+	}
+
+	for _, item := range merged {
+		left, ok := remaining[item.Line()]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not part of the order", domain.ErrorInvalidShipment, item.Line())
+		}
+		if item.Quantity > left {
+			return nil, fmt.Errorf("%w: only %d of %s left to ship", domain.ErrorInvalidShipment, left, item.Line())
+		}
+	}
+	slices.SortFunc(merged, func(a, b domain.ShipmentItem) int {
+		return cmp.Or(strings.Compare(a.ProductID, b.ProductID), strings.Compare(a.VariantID, b.VariantID))
+	})
+	return merged, nil
+}
+
+// fullyDelivered reports whether every item of order is in a delivered
+// shipment, counting shipments already marked delivered in memory.
+func fullyDelivered(order *domain.Order, shipments []*domain.Shipment) bool {
+	for _, sh := range shipments {
+		if !sh.Delivered() {
+			return false
+		}
+	}
+	for _, left := range unshipped(order, shipments) {
+		if left > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/google/uuid"
+)
+
+// CreateVariant is a method for adding a variant to a product. It starts
+// without stock; stock is brought in with stock adjustments naming the
+// variant. Once a product has variants it can only be ordered through them.
+func (s *service) CreateVariant(ctx context.Context, variant *domain.Variant) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateVariant")
+	defer span.End()
+
+	variant.SKU = strings.TrimSpace(variant.SKU)
+	if variant.SKU == "" {
+		return fmt.Errorf("%w: sku is required", domain.ErrorInvalidVariant)
+	}
+	if len(variant.Options) == 0 {
+		return fmt.Errorf("%w: at least one option is required", domain.ErrorInvalidVariant)
+	}
+	options := make(map[string]string, len(variant.Options))
+	for name, value := range variant.Options {
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return fmt.Errorf("%w: option names and values must not be empty", domain.ErrorInvalidVariant)
+		}
+		if _, ok := options[name]; ok {
+			return fmt.Errorf("%w: option %q is given twice", domain.ErrorInvalidVariant, name)
+		}
+		options[name] = value
+	}
+	variant.Options = options
+	if variant.Price != nil && !variant.Price.IsPositive() {
+		return fmt.Errorf("%w: price must be positive", domain.ErrorInvalidVariant)
+	}
+
+	product, err := s.repo.GetProductByID(ctx, variant.ProductID)
+	if err != nil {
+		return err
+	}
+	if variant.Price != nil && variant.Price.Currency != product.Price.Currency {
+		return fmt.Errorf("%w: price must be in %s like the product price", domain.ErrorInvalidVariant, product.Price.Currency)
+	}
+
+	variant.ID = uuid.New().String()
+	variant.Amount = 0
+	variant.CreatedAt = time.Now()
+	return s.repo.CreateVariant(ctx, variant)
+}
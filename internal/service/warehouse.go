@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/google/uuid"
+)
+
+// CreateWarehouse is a method for adding a warehouse. It starts without stock;
+// stock is brought in with stock adjustments.
+func (s *service) CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateWarehouse")
+	defer span.End()
+
+	warehouse.Name = strings.TrimSpace(warehouse.Name)
+	warehouse.Country = strings.ToUpper(strings.TrimSpace(warehouse.Country))
+	switch {
+	case warehouse.Name == "":
+		return fmt.Errorf("%w: name is required", domain.ErrorInvalidWarehouse)
+	case !isCountryCode(warehouse.Country):
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", domain.ErrorInvalidWarehouse)
+	}
+
+	warehouse.ID = uuid.New().String()
+	warehouse.CreatedAt = time.Now()
+	return s.repo.CreateWarehouse(ctx, warehouse)
+}
+
+func (s *service) ListWarehouses(ctx context.Context) ([]*domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListWarehouses")
+	defer span.End()
+
+	return s.repo.ListWarehouses(ctx)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/metrics"
+	"github.com/aibekfatkhulla/shop/internal/webhook"
+	"github.com/google/uuid"
+)
+
+const (
+	// webhookLease is how long a claimed delivery is hidden from other workers.
+	webhookLease = time.Minute
+	// webhookDeliveryLogSize is the number of deliveries listed per subscription.
+	webhookDeliveryLogSize = 100
+	minWebhookSecretLength = 16
+)
+
+// CreateWebhookSubscription is a method for subscribing a URL to events. A
+// secret is generated unless one is given.
+func (s *service) CreateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateWebhookSubscription")
+	defer span.End()
+
+	if err := validateWebhookSubscription(ctx, sub); err != nil {
+		return err
+	}
+
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		sub.Secret = "whsec_" + hex.EncodeToString(secret)
+	}
+	sub.ID = uuid.New().String()
+	sub.CreatedAt = time.Now()
+
+	return s.repo.CreateWebhookSubscription(ctx, sub)
+}
+
+// validateWebhookSubscription checks sub before it is stored. The URL must
+// point at a public host, so that subscriptions cannot make the server call
+// into its own network.
+func validateWebhookSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrorInvalidWebhook)
+	}
+	if err := webhook.CheckHost(ctx, u.Hostname()); err != nil {
+		return fmt.Errorf("%w: url host: %w", domain.ErrorInvalidWebhook, err)
+	}
+	if len(sub.EventTypes) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", domain.ErrorInvalidWebhook)
+	}
+	for _, t := range sub.EventTypes {
+		if !slices.Contains(domain.EventTypes, t) {
+			return fmt.Errorf("%w: unknown event type %q", domain.ErrorInvalidWebhook, t)
+		}
+	}
+	if sub.Secret != "" && len(sub.Secret) < minWebhookSecretLength {
+		return fmt.Errorf("%w: secret must be at least %d characters", domain.ErrorInvalidWebhook, minWebhookSecretLength)
+	}
+	return nil
+}
+
+func (s *service) GetWebhookSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetWebhookSubscription")
+	defer span.End()
+
+	return s.repo.GetWebhookSubscription(ctx, id)
+}
+
+func (s *service) ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListWebhookSubscriptions")
+	defer span.End()
+
+	return s.repo.ListWebhookSubscriptions(ctx)
+}
+
+func (s *service) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteWebhookSubscription")
+	defer span.End()
+
+	return s.repo.DeleteWebhookSubscription(ctx, id)
+}
+
+// ListWebhookDeliveries is a method for reading the latest deliveries of a
+// subscription, newest first
+func (s *service) ListWebhookDeliveries(ctx context.Context, subscriptionID string) ([]*domain.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListWebhookDeliveries")
+	defer span.End()
+
+	if _, err := s.repo.GetWebhookSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListWebhookDeliveries(ctx, subscriptionID, webhookDeliveryLogSize)
+}
+
+// RedeliverWebhook is a method for sending a past delivery again. The payload
+// is repeated as a new delivery so the log keeps the original attempts.
+func (s *service) RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID string) (*domain.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "Service.RedeliverWebhook")
+	defer span.End()
+
+	original, err := s.repo.GetWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.SubscriptionID != subscriptionID {
+		return nil, domain.ErrorDeliveryNotFound
+	}
+
+	now := time.Now()
+	redelivery := &domain.WebhookDelivery{
+		ID:             uuid.New().String(),
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         domain.DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+		RedeliveryOf:   &original.ID,
+	}
+	if err := s.repo.CreateWebhookDelivery(ctx, redelivery); err != nil {
+		return nil, err
+	}
+	return redelivery, nil
+}
+
+// DeliverWebhooks is a method for sending due webhook deliveries. Failed
+// deliveries are retried with exponential backoff until the attempts are
+// exhausted, then marked failed. It returns the number of successful deliveries.
+func (s *service) DeliverWebhooks(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "Service.DeliverWebhooks")
+	defer span.End()
+
+	now := time.Now()
+	due, err := s.repo.ClaimWebhookDeliveries(ctx, s.eventBatchSize, now, now.Add(webhookLease))
+	if err != nil {
+		return 0, err
+	}
+
+	subs := make(map[string]*domain.WebhookSubscription)
+	delivered := 0
+	for _, d := range due {
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			sub, err = s.repo.GetWebhookSubscription(ctx, d.SubscriptionID)
+			if errors.Is(err, domain.ErrorWebhookNotFound) {
+				// Deleted meanwhile, together with its deliveries.
+				continue
+			}
+			if err != nil {
+				return delivered, err
+			}
+			subs[d.SubscriptionID] = sub
+		}
+
+		status, sendErr := s.webhookSender.Send(ctx, sub.URL, sub.Secret, d)
+		d.ResponseStatus = status
+		switch {
+		case sendErr == nil:
+			at := time.Now()
+			d.Status = domain.DeliverySucceeded
+			d.DeliveredAt = &at
+			d.LastError = ""
+			delivered++
+		case d.Attempts >= s.webhookMaxAttempts:
+			d.Status = domain.DeliveryFailed
+			d.LastError = sendErr.Error()
+			slog.WarnContext(ctx, "webhook delivery abandoned", "delivery_id", d.ID, "webhook_id", sub.ID, "attempts", d.Attempts, "error", sendErr)
+		default:
+			d.NextAttemptAt = time.Now().Add(retryDelay(s.webhookBackoff, d.Attempts))
+			d.LastError = sendErr.Error()
+		}
+		metrics.WebhookDeliveries.WithLabelValues(string(d.EventType), string(d.Status)).Inc()
+
+		if err := s.repo.UpdateWebhookDelivery(ctx, d); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
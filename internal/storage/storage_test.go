@@ -0,0 +1,42 @@
+package storage_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aibekfatkhulla/shop/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.LocalStore{Dir: dir}
+	ctx := t.Context()
+
+	require.NoError(t, store.Put(ctx, "products/p1/images/i1/original", []byte("png bytes")))
+	_, err := os.Stat(filepath.Join(dir, "products", "p1", "images", "i1", "original"))
+	require.NoError(t, err)
+
+	r, err := store.Open(ctx, "products/p1/images/i1/original")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "png bytes", string(data))
+
+	require.NoError(t, store.Delete(ctx, "products/p1/images/i1/original"))
+	require.NoError(t, store.Delete(ctx, "products/p1/images/i1/original"))
+	_, err = store.Open(ctx, "products/p1/images/i1/original")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestLocalStore_RejectsKeysOutsideDir(t *testing.T) {
+	store := storage.LocalStore{Dir: t.TempDir()}
+
+	for _, key := range []string{"../escape", "/etc/passwd", ""} {
+		assert.Error(t, store.Put(t.Context(), key, []byte("x")), key)
+	}
+}
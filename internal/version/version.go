@@ -0,0 +1,51 @@
+// Package version exposes build metadata of the running binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Commit and BuildTime are set at link time, e.g.
+//
+//	go build -ldflags "-X github.com/aibekfatkhulla/shop/internal/version.Commit=$(git rev-parse HEAD)"
+//
+// When they are left empty the VCS information embedded by the Go toolchain is used instead.
+var (
+	Commit    string
+	BuildTime string
+)
+
+type Info struct {
+	Commit    string
+	BuildTime string
+	GoVersion string
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	info := Info{
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+	return info
+}
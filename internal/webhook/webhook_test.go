@@ -0,0 +1,111 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	body := []byte(`{"id":"e1"}`)
+	header := webhook.Sign("s3cret", now, body)
+
+	assert.NoError(t, webhook.Verify("s3cret", header, body, 5*time.Minute, now.Add(time.Minute)))
+	assert.ErrorIs(t, webhook.Verify("other", header, body, 5*time.Minute, now), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("s3cret", header, []byte(`{"id":"e2"}`), 5*time.Minute, now), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("s3cret", header, body, 5*time.Minute, now.Add(time.Hour)), webhook.ErrExpiredSignature)
+	assert.ErrorIs(t, webhook.Verify("s3cret", "", body, 5*time.Minute, now), webhook.ErrMissingSignature)
+	assert.ErrorIs(t, webhook.Verify("s3cret", "t=x,v1=zz", body, 5*time.Minute, now), webhook.ErrInvalidSignature)
+}
+
+func TestSender_Send(t *testing.T) {
+	delivery := &domain.WebhookDelivery{
+		ID:        "d1",
+		EventID:   "e1",
+		EventType: domain.EventOrderStatusChanged,
+		Payload:   []byte(`{"id":"e1"}`),
+	}
+
+	tests := []struct {
+		name           string
+		status         int
+		expectedStatus int
+		expectErr      bool
+	}{
+		{name: "accepted", status: http.StatusAccepted, expectedStatus: http.StatusAccepted},
+		{name: "rejected", status: http.StatusInternalServerError, expectedStatus: http.StatusInternalServerError, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, delivery.Payload, body)
+				assert.Equal(t, "d1", r.Header.Get(webhook.DeliveryHeader))
+				assert.Equal(t, "order.status_changed", r.Header.Get(webhook.EventTypeHeader))
+				assert.NoError(t, webhook.Verify("s3cret", r.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now()))
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			status, err := webhook.Sender{Client: receiver.Client()}.Send(t.Context(), receiver.URL, "s3cret", delivery)
+			assert.Equal(t, tt.expectedStatus, status)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::":    true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::ffff:127.0.0.1":     false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"224.0.0.1":            false,
+		"255.255.255.255":      false,
+		"64:ff9b::7f00:1":      false,
+		"::ffff:93.184.216.34": true,
+	} {
+		assert.Equal(t, public, webhook.IsPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	assert.NoError(t, webhook.CheckHost(t.Context(), "93.184.216.34"))
+	assert.ErrorIs(t, webhook.CheckHost(t.Context(), "169.254.169.254"), webhook.ErrNonPublicAddress)
+	assert.ErrorIs(t, webhook.CheckHost(t.Context(), "localhost"), webhook.ErrNonPublicAddress)
+}
+
+func TestNewPublicTransport(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("loopback receiver must not be reached")
+	}))
+	defer receiver.Close()
+
+	client := &http.Client{Transport: webhook.NewPublicTransport()}
+	_, err := webhook.Sender{Client: client}.Send(t.Context(), receiver.URL, "s3cret", &domain.WebhookDelivery{ID: "d1", Payload: []byte(`{}`)})
+	assert.ErrorIs(t, err, webhook.ErrNonPublicAddress)
+}
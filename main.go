@@ -2,46 +2,200 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/aibekfatkhulla/shop/config"
+	"github.com/aibekfatkhulla/shop/internal/domain"
+	"github.com/aibekfatkhulla/shop/internal/events"
+	"github.com/aibekfatkhulla/shop/internal/logging"
+	"github.com/aibekfatkhulla/shop/internal/metrics"
+	"github.com/aibekfatkhulla/shop/internal/notify"
+	"github.com/aibekfatkhulla/shop/internal/ratelimit"
 	"github.com/aibekfatkhulla/shop/internal/repository"
 	"github.com/aibekfatkhulla/shop/internal/server"
 	"github.com/aibekfatkhulla/shop/internal/service"
+	"github.com/aibekfatkhulla/shop/internal/storage"
+	"github.com/aibekfatkhulla/shop/internal/tracing"
+	"github.com/aibekfatkhulla/shop/internal/webhook"
 	"github.com/caarlos0/env"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
+	if err := run(); err != nil {
+		slog.Error("shop stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
 	gin.SetMode(gin.ReleaseMode)
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	_ = godotenv.Load("local.env")
 	var cfg config.Config
 	if err := env.Parse(&cfg); err != nil {
-		panic(err)
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	slog.Info("config loaded", "config", cfg)
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg)
+	if err != nil {
+		return err
 	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("shutdown tracing", "error", err)
+		}
+	}()
 
-	fmt.Printf("cfg: %+v\n", cfg)
-	pg, err := pgx.Connect(ctx, fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
+	pg, err := pgxpool.New(ctx, fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
 		cfg.PgUser,
 		cfg.PgPassword,
 		cfg.PgHost,
 		cfg.PgPort,
 		cfg.Db))
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("connect to postgres: %w", err)
 	}
-	defer pg.Close(ctx)
+	defer pg.Close()
 
-	repo := repository.NewRepository(pg)
-	svc := service.NewService(repo)
-	srv := server.NewServer(svc)
+	prometheus.MustRegister(metrics.NewPoolCollector(pg))
 
-	err = srv.Run(":8080")
+	repo := repository.NewRepository(pg)
+	sink, err := eventSink(cfg)
+	if err != nil {
+		return err
+	}
+	mailer, err := newMailer(cfg)
+	if err != nil {
+		return err
+	}
+	strategy := domain.FulfillmentStrategy(cfg.FulfillmentStrategy)
+	if !strategy.Valid() {
+		return fmt.Errorf("unknown fulfillment strategy %q", cfg.FulfillmentStrategy)
+	}
+	currency, err := domain.ParseCurrency(cfg.DefaultCurrency)
+	if err != nil {
+		return fmt.Errorf("default currency: %w", err)
+	}
+	svcOpts := []service.Option{
+		service.WithIdempotencyTTL(cfg.IdempotencyTTL),
+		service.WithTokenTTLs(cfg.EmailVerificationTTL, cfg.PasswordResetTTL),
+		service.WithVerifiedEmailRequiredToOrder(cfg.RequireVerifiedToOrder),
+		service.WithPendingOrderTTL(cfg.PendingOrderTTL),
+		service.WithFulfillmentStrategy(strategy),
+		service.WithDefaultCurrency(currency),
+		service.WithEventRetries(cfg.EventMaxAttempts, cfg.EventRetryBackoff),
+		service.WithWebhookClient(&http.Client{Timeout: cfg.WebhookTimeout, Transport: webhook.NewPublicTransport()}),
+		service.WithWebhookRetries(cfg.WebhookMaxAttempts, cfg.WebhookRetryBackoff),
+		service.WithBlobStore(storage.LocalStore{Dir: cfg.ImageDir}),
+		service.WithImageMaxBytes(cfg.ImageMaxBytes),
+	}
+	if mailer != nil {
+		notifier := notify.NewNotifier(mailer, repo)
+		notifier.SetStockAlertRecipient(cfg.StockAlertEmail)
+		notifier.SetSentLog(repo)
+		sink = events.Multi{sink, notifier}
+		svcOpts = append(svcOpts, service.WithAccountMailer(notifier))
+	}
+	svcOpts = append(svcOpts, service.WithEventSink(sink))
+	svc := service.NewService(repo, svcOpts...)
+	admins, err := server.ParseAdmins(cfg.AdminTokens)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("admin tokens: %w", err)
+	}
+	srvOpts := []server.Option{server.WithTrustedProxies(cfg.HTTPTrustedProxies), server.WithAdmins(admins)}
+	if cfg.RateLimitEnabled {
+		srvOpts = append(srvOpts, server.WithRateLimits(ratelimit.NewMemoryStore(), server.RateLimits{
+			API:    ratelimit.Limit{Burst: cfg.RateLimitAPIBurst, Period: cfg.RateLimitAPIPeriod},
+			Signup: ratelimit.Limit{Burst: cfg.RateLimitSignupBurst, Period: cfg.RateLimitSignupPeriod},
+		}))
+	}
+	srv := server.NewServer(svc, srvOpts...)
+
+	go every(ctx, cfg.IdempotencyPurgeInterval, "purge idempotency keys", svc.PurgeExpiredIdempotencyKeys)
+	go every(ctx, cfg.EventDispatchInterval, "dispatch events", svc.DispatchEvents)
+	go every(ctx, cfg.WebhookDeliveryInterval, "deliver webhooks", svc.DeliverWebhooks)
+	if cfg.PendingOrderTTL > 0 {
+		go every(ctx, cfg.StaleOrderCancelInterval, "cancel stale orders", svc.CancelStaleOrders)
+	}
+	go every(ctx, cfg.LowStockCheckInterval, "check low stock", svc.CheckLowStock)
+
+	slog.Info("http server starting", "addr", cfg.HTTPAddr)
+	if err := srv.Run(ctx, cfg); err != nil {
+		return err
+	}
+	slog.Info("http server stopped")
+	return nil
+}
+
+// eventSink returns the sink selected by cfg.EventSink.
+func eventSink(cfg config.Config) (events.Sink, error) {
+	switch cfg.EventSink {
+	case "log":
+		return events.LogSink{}, nil
+	case "webhook":
+		if cfg.EventWebhookURL == "" {
+			return nil, errors.New("EVENT_WEBHOOK_URL is required for the webhook event sink")
+		}
+		return events.WebhookSink{URL: cfg.EventWebhookURL, Client: &http.Client{Timeout: 10 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("unknown event sink %q", cfg.EventSink)
+	}
+}
+
+// newMailer returns the mailer selected by cfg.MailDriver, or nil if email
+// notifications are disabled.
+func newMailer(cfg config.Config) (notify.Mailer, error) {
+	switch cfg.MailDriver {
+	case "none":
+		return nil, nil
+	case "file":
+		return notify.FileMailer{Dir: cfg.MailDir, From: cfg.MailFrom}, nil
+	case "smtp":
+		return notify.SMTPMailer{Addr: cfg.SMTPAddr, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword, From: cfg.MailFrom}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.MailDriver)
+	}
+}
+
+// every calls job each interval until ctx is canceled, logging what it did.
+func every[N int | int64](ctx context.Context, interval time.Duration, name string, job func(context.Context) (N, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := job(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, name, "error", err)
+				continue
+			}
+			if n > 0 {
+				slog.DebugContext(ctx, name, "count", n)
+			}
+		}
 	}
 }
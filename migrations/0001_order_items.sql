@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS order_items (
+    order_id   TEXT    NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id TEXT    NOT NULL REFERENCES products (id),
+    quantity   INTEGER NOT NULL CHECK (quantity > 0),
+    price      INTEGER NOT NULL,
+    PRIMARY KEY (order_id, product_id)
+);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS addresses (
+    id          TEXT        PRIMARY KEY,
+    user_id     TEXT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient   TEXT        NOT NULL,
+    line1       TEXT        NOT NULL,
+    line2       TEXT        NOT NULL DEFAULT '',
+    city        TEXT        NOT NULL,
+    region      TEXT        NOT NULL DEFAULT '',
+    postal_code TEXT        NOT NULL,
+    country     TEXT        NOT NULL,
+    phone       TEXT        NOT NULL DEFAULT '',
+    is_default  BOOLEAN     NOT NULL DEFAULT false,
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS addresses_user_idx ON addresses (user_id, created_at);
+
+-- A user has at most one default address.
+CREATE UNIQUE INDEX IF NOT EXISTS addresses_default_idx ON addresses (user_id) WHERE is_default;
+
+-- Orders keep a copy of their addresses so that later edits do not rewrite history.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS billing_address  JSONB;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS shipments (
+    id              TEXT        PRIMARY KEY,
+    order_id        TEXT        NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    carrier         TEXT        NOT NULL,
+    tracking_number TEXT        NOT NULL DEFAULT '',
+    shipped_at      TIMESTAMPTZ NOT NULL,
+    delivered_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS shipments_order_idx ON shipments (order_id, shipped_at);
+
+CREATE TABLE IF NOT EXISTS shipment_items (
+    shipment_id TEXT    NOT NULL REFERENCES shipments (id) ON DELETE CASCADE,
+    product_id  TEXT    NOT NULL,
+    quantity    INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (shipment_id, product_id)
+);
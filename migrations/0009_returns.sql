@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS returns (
+    id            TEXT        PRIMARY KEY,
+    order_id      TEXT        NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    user_id       TEXT        NOT NULL,
+    status        TEXT        NOT NULL,
+    comment       TEXT        NOT NULL DEFAULT '',
+    refund_amount INTEGER     NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS returns_order_idx ON returns (order_id, created_at);
+
+CREATE TABLE IF NOT EXISTS return_items (
+    return_id  TEXT    NOT NULL REFERENCES returns (id) ON DELETE CASCADE,
+    product_id TEXT    NOT NULL,
+    quantity   INTEGER NOT NULL CHECK (quantity > 0),
+    reason     TEXT    NOT NULL,
+    PRIMARY KEY (return_id, product_id)
+);
+
+-- Every status change of a return, including the request itself.
+CREATE TABLE IF NOT EXISTS return_status_changes (
+    id          BIGSERIAL   PRIMARY KEY,
+    return_id   TEXT        NOT NULL REFERENCES returns (id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status   TEXT        NOT NULL,
+    note        TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS return_status_changes_return_idx ON return_status_changes (return_id, id);
@@ -0,0 +1,9 @@
+CREATE INDEX IF NOT EXISTS orders_pending_created_idx ON orders (created_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    name        TEXT        PRIMARY KEY,
+    started_at  TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    processed   INTEGER     NOT NULL DEFAULT 0,
+    error       TEXT
+);
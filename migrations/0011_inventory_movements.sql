@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id         BIGSERIAL   PRIMARY KEY,
+    product_id TEXT        NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    type       TEXT        NOT NULL CHECK (type IN ('sale', 'restock', 'return', 'adjustment')),
+    quantity   INTEGER     NOT NULL CHECK (quantity <> 0),
+    reason     TEXT        NOT NULL,
+    actor      TEXT        NOT NULL,
+    reference  TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS inventory_movements_product_idx ON inventory_movements (product_id, id);
+
+-- The ledger starts with the stock on hand when it was introduced.
+INSERT INTO inventory_movements (product_id, type, quantity, reason, actor)
+SELECT id, 'adjustment', amount, 'opening balance', 'system'
+FROM products
+WHERE amount <> 0
+  AND NOT EXISTS (SELECT 1 FROM inventory_movements);
@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id         TEXT        PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    country    TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Stock on hand so far is kept in the main warehouse.
+INSERT INTO warehouses (id, name) VALUES ('main', 'Main warehouse') ON CONFLICT DO NOTHING;
+
+-- products.amount stays the total over all warehouses.
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    warehouse_id TEXT    NOT NULL REFERENCES warehouses (id),
+    product_id   TEXT    NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    amount       INTEGER NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (warehouse_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS warehouse_stock_product_idx ON warehouse_stock (product_id);
+
+-- Backfilled only once: on a rerun products added since already have their
+-- stock in warehouse_stock.
+INSERT INTO warehouse_stock (warehouse_id, product_id, amount)
+SELECT 'main', id, amount FROM products
+WHERE NOT EXISTS (SELECT 1 FROM warehouse_stock)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS order_item_allocations (
+    order_id     TEXT    NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id   TEXT    NOT NULL,
+    warehouse_id TEXT    NOT NULL REFERENCES warehouses (id),
+    quantity     INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (order_id, product_id, warehouse_id)
+);
+
+-- Orders placed before warehouses are taken from the main one. Backfilled
+-- only once: on a rerun every order already has its allocations, possibly in
+-- other warehouses or per variant, and releasing an order restocks each row.
+INSERT INTO order_item_allocations (order_id, product_id, warehouse_id, quantity)
+SELECT order_id, product_id, 'main', quantity FROM order_items
+WHERE NOT EXISTS (SELECT 1 FROM order_item_allocations)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE inventory_movements ADD COLUMN IF NOT EXISTS warehouse_id TEXT REFERENCES warehouses (id);
+UPDATE inventory_movements SET warehouse_id = 'main' WHERE warehouse_id IS NULL;
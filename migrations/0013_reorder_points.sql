@@ -0,0 +1,10 @@
+-- The default reorder point matches the fixed low-stock threshold used before.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS reorder_point        INTEGER NOT NULL DEFAULT 5 CHECK (reorder_point >= 0),
+    ADD COLUMN IF NOT EXISTS reorder_quantity     INTEGER NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0),
+    ADD COLUMN IF NOT EXISTS supplier_id          TEXT REFERENCES suppliers (id) ON DELETE SET NULL,
+    -- low_stock_alerted_at is set once an alert for the current shortage is
+    -- raised and cleared when the product is restocked above its reorder point.
+    ADD COLUMN IF NOT EXISTS low_stock_alerted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS products_below_reorder_point_idx ON products (id) WHERE amount <= reorder_point;
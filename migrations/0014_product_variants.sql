@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id         TEXT        PRIMARY KEY,
+    product_id TEXT        NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku        TEXT        NOT NULL UNIQUE,
+    options    JSONB       NOT NULL,
+    -- price overrides the product price when set.
+    price      INTEGER     CHECK (price > 0),
+    -- amount is the part of the product amount that is this variant.
+    amount     INTEGER     NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (product_id, options)
+);
+
+-- Order lines are a product, or one of its variants. Lines without a variant
+-- keep an empty variant_id so that it can be part of the primary keys.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_pkey;
+ALTER TABLE order_items ADD PRIMARY KEY (order_id, product_id, variant_id);
+
+ALTER TABLE order_item_allocations ADD COLUMN IF NOT EXISTS variant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE order_item_allocations DROP CONSTRAINT IF EXISTS order_item_allocations_pkey;
+ALTER TABLE order_item_allocations ADD PRIMARY KEY (order_id, product_id, variant_id, warehouse_id);
+
+ALTER TABLE shipment_items ADD COLUMN IF NOT EXISTS variant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE shipment_items DROP CONSTRAINT IF EXISTS shipment_items_pkey;
+ALTER TABLE shipment_items ADD PRIMARY KEY (shipment_id, product_id, variant_id);
+
+ALTER TABLE return_items ADD COLUMN IF NOT EXISTS variant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE return_items DROP CONSTRAINT IF EXISTS return_items_pkey;
+ALTER TABLE return_items ADD PRIMARY KEY (return_id, product_id, variant_id);
+
+ALTER TABLE inventory_movements ADD COLUMN IF NOT EXISTS variant_id TEXT REFERENCES product_variants (id) ON DELETE SET NULL;
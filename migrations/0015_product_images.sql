@@ -0,0 +1,13 @@
+-- The image files are kept in the blob store; this is their gallery.
+CREATE TABLE IF NOT EXISTS product_images (
+    id           TEXT        PRIMARY KEY,
+    product_id   TEXT        NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    position     INTEGER     NOT NULL CHECK (position >= 0),
+    content_type TEXT        NOT NULL,
+    width        INTEGER     NOT NULL,
+    height       INTEGER     NOT NULL,
+    size         BIGINT      NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS product_images_product_idx ON product_images (product_id, position);
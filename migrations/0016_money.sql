@@ -0,0 +1,43 @@
+-- Amounts are kept in the minor unit of their currency.
+--
+-- Unit decision: the integers stored before this migration are KZT minor
+-- units (tiyn) and are not converted. The API never defined their unit, and
+-- the legacy routes keep serving them as the same integers, so neither stored
+-- data nor existing clients change. This file is rerun on every migrate, so it
+-- must not convert amounts itself.
+--
+-- A deployment whose amounts were whole tenge converts them once, by hand,
+-- before starting this version; its legacy clients then see amounts in tiyn:
+--
+--   UPDATE products SET price = price * 100;
+--   UPDATE product_variants SET price = price * 100 WHERE price IS NOT NULL;
+--   UPDATE users SET balance = balance * 100;
+--   UPDATE order_items SET price = price * 100;
+--   UPDATE returns SET refund_amount = refund_amount * 100;
+
+ALTER TABLE products
+    ALTER COLUMN price TYPE BIGINT,
+    ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'KZT';
+
+ALTER TABLE users
+    ALTER COLUMN balance TYPE BIGINT,
+    ADD COLUMN IF NOT EXISTS balance_currency TEXT NOT NULL DEFAULT 'KZT';
+
+-- Every price of an order is in the order currency.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'KZT';
+ALTER TABLE order_items ALTER COLUMN price TYPE BIGINT;
+
+ALTER TABLE returns
+    ALTER COLUMN refund_amount TYPE BIGINT,
+    ADD COLUMN IF NOT EXISTS refund_currency TEXT NOT NULL DEFAULT 'KZT';
+
+-- Variant prices are in the currency of their product.
+ALTER TABLE product_variants ALTER COLUMN price TYPE BIGINT;
+
+-- What products cost in currencies other than the one of their base price.
+CREATE TABLE IF NOT EXISTS product_prices (
+    product_id TEXT   NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    currency   TEXT   NOT NULL,
+    amount     BIGINT NOT NULL CHECK (amount > 0),
+    PRIMARY KEY (product_id, currency)
+);
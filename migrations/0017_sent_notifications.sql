@@ -0,0 +1,6 @@
+-- sent_notifications records the outbox events the notifier has mailed, so an
+-- event retried because another sink failed is not mailed again.
+CREATE TABLE IF NOT EXISTS sent_notifications (
+    event_id TEXT        PRIMARY KEY REFERENCES outbox_events (id) ON DELETE CASCADE,
+    sent_at  TIMESTAMPTZ NOT NULL
+);
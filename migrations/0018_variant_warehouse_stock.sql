@@ -0,0 +1,27 @@
+-- Warehouse stock is kept per variant, so that a variant is only reserved
+-- where it is. Stock of products without variants keeps an empty variant_id.
+ALTER TABLE warehouse_stock ADD COLUMN IF NOT EXISTS variant_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE warehouse_stock DROP CONSTRAINT IF EXISTS warehouse_stock_pkey;
+ALTER TABLE warehouse_stock ADD PRIMARY KEY (warehouse_id, product_id, variant_id);
+
+-- Variant stock was counted in the product rows so far. The ledger knows the
+-- warehouse and variant of every movement, so move it to rows of its own.
+-- Runs only once: later it finds variant rows.
+WITH moved AS (
+    INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, amount)
+    SELECT warehouse_id, product_id, variant_id, SUM(quantity)
+    FROM inventory_movements
+    WHERE variant_id IS NOT NULL AND warehouse_id IS NOT NULL
+      AND NOT EXISTS (SELECT 1 FROM warehouse_stock WHERE variant_id <> '')
+    GROUP BY warehouse_id, product_id, variant_id
+    HAVING SUM(quantity) > 0
+    RETURNING warehouse_id, product_id, amount
+)
+UPDATE warehouse_stock s
+SET amount = GREATEST(s.amount - moved.amount, 0)
+FROM (
+    SELECT warehouse_id, product_id, SUM(amount) AS amount
+    FROM moved
+    GROUP BY warehouse_id, product_id
+) moved
+WHERE s.warehouse_id = moved.warehouse_id AND s.product_id = moved.product_id AND s.variant_id = '';